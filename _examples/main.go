@@ -1,54 +1,47 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"log"
+	"log/slog"
 	"math"
 	"os"
-	"strconv"
+	"time"
 
 	"github.com/allank/chartea/clob"
+	"github.com/allank/chartea/exchange/kraken"
+	"github.com/allank/chartea/feed"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
-var market string
+var (
+	market  string
+	logFile string
+)
 
 var (
-	orderBookCache   *OrderBook
+	orderBookCache   *clob.OrderBook
 	isTokenizedCache bool
 )
 
 func init() {
 }
 
-type refetchMsg struct{}
-
-type mainModel struct {
-	rclob   clob.Model
-	wclob   clob.Model
-	width   int
-	height  int
-	loading bool
+// refetchMsg carries the result of refetching the REST order book.
+type refetchMsg struct {
+	orderBook *clob.OrderBook
+	err       error
 }
 
-func parseOrderBook(orderBook *OrderBook) ([]clob.Order, []clob.Order) {
-	asks := make([]clob.Order, len(orderBook.Asks))
-	for i, ask := range orderBook.Asks {
-		price, _ := strconv.ParseFloat(ask[0].(string), 64)
-		volume, _ := strconv.ParseFloat(ask[1].(string), 64)
-		asks[i] = clob.Order{Price: price, Volume: volume}
-	}
-
-	bids := make([]clob.Order, len(orderBook.Bids))
-	for i, bid := range orderBook.Bids {
-		price, _ := strconv.ParseFloat(bid[0].(string), 64)
-		volume, _ := strconv.ParseFloat(bid[1].(string), 64)
-		bids[i] = clob.Order{Price: price, Volume: volume}
-	}
-
-	return asks, bids
+type mainModel struct {
+	rclob  clob.Model
+	wclob  clob.Model
+	width  int
+	height int
 }
 
 // InitialModel creates the initial state of the application model.
@@ -62,9 +55,7 @@ func InitialModel() mainModel {
 		if err != nil {
 			log.Fatalf("could not fetch order book: %v", err)
 		}
-		asks, bids := parseOrderBook(orderBook)
-		m.rclob.Asks = asks
-		m.rclob.Bids = bids
+		m.rclob.OrderBook = *orderBook
 	} else {
 		m.rclob.Asks = mockAsks()
 		m.rclob.Bids = mockBids()
@@ -78,8 +69,19 @@ func InitialModel() mainModel {
 	m.rclob.StyleOnAsk = lipgloss.NewStyle().
 		Foreground(lipgloss.Color("228")).
 		Background(lipgloss.Color("197"))
-	m.wclob.Asks = mockAsks()
-	m.wclob.Bids = mockBids()
+	// Refresh the REST order book every 30 seconds.
+	if market != "" {
+		m.rclob.RefreshInterval = 30 * time.Second
+	}
+	if market != "" {
+		// Show placeholder rows until the websocket book arrives, and dim
+		// levels that haven't changed for a while.
+		m.wclob.Skeleton = true
+		m.wclob.FadeAfter = 30 * time.Second
+	} else {
+		m.wclob.Asks = mockAsks()
+		m.wclob.Bids = mockBids()
+	}
 	// Set VolumePrecision
 	m.wclob.VolumePrecision = 8
 	m.wclob.Orientation = clob.Vertical
@@ -95,7 +97,13 @@ func InitialModel() mainModel {
 
 // Init is the first command that is run when the program starts.
 func (m mainModel) Init() tea.Cmd {
-	return nil
+	return m.rclob.Init()
+}
+
+// refetch fetches the REST order book.
+func refetch() tea.Msg {
+	orderBook, _, err := fetchOrderBook(market, true)
+	return refetchMsg{orderBook: orderBook, err: err}
 }
 
 // Update handles all incoming messages and updates the model accordingly.
@@ -105,14 +113,21 @@ func (m mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.width = msg.Width
 		m.height = msg.Height
 	case tea.KeyMsg:
+		// Let the websocket book have the keys while a size is typed in.
+		if m.wclob.EnteringImpact() {
+			var cmd tea.Cmd
+			m.wclob, cmd = m.wclob.Update(msg)
+			return m, cmd
+		}
 		switch msg.String() {
 		case "ctrl+c", "q":
 			return m, tea.Quit
 		case "r":
-			m.loading = true
-			return m, func() tea.Msg {
-				return refetchMsg{}
+			if market == "" || m.rclob.Loading() {
+				return m, nil
 			}
+			// Fetch in the background so the spinner keeps turning.
+			return m, tea.Batch(m.rclob.SetLoading(true), refetch)
 		case "v":
 			if m.rclob.Orientation == clob.Vertical {
 				m.rclob.Orientation = clob.Horizontal
@@ -125,18 +140,38 @@ func (m mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			} else {
 				m.wclob.Alignment = clob.AlignLeft
 			}
+		case "i":
+			m.wclob.StartImpact(clob.Bid)
+		case "t":
+			m.rclob.TextMode = !m.rclob.TextMode
+			m.wclob.TextMode = !m.wclob.TextMode
 		}
+	case clob.RefreshRequestMsg:
+		// Refresh quietly in the background, keeping the current book on screen.
+		if msg.ID == m.rclob.ID() && market != "" && !m.rclob.Loading() {
+			return m, refetch
+		}
+		return m, nil
+	case feed.BookUpdateMsg:
+		m.wclob.OrderBook = msg.Book
+		return m, nil
+	case feed.ErrMsg:
+		m.wclob.SetError(msg)
+		return m, nil
+	case feed.StateMsg:
+		// Cover the websocket book while it's out of date.
+		switch msg.State {
+		case feed.Live:
+			m.wclob.SetError(nil)
+		case feed.Reconnecting:
+			m.wclob.SetError(fmt.Errorf("Connection lost, reconnecting in %s...", msg.Delay.Round(time.Second)))
+		}
+		return m, nil
 	case refetchMsg:
-		m.loading = false
-		if market != "" {
-			orderBook, _, err := fetchOrderBook(market, true)
-			if err != nil {
-				// Handle error appropriately, maybe set an error message in the model
-			} else {
-				asks, bids := parseOrderBook(orderBook)
-				m.rclob.Asks = asks
-				m.rclob.Bids = bids
-			}
+		m.rclob.SetLoading(false)
+		m.rclob.SetError(msg.err)
+		if msg.err == nil {
+			m.rclob.OrderBook = *msg.orderBook
 		}
 		return m, nil
 	}
@@ -165,12 +200,7 @@ func (m mainModel) View() string {
 	availHeight := panelHeight - panelStyle.GetVerticalFrameSize()
 
 	// REST Panel
-	var restPanelContent string
-	if m.loading {
-		restPanelContent = "Loading..."
-	} else {
-		restPanelContent = m.rclob.ViewWithOptions(clob.ViewOptions{Width: availRWidth, Height: availHeight})
-	}
+	restPanelContent := m.rclob.ViewWithOptions(clob.ViewOptions{Width: availRWidth, Height: availHeight})
 	restPanel := panelStyle.
 		Width(restPanelWidth - panelStyle.GetHorizontalFrameSize()).
 		Height(panelHeight - panelStyle.GetVerticalFrameSize()).
@@ -197,6 +227,8 @@ func (m mainModel) View() string {
 	statusRefreshVal := StatusBarContentStyle.Render(" refresh REST order book")
 	statusAlignKey := StatusBarInfoStyle.Render("a:")
 	statusAlignVal := StatusBarContentStyle.Render(" toggle vertical alignment")
+	statusTextKey := StatusBarInfoStyle.Render("t:")
+	statusTextVal := StatusBarContentStyle.Render(" toggle text mode")
 	statusQuitKey := StatusBarInfoStyle.Render(" q:")
 	statusQuitVal := StatusBarContentStyle.Render(" quit")
 	statusMarket := ""
@@ -213,7 +245,7 @@ func (m mainModel) View() string {
 			"  | ",
 		)
 	}
-	statusBar := lipgloss.JoinHorizontal(lipgloss.Center, statusMarket, statusRefreshKey, statusRefreshVal, "  ", statusAlignKey, statusAlignVal, "  ", statusQuitKey, statusQuitVal)
+	statusBar := lipgloss.JoinHorizontal(lipgloss.Center, statusMarket, statusRefreshKey, statusRefreshVal, "  ", statusAlignKey, statusAlignVal, "  ", statusTextKey, statusTextVal, "  ", statusQuitKey, statusQuitVal)
 	mainLayout := lipgloss.JoinVertical(
 		lipgloss.Left,
 		panels,
@@ -224,9 +256,30 @@ func (m mainModel) View() string {
 
 func main() {
 	flag.StringVar(&market, "market", "", "the market pair to fetch")
+	flag.StringVar(&logFile, "log", "", "a file to log the websocket feed to")
 	flag.Parse()
 	p := tea.NewProgram(InitialModel(), tea.WithAltScreen())
 
+	// Stream the market's book into the websocket panel.
+	if market != "" {
+		manager, protocol := kraken.NewManager()
+		if logFile != "" {
+			f, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+			if err != nil {
+				log.Fatalf("could not open log: %v", err)
+			}
+			defer f.Close()
+			logger := slog.New(slog.NewTextHandler(f, nil))
+			manager.Client().Logger = logger
+			protocol.Logger = logger
+		}
+		symbol := marketCache.Symbol.String()
+		protocol.SetPrecision(symbol, marketCache.PriceDecimals, marketCache.VolumeDecimals)
+		manager.Subscribe(kraken.ChannelBook, symbol, p.Send)
+		sources := feed.Start(context.Background(), p.Send, manager)
+		defer sources.Stop()
+	}
+
 	if _, err := p.Run(); err != nil {
 		log.Fatalf("Alas, there's been an error: %v", err)
 		os.Exit(1)
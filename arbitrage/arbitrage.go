@@ -0,0 +1,257 @@
+package arbitrage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/allank/chartea/clob"
+	"github.com/allank/chartea/sparkline"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ViewOptions allows you to specify the dimensions of the arbitrage view.
+type ViewOptions struct {
+	Width  int
+	Height int
+}
+
+// Quote is the best bid and ask for the instrument on a venue. A side
+// without a price is zero.
+type Quote struct {
+	Venue string
+	Bid   float64
+	Ask   float64
+	Time  time.Time
+}
+
+// QuoteMsg carries a new quote from a venue.
+type QuoteMsg Quote
+
+// Opportunity is the best arbitrage between two venues: buying at the ask on
+// one and selling at the bid on the other.
+type Opportunity struct {
+	Buy  string
+	Sell string
+	Ask  float64
+	Bid  float64
+	// Spread is the bid less the ask, positive when the trade is profitable
+	// before fees.
+	Spread float64
+}
+
+// Model represents the state of the arbitrage spread widget.
+type Model struct {
+	width  int
+	height int
+
+	// Quotes are the latest quotes from each venue, in the order the venues
+	// were first seen.
+	Quotes []Quote
+
+	// History holds the executable spread after each quote, oldest first,
+	// shown as a sparkline.
+	History []float64
+	// Capacity is the number of spreads kept in the history, older spreads are dropped.
+	Capacity int
+
+	// Threshold highlights spreads at or above it with StyleOpportunity, in
+	// the summary and the sparkline, e.g. your fees for the round trip.
+	Threshold float64
+
+	// Precision for the prices and spread.
+	Precision int
+
+	// Styles
+	StyleLabel       lipgloss.Style
+	StyleOpportunity lipgloss.Style
+	StyleSparkline   lipgloss.Style
+}
+
+// New creates a new arbitrage model with default styles.
+func New() Model {
+	return Model{
+		Capacity:  500,
+		Precision: 2,
+		StyleLabel: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "240", Dark: "245"}),
+		StyleOpportunity: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("34")),
+		StyleSparkline: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "232", Dark: "188"}),
+	}
+}
+
+// Push replaces the quote from a venue, and adds the executable spread to the
+// history once at least two venues are quoted.
+func (m *Model) Push(q Quote) {
+	found := false
+	for i := range m.Quotes {
+		if m.Quotes[i].Venue == q.Venue {
+			m.Quotes[i], found = q, true
+			break
+		}
+	}
+	if !found {
+		m.Quotes = append(m.Quotes, q)
+	}
+
+	best, ok := m.Best()
+	if !ok {
+		return
+	}
+	m.History = append(m.History, best.Spread)
+	if m.Capacity > 0 && len(m.History) > m.Capacity {
+		m.History = m.History[len(m.History)-m.Capacity:]
+	}
+}
+
+// PushBook pushes the best bid and ask of a venue's order book, e.g. from a
+// feed.BookUpdateMsg.
+func (m *Model) PushBook(venue string, book clob.OrderBook, t time.Time) {
+	q := Quote{Venue: venue, Time: t}
+	if bid, ok := book.BestBid(); ok {
+		q.Bid = bid.Price
+	}
+	if ask, ok := book.BestAsk(); ok {
+		q.Ask = ask.Price
+	}
+	m.Push(q)
+}
+
+// Best returns the opportunity with the highest spread across every pair of
+// venues, and false if fewer than two venues have a price to trade at.
+func (m *Model) Best() (Opportunity, bool) {
+	var best Opportunity
+	found := false
+	for _, buy := range m.Quotes {
+		for _, sell := range m.Quotes {
+			if buy.Venue == sell.Venue || buy.Ask <= 0 || sell.Bid <= 0 {
+				continue
+			}
+			spread := sell.Bid - buy.Ask
+			if !found || spread > best.Spread {
+				best = Opportunity{Buy: buy.Venue, Sell: sell.Venue, Ask: buy.Ask, Bid: sell.Bid, Spread: spread}
+				found = true
+			}
+		}
+	}
+	return best, found
+}
+
+// Init initializes the arbitrage model.
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages for the arbitrage model.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	case QuoteMsg:
+		m.Push(Quote(msg))
+	}
+	return m, nil
+}
+
+// View renders the widget, taking up the full width and height of the model.
+func (m *Model) View() string {
+	if m.width <= 0 {
+		return "Initializing..."
+	}
+	return m.ViewWithOptions(ViewOptions{Width: m.width, Height: m.height})
+}
+
+// ViewWithOptions renders the widget with the given options. The first line
+// shows the best opportunity, followed by the quote from each venue as far as
+// they fit, with the history of the spread as a sparkline on the last line.
+func (m *Model) ViewWithOptions(opts ViewOptions) string {
+	if opts.Width <= 0 {
+		return "Initializing..."
+	}
+	if opts.Height <= 0 {
+		return ""
+	}
+	fit := lipgloss.NewStyle().MaxWidth(opts.Width)
+
+	lines := []string{fit.Render(m.renderSummary())}
+	showHistory := opts.Height >= 2 && len(m.History) > 0
+	rows := opts.Height - 1
+	if showHistory {
+		rows--
+	}
+	venueWidth := 0
+	for _, q := range m.Quotes {
+		venueWidth = max(venueWidth, len(q.Venue))
+	}
+	for _, q := range m.Quotes[:min(len(m.Quotes), max(rows, 0))] {
+		line := fmt.Sprintf("%-*s %s %s %s %s", venueWidth, q.Venue,
+			m.StyleLabel.Render("Bid"), m.price(q.Bid),
+			m.StyleLabel.Render("Ask"), m.price(q.Ask))
+		lines = append(lines, fit.Render(line))
+	}
+	if showHistory {
+		lines = append(lines, m.renderHistory(opts.Width))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// renderSummary renders the best opportunity.
+func (m *Model) renderSummary() string {
+	best, ok := m.Best()
+	if !ok {
+		return m.StyleLabel.Render("Arb") + " waiting for two venues"
+	}
+	spread := fmt.Sprintf("%+.*f", m.Precision, best.Spread)
+	if best.Spread >= m.Threshold {
+		spread = m.StyleOpportunity.Render(spread)
+	}
+	return strings.Join([]string{
+		m.StyleLabel.Render("Arb"), spread,
+		m.StyleLabel.Render("Buy"), best.Buy, m.price(best.Ask),
+		m.StyleLabel.Render("Sell"), best.Sell, m.price(best.Bid),
+	}, " ")
+}
+
+// renderHistory renders the sparkline of the spread, with the spreads at or
+// above the threshold highlighted.
+func (m *Model) renderHistory(width int) string {
+	line := []rune(sparkline.Render(m.History, width))
+	values := m.History[max(len(m.History)-width, 0):]
+	// The sparkline is padded on the left when there are fewer values than cells.
+	offset := len(line) - len(values)
+
+	var sb strings.Builder
+	sb.WriteString(m.StyleSparkline.Render(string(line[:offset])))
+	start := offset
+	for i := offset; i <= len(line); i++ {
+		if i < len(line) && (i == start || m.highlight(values[i-offset]) == m.highlight(values[start-offset])) {
+			continue
+		}
+		style := m.StyleSparkline
+		if m.highlight(values[start-offset]) {
+			style = m.StyleOpportunity
+		}
+		sb.WriteString(style.Render(string(line[start:i])))
+		start = i
+	}
+	return sb.String()
+}
+
+// highlight reports whether a spread is at or above the threshold.
+func (m *Model) highlight(spread float64) bool {
+	return spread >= m.Threshold
+}
+
+// price formats a price, or a dash when there isn't one.
+func (m *Model) price(p float64) string {
+	if p <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.*f", m.Precision, p)
+}
@@ -0,0 +1,149 @@
+package areachart
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/allank/chartea/axis"
+	"github.com/allank/chartea/canvas/block"
+	"github.com/allank/chartea/gradient"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ViewOptions allows you to specify the dimensions of the area chart view.
+type ViewOptions struct {
+	Width  int
+	Height int
+}
+
+// Model represents the state of the area chart component.
+type Model struct {
+	width  int
+	height int
+
+	// Data holds the values, oldest first. NaN values leave a gap.
+	Data []float64
+
+	// Mode determines how values are mapped onto the y-axis.
+	Mode axis.Mode
+
+	// ColorTop and ColorBottom are the hex colors the fill fades between,
+	// from the line down to the bottom of the chart.
+	ColorTop    lipgloss.Color
+	ColorBottom lipgloss.Color
+
+	// Styles
+	StyleAxis lipgloss.Style
+}
+
+// New creates a new area chart model with default styles.
+func New() Model {
+	return Model{
+		ColorTop:    lipgloss.Color("#00d75f"),
+		ColorBottom: lipgloss.Color("#003a1a"),
+		StyleAxis: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "240", Dark: "245"}),
+	}
+}
+
+// Init initializes the area chart model.
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages for the area chart model.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	}
+	return m, nil
+}
+
+// View renders the chart, taking up the full width and height of the model.
+func (m *Model) View() string {
+	if m.width <= 0 {
+		return "Initializing..."
+	}
+	return m.ViewWithOptions(ViewOptions{Width: m.width, Height: m.height})
+}
+
+// ViewWithOptions renders the chart with the given options. Each column is
+// filled from the bottom of the chart up to the value, fading from ColorTop at
+// the top of the chart to ColorBottom at the bottom.
+func (m *Model) ViewWithOptions(opts ViewOptions) string {
+	if opts.Width <= 0 {
+		return "Initializing..."
+	}
+	if opts.Height <= 0 {
+		return ""
+	}
+
+	values := m.Data
+	if m.Mode == axis.Percent {
+		values = axis.PercentChange(values)
+	}
+	scale := axis.Fit(m.Mode, values)
+
+	// Aim for a tick every few rows, placing each on the row nearest its value.
+	labels := make([]string, opts.Height)
+	ticks, text := scale.TickLabels(max(opts.Height/3, 2))
+	gutter := 1
+	for i, v := range ticks {
+		y := opts.Height - 1 - int(math.Round(scale.Normalize(v)*float64(opts.Height-1)))
+		if y >= 0 && y < opts.Height && labels[y] == "" {
+			labels[y] = text[i]
+			gutter = max(gutter, len(text[i])+1)
+		}
+	}
+	plotWidth := opts.Width - gutter
+	if plotWidth <= 0 {
+		return ""
+	}
+
+	// Sample a value for each column, and convert it to a height in eighths of a cell.
+	heights := make([]int, plotWidth)
+	for x := range heights {
+		heights[x] = -1
+		if len(values) == 0 {
+			continue
+		}
+		i := 0
+		if len(values) > 1 && plotWidth > 1 {
+			i = int(math.Round(float64(x) * float64(len(values)-1) / float64(plotWidth-1)))
+		}
+		pos := scale.Normalize(values[i])
+		if math.IsNaN(pos) || math.IsInf(pos, 0) {
+			continue
+		}
+		// Always show at least a sliver so the lowest values are visible.
+		heights[x] = max(int(math.Round(pos*float64(opts.Height*8))), 1)
+	}
+
+	rows := make([]string, 0, opts.Height)
+	for y := 0; y < opts.Height; y++ {
+		// Fade from the top of the chart to the bottom.
+		t := 0.0
+		if opts.Height > 1 {
+			t = float64(y) / float64(opts.Height-1)
+		}
+		style := lipgloss.NewStyle().Foreground(gradient.Lerp(m.ColorTop, m.ColorBottom, t))
+
+		floor := block.Floor(y, opts.Height)
+		var sb strings.Builder
+		for _, h := range heights {
+			fill := 0
+			if h >= 0 {
+				fill = h - floor
+			}
+			sb.WriteRune(block.Eighth(fill))
+		}
+
+		rows = append(rows, style.Render(sb.String())+m.StyleAxis.Render(fmt.Sprintf(" %-*s", gutter-1, labels[y])))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
@@ -0,0 +1,131 @@
+package axis
+
+import "math"
+
+// Side defines which side of a chart a y-axis is drawn on.
+type Side int
+
+const (
+	// Right draws the axis on the right of the chart, as is usual for price.
+	Right Side = iota
+	// Left draws the axis on the left of the chart.
+	Left
+)
+
+// Mode defines how values are mapped onto an axis.
+type Mode int
+
+const (
+	// Linear spaces values evenly along the axis.
+	Linear Mode = iota
+	// Log spaces values logarithmically, so equal ratios take up equal space.
+	// Zero and negative values can't be shown on a log axis and are skipped.
+	Log
+	// Percent shows each series as the % change from its first visible value,
+	// for comparing the relative performance of series with different prices.
+	Percent
+)
+
+// Scale maps values onto the vertical range of a chart.
+type Scale struct {
+	Min  float64
+	Max  float64
+	Mode Mode
+}
+
+// Fit creates a scale spanning all of the given values, ignoring NaN and Inf,
+// as well as values that can't be shown in the given mode.
+func Fit(mode Mode, values ...[]float64) Scale {
+	s := Scale{Min: math.Inf(1), Max: math.Inf(-1), Mode: mode}
+	for _, vs := range values {
+		for _, v := range vs {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				continue
+			}
+			if mode == Log && v <= 0 {
+				continue
+			}
+			s.Min = math.Min(s.Min, v)
+			s.Max = math.Max(s.Max, v)
+		}
+	}
+	if s.Min > s.Max {
+		// No usable values.
+		return Scale{Mode: mode}
+	}
+	return s
+}
+
+// Pad widens the scale by a percentage of its span above and below, so the
+// highest and lowest values aren't drawn against the edges of a chart. On a
+// log scale the span is measured in ratios, and a scale with no range is
+// padded by a percentage of its value.
+func (s Scale) Pad(percent float64) Scale {
+	if !(percent > 0) || math.IsInf(percent, 1) {
+		return s
+	}
+	f := percent / 100
+	if s.Mode == Log {
+		if s.Min <= 0 {
+			return s
+		}
+		pad := math.Pow(s.Max/s.Min, f)
+		if s.Max == s.Min {
+			pad = 1 + f
+		}
+		return s.widen(s.Min/pad, s.Max*pad)
+	}
+	pad := (s.Max - s.Min) * f
+	if s.Max == s.Min {
+		pad = math.Abs(s.Min) * f
+	}
+	return s.widen(s.Min-pad, s.Max+pad)
+}
+
+// widen returns the scale spanning low to high, or the scale unchanged if
+// either overflows.
+func (s Scale) widen(low, high float64) Scale {
+	if math.IsInf(low, 0) || math.IsInf(high, 0) || math.IsNaN(low) || math.IsNaN(high) {
+		return s
+	}
+	s.Min, s.Max = low, high
+	return s
+}
+
+// Normalize returns the position of v within the scale, 0 at Min and 1 at Max.
+// A scale with no range places every value in the middle. Returns NaN for
+// values that can't be shown in the scale's mode.
+func (s Scale) Normalize(v float64) float64 {
+	if s.Mode == Log && v <= 0 {
+		return math.NaN()
+	}
+	if s.Max == s.Min {
+		return 0.5
+	}
+	if s.Mode == Log {
+		return (math.Log(v) - math.Log(s.Min)) / (math.Log(s.Max) - math.Log(s.Min))
+	}
+	if span := s.Max - s.Min; !math.IsInf(span, 0) {
+		return (v - s.Min) / span
+	}
+	// The range is too wide for float64, so work in halves.
+	return (v/2 - s.Min/2) / (s.Max/2 - s.Min/2)
+}
+
+// PercentChange returns the values as a % change from the first usable value.
+// If there is no usable first value, or it is zero, every value is NaN.
+func PercentChange(values []float64) []float64 {
+	out := make([]float64, len(values))
+	base := math.NaN()
+	for i, v := range values {
+		if math.IsNaN(base) && !math.IsNaN(v) && !math.IsInf(v, 0) {
+			base = v
+		}
+		if math.IsNaN(base) || base == 0 {
+			out[i] = math.NaN()
+			continue
+		}
+		out[i] = (v - base) / math.Abs(base) * 100
+	}
+	return out
+}
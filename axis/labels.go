@@ -0,0 +1,101 @@
+package axis
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// LabelOptions configures the labels down a y-axis.
+type LabelOptions struct {
+	// Gutter is the width of the column of labels, including the space
+	// between it and the plot. When zero it's sized to fit the widest label,
+	// otherwise labels too wide for it are cut short.
+	Gutter int
+
+	// Count is roughly the number of labels. When zero there's a label about
+	// every three rows.
+	Count int
+
+	// Precision is the number of decimals values are labelled with, as with
+	// the PricePrecision of a clob.Model. When negative, labels have just
+	// enough decimals to tell them apart, as with TickLabels. Labels on a
+	// percent scale are always signed percentages.
+	Precision int
+}
+
+// RowLabels returns a label for each of the rows of a chart, top first, empty
+// where no label is drawn. Each tick is labelled on the row nearest its value.
+func (s Scale) RowLabels(rows int, opts LabelOptions) []string {
+	labels := make([]string, max(rows, 0))
+	if rows <= 0 {
+		return labels
+	}
+	count := opts.Count
+	if count <= 0 {
+		count = max(rows/3, 2)
+	}
+	ticks, text := s.TickLabels(count)
+	for i, v := range ticks {
+		if opts.Precision >= 0 && s.Mode != Percent {
+			text[i] = strconv.FormatFloat(v, 'f', opts.Precision, 64)
+		}
+		pos := s.Normalize(v)
+		if math.IsNaN(pos) {
+			continue
+		}
+		y := rows - 1 - int(math.Round(pos*float64(rows-1)))
+		if y >= 0 && y < rows && labels[y] == "" {
+			labels[y] = text[i]
+		}
+	}
+	return labels
+}
+
+// Format returns the label for a value between the ticks, such as the latest
+// price, with Precision decimals, or when negative six significant figures
+// and at most 12 decimals.
+func (o LabelOptions) Format(v float64) string {
+	if o.Precision >= 0 {
+		return strconv.FormatFloat(v, 'f', o.Precision, 64)
+	}
+	decimals := 0
+	if v != 0 && !math.IsInf(v, 0) && !math.IsNaN(v) {
+		decimals = min(max(5-int(math.Floor(math.Log10(math.Abs(v)))), 0), 12)
+	}
+	return strconv.FormatFloat(v, 'f', decimals, 64)
+}
+
+// GutterWidth returns the width of the column for the labels: the Gutter if
+// set, or else the width of the widest label and the space separating it
+// from the plot. No labels need no gutter.
+func (o LabelOptions) GutterWidth(labels []string) int {
+	if labels == nil {
+		return 0
+	}
+	if o.Gutter > 0 {
+		return o.Gutter
+	}
+	w := 0
+	for _, l := range labels {
+		w = max(w, ansi.StringWidth(l))
+	}
+	return w + 1
+}
+
+// Gutter lays a label out in a column of the given width on a side of the
+// plot, separated from it by a space. Labels on the right are aligned left,
+// and on the left aligned right, so they line up against the plot. Labels too
+// wide for the column are cut short.
+func Gutter(label string, width int, side Side) string {
+	if width <= 0 {
+		return ""
+	}
+	label = ansi.Truncate(label, width-1, "")
+	if side == Left {
+		return fmt.Sprintf("%*s ", width-1, label)
+	}
+	return fmt.Sprintf(" %-*s", width-1, label)
+}
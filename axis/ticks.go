@@ -0,0 +1,177 @@
+package axis
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// units are the suffixes used to shorten large values, largest first.
+var units = []struct {
+	size   float64
+	suffix string
+}{
+	{1e12, "T"},
+	{1e9, "B"},
+	{1e6, "M"},
+	{1e3, "K"},
+}
+
+// NiceStep returns a step of 1, 2 or 5 times a power of ten that divides the
+// span into roughly count intervals.
+func NiceStep(span float64, count int) float64 {
+	if span <= 0 || math.IsNaN(span) || math.IsInf(span, 0) {
+		return 0
+	}
+	raw := span / float64(max(count, 1))
+	mag := math.Pow(10, math.Floor(math.Log10(raw)))
+	switch norm := raw / mag; {
+	case norm < 1.5:
+		return mag
+	case norm < 3:
+		return 2 * mag
+	case norm < 7:
+		return 5 * mag
+	default:
+		return 10 * mag
+	}
+}
+
+// Ticks returns around count values within the scale to label, at round steps.
+// On a log scale the ticks are at 1, 2 and 5 times powers of ten, or just the
+// powers of ten when that would give too many. A scale with no range has a
+// single tick, and one whose range has no round step, being too narrow or too
+// wide for float64, is ticked at its ends.
+func (s Scale) Ticks(count int) []float64 {
+	if s.Max == s.Min {
+		return []float64{s.Min}
+	}
+	if s.Mode == Log {
+		return s.logTicks(count)
+	}
+	// A round step can straddle a narrow range, so halve it until there are at
+	// least two ticks to show the scale.
+	step := NiceStep(s.Max-s.Min, count)
+	ticks := s.linearTicks(step)
+	for n := max(count, 1) * 2; len(ticks) < 2 && n <= max(count, 1)*8; n *= 2 {
+		step = NiceStep(s.Max-s.Min, n)
+		ticks = s.linearTicks(step)
+	}
+	if len(ticks) == 0 {
+		return []float64{s.Min, s.Max}
+	}
+	return ticks
+}
+
+// linearTicks returns the multiples of step within the scale.
+func (s Scale) linearTicks(step float64) []float64 {
+	if step <= 0 || math.IsNaN(step) || math.IsInf(step, 0) {
+		return nil
+	}
+	var ticks []float64
+	for i := math.Ceil(s.Min / step); ; i++ {
+		// Multiply rather than accumulate so the ticks don't drift off round values.
+		v := i * step
+		if v > s.Max+step*1e-9 || math.IsInf(v, 0) {
+			break
+		}
+		if v == 0 {
+			// Avoid labelling negative zero.
+			v = 0
+		}
+		ticks = append(ticks, v)
+	}
+	return ticks
+}
+
+// logTicks returns the ticks for a log scale.
+func (s Scale) logTicks(count int) []float64 {
+	var all, decades []float64
+	for k := math.Floor(math.Log10(s.Min)); k <= math.Ceil(math.Log10(s.Max)); k++ {
+		for _, m := range []float64{1, 2, 5} {
+			v := m * math.Pow(10, k)
+			if v < s.Min || v > s.Max {
+				continue
+			}
+			all = append(all, v)
+			if m == 1 {
+				decades = append(decades, v)
+			}
+		}
+	}
+	if len(all) > count && len(decades) > 0 {
+		return decades
+	}
+	return all
+}
+
+// TickLabels returns around count ticks for the scale along with their
+// labels. Labels share a suffix and just enough decimals to tell them apart,
+// so they line up, e.g. 1.2K, 1.4K, 1.6K. On a percent scale labels are
+// signed percentages.
+func (s Scale) TickLabels(count int) ([]float64, []string) {
+	ticks := s.Ticks(count)
+	labels := make([]string, len(ticks))
+	if s.Mode == Log {
+		for i, v := range ticks {
+			// Log ticks are round numbers, so only small values need decimals.
+			labels[i] = Format(v, max(int(-math.Floor(math.Log10(v))), 0))
+		}
+		return ticks, labels
+	}
+
+	step := 0.0
+	if len(ticks) > 1 {
+		step = ticks[1] - ticks[0]
+	}
+	largest := 0.0
+	for _, v := range ticks {
+		largest = math.Max(largest, math.Abs(v))
+	}
+	// Only shorten with a suffix if it saves a few digits, and the step can
+	// still be shown with a couple of decimals.
+	size, suffix := 1.0, ""
+	for _, u := range units {
+		if largest >= max(u.size, 1e4) && (step == 0 || step >= u.size/100) {
+			size, suffix = u.size, u.suffix
+			break
+		}
+	}
+	decimals := 0
+	if step > 0 {
+		decimals = max(int(-math.Floor(math.Log10(step/size)+1e-9)), 0)
+	}
+
+	for i, v := range ticks {
+		label := strconv.FormatFloat(v/size, 'f', decimals, 64) + suffix
+		if s.Mode == Percent {
+			if v > 0 {
+				label = "+" + label
+			}
+			label += "%"
+		}
+		labels[i] = label
+	}
+	return ticks, labels
+}
+
+// Format returns a value in a compact form, e.g. 1.2K or 3.45M, with at most
+// precision decimals and trailing zeros removed.
+func Format(v float64, precision int) string {
+	size, suffix := 1.0, ""
+	for _, u := range units {
+		if math.Abs(v) >= u.size {
+			size, suffix = u.size, u.suffix
+			break
+		}
+	}
+	s := fmt.Sprintf("%.*f", max(precision, 0), v/size)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	if s == "-0" {
+		s = "0"
+	}
+	return s + suffix
+}
@@ -0,0 +1,171 @@
+package axis
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeStep is a round step between time labels, either a duration within a
+// day or a number of days or months.
+type timeStep struct {
+	duration time.Duration
+	days     int
+	months   int
+	layout   string
+}
+
+// timeSteps are the steps time labels are tried at, finest first.
+var timeSteps = []timeStep{
+	{duration: time.Second, layout: "15:04:05"},
+	{duration: 5 * time.Second, layout: "15:04:05"},
+	{duration: 10 * time.Second, layout: "15:04:05"},
+	{duration: 15 * time.Second, layout: "15:04:05"},
+	{duration: 30 * time.Second, layout: "15:04:05"},
+	{duration: time.Minute, layout: "15:04"},
+	{duration: 5 * time.Minute, layout: "15:04"},
+	{duration: 10 * time.Minute, layout: "15:04"},
+	{duration: 15 * time.Minute, layout: "15:04"},
+	{duration: 30 * time.Minute, layout: "15:04"},
+	{duration: time.Hour, layout: "15:04"},
+	{duration: 2 * time.Hour, layout: "15:04"},
+	{duration: 3 * time.Hour, layout: "15:04"},
+	{duration: 6 * time.Hour, layout: "15:04"},
+	{duration: 12 * time.Hour, layout: "15:04"},
+	{days: 1, layout: "Jan 2"},
+	{days: 2, layout: "Jan 2"},
+	{days: 7, layout: "Jan 2"},
+	{months: 1, layout: "Jan"},
+	{months: 3, layout: "Jan"},
+	{months: 6, layout: "Jan"},
+	{months: 12, layout: "2006"},
+}
+
+// size returns roughly how long the step is.
+func (s timeStep) size() time.Duration {
+	return s.duration + time.Duration(s.days)*24*time.Hour + time.Duration(s.months)*30*24*time.Hour
+}
+
+// floor returns the start of the step t falls in, in t's location.
+func (s timeStep) floor(t time.Time) time.Time {
+	y, m, d := t.Date()
+	switch {
+	case s.months > 0:
+		return time.Date(y, time.Month((int(m)-1)/s.months*s.months+1), 1, 0, 0, 0, 0, t.Location())
+	case s.days > 0:
+		midnight := time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+		return midnight.AddDate(0, 0, -((t.YearDay() - 1) % s.days))
+	}
+	midnight := time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	return midnight.Add(t.Sub(midnight).Truncate(s.duration))
+}
+
+// label returns the label for the start of a step. A step within a day
+// starting at midnight is labelled with the date, and a step of months
+// starting in January with the year, so the labels show where days and years
+// change.
+func (s timeStep) label(t time.Time) string {
+	switch {
+	case s.duration > 0 && t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0:
+		return t.Format("Jan 2")
+	case s.months > 0 && s.months < 12 && t.Month() == time.January:
+		return t.Format("2006")
+	}
+	return t.Format(s.layout)
+}
+
+// TimeAxis returns a row of time labels of the given width, for points at
+// the given times, oldest first, drawn in the given columns. The finest round
+// step no finer than the points whose labels don't overlap is used, so labels
+// go from seconds through minutes and hours to dates as the span of the times
+// grows. Each label is centred on the first point of its step. Points with a
+// zero time aren't labelled.
+func TimeAxis(times []time.Time, columns []int, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	n := min(len(times), len(columns))
+	gap := time.Duration(0)
+	for i := 1; i < n; i++ {
+		if times[i-1].IsZero() {
+			continue
+		}
+		if d := times[i].Sub(times[i-1]); d > 0 && (gap == 0 || d < gap) {
+			gap = d
+		}
+	}
+	for _, step := range timeSteps {
+		if step.size() < gap {
+			continue
+		}
+		if row, ok := timeRow(times[:n], columns[:n], width, step); ok {
+			return row
+		}
+	}
+	return strings.Repeat(" ", width)
+}
+
+// timeRow lays out the labels for a step, reporting false if any overlap.
+// The first point is only labelled if it starts its step, as the step may
+// have started long before.
+func timeRow(times []time.Time, columns []int, width int, step timeStep) (string, bool) {
+	row := []rune(strings.Repeat(" ", width))
+	end := -1
+	var prev time.Time
+	for i, t := range times {
+		if t.IsZero() {
+			continue
+		}
+		start := step.floor(t)
+		if prev.IsZero() && !start.Equal(t) || start.Equal(prev) {
+			prev = start
+			continue
+		}
+		prev = start
+		label := []rune(step.label(start))
+		if len(label) > width {
+			return "", false
+		}
+		x := min(max(columns[i]-len(label)/2, 0), width-len(label))
+		if x <= end {
+			return "", false
+		}
+		copy(row[x:], label)
+		end = x + len(label)
+	}
+	return string(row), true
+}
+
+// FormatDuration formats a span of time in its two largest units, e.g.
+// "3d 4h", "2h 30m" or "45s", for labelling the time between two points.
+func FormatDuration(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	units := []struct {
+		size time.Duration
+		name string
+	}{
+		{24 * time.Hour, "d"},
+		{time.Hour, "h"},
+		{time.Minute, "m"},
+		{time.Second, "s"},
+	}
+	var parts []string
+	for _, u := range units {
+		n := d / u.size
+		d -= n * u.size
+		if n > 0 {
+			parts = append(parts, strconv.FormatInt(int64(n), 10)+u.name)
+		}
+		// Stop at the unit after the largest, as "2h 0m" reads as "2h".
+		if len(parts) == 2 || len(parts) == 1 && n == 0 {
+			break
+		}
+	}
+	if len(parts) == 0 {
+		return "0s"
+	}
+	return sign + strings.Join(parts, " ")
+}
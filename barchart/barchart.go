@@ -0,0 +1,263 @@
+package barchart
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/allank/chartea/canvas/block"
+	"github.com/allank/chartea/legend"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ViewOptions allows you to specify the dimensions of the bar chart view.
+type ViewOptions struct {
+	Width  int
+	Height int
+}
+
+// Segment describes one layer of the stacked bars, e.g. maker or taker volume.
+type Segment struct {
+	Name string
+	// Style is used to draw the segment, its foreground color fills the bar.
+	Style lipgloss.Style
+}
+
+// Bar is a single bar, with a value for each segment stacked from the bottom up.
+type Bar struct {
+	Label  string
+	Values []float64
+}
+
+// Model represents the state of the bar chart component.
+type Model struct {
+	width  int
+	height int
+
+	// Bars are drawn left to right.
+	Bars []Bar
+
+	// Segments describe each of the values in a bar, in stacking order.
+	Segments []Segment
+
+	// BarWidth is the width of each bar, and Gap the space between bars.
+	BarWidth int
+	Gap      int
+
+	// Precision for the axis labels.
+	Precision int
+
+	// ShowLegend overlays a legend listing the segments.
+	ShowLegend bool
+	// Legend holds the position and styles of the legend, its entries are
+	// filled in from the segments when rendering.
+	Legend legend.Model
+
+	// Styles
+	StyleAxis lipgloss.Style
+}
+
+// New creates a new bar chart model with default styles and a single segment.
+func New() Model {
+	l := legend.New()
+	l.Corner = legend.TopRight
+	return Model{
+		Segments: []Segment{
+			{Style: lipgloss.NewStyle().Foreground(lipgloss.Color("34"))},
+		},
+		BarWidth:  3,
+		Gap:       1,
+		Precision: 2,
+		Legend:    l,
+		StyleAxis: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "240", Dark: "245"}),
+	}
+}
+
+// Init initializes the bar chart model.
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages for the bar chart model.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	}
+	return m, nil
+}
+
+// View renders the chart, taking up the full width and height of the model.
+func (m *Model) View() string {
+	if m.width <= 0 {
+		return "Initializing..."
+	}
+	return m.ViewWithOptions(ViewOptions{Width: m.width, Height: m.height})
+}
+
+// ViewWithOptions renders the chart with the given options. Totals are
+// labelled on the left, and each bar is labelled underneath. When there are
+// more bars than fit, the most recent are shown.
+func (m *Model) ViewWithOptions(opts ViewOptions) string {
+	if opts.Width <= 0 {
+		return "Initializing..."
+	}
+	// Leave a row for the bar labels.
+	plotHeight := opts.Height - 1
+	if plotHeight <= 0 || len(m.Bars) == 0 {
+		return ""
+	}
+
+	maxTotal := 0.0
+	for _, b := range m.Bars {
+		maxTotal = math.Max(maxTotal, total(b))
+	}
+	gutter := max(len(m.label(maxTotal)), 1) + 1
+	barWidth := max(m.BarWidth, 1)
+	gap := max(m.Gap, 0)
+	plotWidth := opts.Width - gutter
+	if plotWidth < barWidth {
+		return ""
+	}
+
+	bars := m.Bars
+	if fit := (plotWidth + gap) / (barWidth + gap); len(bars) > fit {
+		bars = bars[len(bars)-fit:]
+		// Rescale on the visible bars.
+		maxTotal = 0
+		for _, b := range bars {
+			maxTotal = math.Max(maxTotal, total(b))
+		}
+	}
+
+	// Convert each bar's segments into cumulative heights in eighths of a cell.
+	heights := make([][]int, len(bars))
+	for i, b := range bars {
+		sum := 0.0
+		heights[i] = make([]int, len(b.Values))
+		for j, v := range b.Values {
+			sum = math.Min(sum+value(v), math.MaxFloat64)
+			if maxTotal > 0 {
+				heights[i][j] = int(math.Round(sum / maxTotal * float64(plotHeight*8)))
+			}
+		}
+	}
+
+	plotRows := make([]string, 0, plotHeight)
+	for y := 0; y < plotHeight; y++ {
+		floor := block.Floor(y, plotHeight)
+		var sb strings.Builder
+		for i := range bars {
+			if i > 0 {
+				sb.WriteString(strings.Repeat(" ", gap))
+			}
+			sb.WriteString(strings.Repeat(m.cell(heights[i], floor), barWidth))
+		}
+		sb.WriteString(strings.Repeat(" ", plotWidth-lipgloss.Width(sb.String())))
+		plotRows = append(plotRows, sb.String())
+	}
+
+	plot := strings.Join(plotRows, "\n")
+	if m.ShowLegend {
+		l := m.Legend
+		l.Entries = make([]legend.Entry, 0, len(m.Segments))
+		for _, s := range m.Segments {
+			l.Entries = append(l.Entries, legend.Entry{Label: s.Name, Style: s.Style, HideValue: true})
+		}
+		plot = l.Overlay(plot)
+	}
+
+	rows := make([]string, 0, opts.Height)
+	for y, row := range strings.Split(plot, "\n") {
+		label := ""
+		switch y {
+		case 0:
+			// Rescaling can only shrink the total, but its shortest form can
+			// have more decimals, so drop any that don't fit.
+			label = m.label(maxTotal)
+			label = label[:min(len(label), gutter-1)]
+		case plotHeight - 1:
+			label = "0"
+		}
+		rows = append(rows, m.StyleAxis.Render(fmt.Sprintf("%*s ", gutter-1, label))+row)
+	}
+
+	// Label each bar, truncated to its width.
+	var labels strings.Builder
+	labels.WriteString(strings.Repeat(" ", gutter))
+	for i, b := range bars {
+		if i > 0 {
+			labels.WriteString(strings.Repeat(" ", gap))
+		}
+		label := []rune(b.Label)
+		if len(label) > barWidth {
+			label = label[:barWidth]
+		}
+		labels.WriteString(lipgloss.PlaceHorizontal(barWidth, lipgloss.Center, string(label)))
+	}
+	rows = append(rows, m.StyleAxis.Render(labels.String()))
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+// cell renders one cell of a bar, given the cumulative segment heights and the
+// eighths below the cell. Where a segment ends part way through the cell, the
+// segment above it is shown behind the partial block.
+func (m *Model) cell(heights []int, floor int) string {
+	for j, h := range heights {
+		if h <= floor {
+			continue
+		}
+		fill := min(h-floor, 8)
+		style := m.segmentStyle(j)
+		if fill == 8 {
+			return style.Render(string(block.Eighth(fill)))
+		}
+		// Find the next segment that reaches into this cell to use as the background.
+		for k := j + 1; k < len(heights); k++ {
+			if heights[k] > h {
+				return style.Background(m.segmentStyle(k).GetForeground()).Render(string(block.Eighth(fill)))
+			}
+		}
+		return style.Render(string(block.Eighth(fill)))
+	}
+	return " "
+}
+
+// segmentStyle returns the style of a segment, falling back to the first segment's.
+func (m *Model) segmentStyle(i int) lipgloss.Style {
+	if i < len(m.Segments) {
+		return m.Segments[i].Style
+	}
+	if len(m.Segments) > 0 {
+		return m.Segments[0].Style
+	}
+	return lipgloss.NewStyle()
+}
+
+// total returns the height of a bar, held at the largest float64 when the
+// segments add up to more.
+func total(b Bar) float64 {
+	sum := 0.0
+	for _, v := range b.Values {
+		sum += value(v)
+	}
+	return math.Min(sum, math.MaxFloat64)
+}
+
+// label formats a total for the axis.
+func (m *Model) label(v float64) string {
+	return strconv.FormatFloat(v, 'f', m.Precision, 64)
+}
+
+// value returns the height of a segment, negative and invalid values have no height.
+func value(v float64) float64 {
+	if math.IsNaN(v) || math.IsInf(v, 0) || v < 0 {
+		return 0
+	}
+	return v
+}
@@ -0,0 +1,15 @@
+package candles
+
+import "time"
+
+// Candle is the open, high, low and close prices of a market over an
+// interval, and the volume traded.
+type Candle struct {
+	// Time is the start of the interval.
+	Time   time.Time
+	Open   float64
+	High   float64
+	Low    float64
+	Close  float64
+	Volume float64
+}
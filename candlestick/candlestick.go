@@ -0,0 +1,722 @@
+package candlestick
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/allank/chartea/axis"
+	"github.com/allank/chartea/candles"
+	"github.com/allank/chartea/indicator"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// ViewOptions allows you to specify the dimensions of the chart view.
+type ViewOptions struct {
+	Width  int
+	Height int
+}
+
+// Glyphs are the characters candles are drawn with.
+type Glyphs struct {
+	// Wick is drawn through the high and low of a candle, above and below
+	// its body.
+	Wick string
+	// Body fills the body of a candle.
+	Body string
+	// Hollow is drawn for a hollow body one cell wide. Wider hollow bodies
+	// are outlined with the corners and edges of Outline, and a body only a
+	// row high with its middle left, top and middle right.
+	Hollow  string
+	Outline lipgloss.Border
+	// PriceLine is repeated across the chart for the price line.
+	PriceLine string
+	// Indicator marks the value of an indicator at each candle.
+	Indicator string
+	// Crosshair is drawn up and down the chart through the candle under the
+	// crosshair, where there's no candle.
+	Crosshair string
+}
+
+// Glyph sets to suit different terminals and fonts.
+var (
+	// LineGlyphs draw thin wicks and outlines. They are the default.
+	LineGlyphs = Glyphs{Wick: "│", Body: "█", Hollow: "┃", Outline: lipgloss.NormalBorder(), PriceLine: "╌", Indicator: "•", Crosshair: "┆"}
+	// HeavyGlyphs draw heavy wicks and outlines, for fonts with thin lines.
+	HeavyGlyphs = Glyphs{Wick: "┃", Body: "█", Hollow: "║", Outline: lipgloss.ThickBorder(), PriceLine: "╍", Indicator: "●", Crosshair: "┇"}
+	// ASCIIGlyphs draw with ASCII characters only.
+	ASCIIGlyphs = Glyphs{Wick: "|", Body: "#", Hollow: "H", Outline: lipgloss.ASCIIBorder(), PriceLine: "-", Indicator: "*", Crosshair: ":"}
+)
+
+// volumeBars are the glyphs used for each eighth of a cell of the volume
+// pane, lowest first.
+var volumeBars = []string{"▁", "▂", "▃", "▄", "▅", "▆", "▇", "█"}
+
+// KeyMap is the keys the chart handles in Update.
+type KeyMap struct {
+	// Indicators toggle the chart's indicators, the first binding the first
+	// indicator and so on.
+	Indicators []key.Binding
+	// Volume toggles the volume pane.
+	Volume key.Binding
+	// Left and Right move the crosshair a candle, showing it if hidden.
+	Left  key.Binding
+	Right key.Binding
+	// HideCrosshair hides the crosshair, ending any measurement.
+	HideCrosshair key.Binding
+	// Measure anchors a measurement at the crosshair, or ends it.
+	Measure key.Binding
+}
+
+// DefaultKeyMap returns the default keys, 1 to 9 for the indicators, v for
+// the volume pane, the arrow keys or h and l to move the crosshair, m to
+// measure from it and esc to hide it.
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Indicators:    indicator.DefaultKeys(),
+		Volume:        key.NewBinding(key.WithKeys("v"), key.WithHelp("v", "toggle volume")),
+		Left:          key.NewBinding(key.WithKeys("left", "h"), key.WithHelp("←/h", "crosshair left")),
+		Right:         key.NewBinding(key.WithKeys("right", "l"), key.WithHelp("→/l", "crosshair right")),
+		HideCrosshair: key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "hide crosshair")),
+		Measure:       key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "measure")),
+	}
+}
+
+// Model represents the state of the candlestick chart component.
+type Model struct {
+	width  int
+	height int
+
+	// Candles are the candles to chart, oldest first.
+	Candles []candles.Candle
+
+	// Capacity is the number of candles kept by Push, older candles are
+	// dropped. When zero every candle is kept.
+	Capacity int
+
+	// BodyWidth is the width of each candle's body in cells, from 1 to 3.
+	// Candles are separated by a blank column.
+	BodyWidth int
+
+	// Glyphs are the characters the candles are drawn with.
+	Glyphs Glyphs
+
+	// Hollow draws the bodies of rising candles as outlines, and falling
+	// candles filled.
+	Hollow bool
+
+	// LabelSide is the side of the chart prices are labelled on, the right
+	// by default.
+	LabelSide axis.Side
+
+	// Labels sets the gutter width, number and precision of the price labels.
+	Labels axis.LabelOptions
+
+	// ShowTime labels the times of the candles along the bottom row, when
+	// the chart is at least three rows high. It's on by default.
+	ShowTime bool
+
+	// ShowPriceLine draws a dashed line across the chart at the close of the
+	// latest candle, with the price labelled at the edge, so the current
+	// price can be read off as candles stream in.
+	ShowPriceLine bool
+
+	// Padding is the percentage of the range of the candles shown added
+	// above and below it, so the highs and lows aren't drawn against the
+	// edges. It's ignored while a range is set with SetYRange.
+	Padding float64
+
+	// Indicators are drawn over the candles while enabled, computed from
+	// their closes.
+	Indicators []indicator.Indicator
+
+	// ShowVolume draws the volume of each candle as a bar in a pane below
+	// the candles, a fifth of the height of the chart, when the chart is at
+	// least five rows high.
+	ShowVolume bool
+
+	// KeyMap is the keys that toggle the indicators and volume pane, each
+	// toggle sending an indicator.ToggleMsg, and move the crosshair.
+	KeyMap KeyMap
+
+	// crosshair is how many candles before the latest the crosshair is on,
+	// so it stays in place on screen as candles stream in, if crosshairOn.
+	crosshair   int
+	crosshairOn bool
+
+	// anchor is how many candles before the latest a measurement starts, if
+	// measuring.
+	anchor    int
+	measuring bool
+
+	// yRange is the range set by SetYRange, if fixed is true.
+	yRange axis.Scale
+	fixed  bool
+
+	// Styles
+	StyleUp         lipgloss.Style
+	StyleDown       lipgloss.Style
+	StyleAxis       lipgloss.Style
+	StylePriceLine  lipgloss.Style
+	StylePriceLabel lipgloss.Style
+	StyleCrosshair  lipgloss.Style
+	StyleReadout    lipgloss.Style
+}
+
+// New creates a new candlestick model with default styles, drawing filled
+// bodies a cell wide with LineGlyphs.
+func New() Model {
+	return Model{
+		Capacity:  1000,
+		BodyWidth: 1,
+		Glyphs:    LineGlyphs,
+		Labels:    axis.LabelOptions{Precision: -1},
+		ShowTime:  true,
+		KeyMap:    DefaultKeyMap(),
+		StyleUp: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("34")),
+		StyleDown: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("124")),
+		StyleAxis: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "240", Dark: "245"}),
+		StylePriceLine: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("214")),
+		StylePriceLabel: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("232")).
+			Background(lipgloss.Color("214")),
+		StyleCrosshair: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "250", Dark: "240"}),
+		StyleReadout: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "240", Dark: "245"}),
+	}
+}
+
+// Push adds candles to the chart, oldest first. A candle for the same
+// interval as the latest replaces it, as the current candle is updated until
+// it closes, and candles older than the latest are dropped. Only the latest
+// Capacity candles are kept, so a chart left streaming for days doesn't grow.
+func (m *Model) Push(cs ...candles.Candle) {
+	for _, c := range cs {
+		switch n := len(m.Candles); {
+		case n > 0 && c.Time.Equal(m.Candles[n-1].Time):
+			m.Candles[n-1] = c
+		case n > 0 && c.Time.Before(m.Candles[n-1].Time):
+			// Already replaced by a later candle.
+		default:
+			m.Candles = append(m.Candles, c)
+		}
+	}
+	if m.Capacity > 0 && len(m.Candles) > m.Capacity {
+		m.Candles = m.Candles[len(m.Candles)-m.Capacity:]
+	}
+}
+
+// SetYRange fixes the range of prices shown, for monitoring a market on a
+// scale that doesn't move. Candles outside the range are cut off at the edges.
+// A range with a bound that isn't a finite number returns to fitting the
+// range to the candles.
+func (m *Model) SetYRange(low, high float64) {
+	m.yRange = axis.Scale{Min: min(low, high), Max: max(low, high)}
+	m.fixed = !math.IsNaN(low) && !math.IsInf(low, 0) && !math.IsNaN(high) && !math.IsInf(high, 0)
+}
+
+// AutoYRange returns to fitting the range of prices to the candles shown.
+func (m *Model) AutoYRange() {
+	m.yRange, m.fixed = axis.Scale{}, false
+}
+
+// SetCrosshair shows the crosshair on the candle at index i of Candles,
+// e.g. the candle under the mouse.
+func (m *Model) SetCrosshair(i int) {
+	m.crosshair = min(max(len(m.Candles)-1-i, 0), max(len(m.Candles)-1, 0))
+	m.crosshairOn = true
+}
+
+// HideCrosshair hides the crosshair, ending any measurement.
+func (m *Model) HideCrosshair() {
+	m.crosshairOn, m.measuring = false, false
+}
+
+// Crosshair returns the index in Candles of the candle under the crosshair,
+// and whether the crosshair is shown.
+func (m *Model) Crosshair() (int, bool) {
+	if !m.crosshairOn || len(m.Candles) == 0 {
+		return 0, false
+	}
+	return max(len(m.Candles)-1-m.crosshair, 0), true
+}
+
+// StartMeasure anchors a measurement at the crosshair, showing it on the
+// latest candle if hidden. As the crosshair moves, the readout shows the
+// change in price, percent and time from the candle at the anchor.
+func (m *Model) StartMeasure() {
+	if !m.crosshairOn {
+		m.crosshair, m.crosshairOn = 0, true
+	}
+	m.anchor, m.measuring = m.crosshair, true
+}
+
+// StopMeasure ends the measurement, leaving the crosshair shown.
+func (m *Model) StopMeasure() {
+	m.measuring = false
+}
+
+// Measurement returns the indices in Candles of the candles at the anchor
+// and the crosshair, and whether a measurement is being made.
+func (m *Model) Measurement() (from, to int, ok bool) {
+	to, ok = m.Crosshair()
+	if !ok || !m.measuring {
+		return 0, 0, false
+	}
+	return max(len(m.Candles)-1-m.anchor, 0), to, true
+}
+
+// Init initializes the candlestick model.
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages for the candlestick model.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.KeyMap.Volume):
+			m.ShowVolume = !m.ShowVolume
+			return m, indicator.Toggled("Volume", m.ShowVolume)
+		case key.Matches(msg, m.KeyMap.Left):
+			// The crosshair first appears on the latest candle.
+			if m.crosshairOn {
+				m.crosshair = min(m.crosshair+1, max(len(m.Candles)-1, 0))
+			}
+			m.crosshairOn = true
+			return m, nil
+		case key.Matches(msg, m.KeyMap.Right):
+			if m.crosshairOn {
+				m.crosshair = max(m.crosshair-1, 0)
+			}
+			m.crosshairOn = true
+			return m, nil
+		case key.Matches(msg, m.KeyMap.HideCrosshair):
+			m.HideCrosshair()
+			return m, nil
+		case key.Matches(msg, m.KeyMap.Measure):
+			if m.measuring {
+				m.StopMeasure()
+			} else {
+				m.StartMeasure()
+			}
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.Indicators, cmd = indicator.Toggle(m.Indicators, m.KeyMap.Indicators, msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// View renders the chart, taking up the full width and height of the model.
+func (m *Model) View() string {
+	if m.width <= 0 {
+		return "Initializing..."
+	}
+	return m.ViewWithOptions(ViewOptions{Width: m.width, Height: m.height})
+}
+
+// ViewWithOptions renders the chart with the given options. The most recent
+// candles that fit are shown, with prices labelled on the LabelSide, their
+// volume below and times along the bottom, above the readout of the candle
+// under the crosshair, or of the measurement, while it's shown. Candles with
+// a price that isn't a finite number are left blank.
+func (m *Model) ViewWithOptions(opts ViewOptions) string {
+	if opts.Width <= 0 {
+		return "Initializing..."
+	}
+	if opts.Height <= 0 || len(m.Candles) == 0 {
+		return ""
+	}
+	bodyWidth := min(max(m.BodyWidth, 1), 3)
+
+	// Leave a row for the readout, and for the time labels when there's room.
+	height := opts.Height
+	if m.crosshairOn && height >= 2 {
+		height--
+	}
+	plotHeight := height
+	if m.ShowTime && height >= 3 {
+		plotHeight--
+	}
+	volumeHeight := 0
+	if m.ShowVolume && plotHeight >= 5 {
+		volumeHeight = plotHeight / 5
+		plotHeight -= volumeHeight
+	}
+
+	// Unless the gutter is set, size it for the price labels of the full
+	// range of the chart and the price line's label, so it doesn't change
+	// width as the chart scrolls. Labels for the candles shown can need more
+	// decimals, so make room for them too, and cut any that still don't fit.
+	price, hasPrice := m.lastPrice()
+	hasPrice = hasPrice && m.ShowPriceLine
+	priceLabel := ""
+	if hasPrice {
+		priceLabel = m.Labels.Format(price)
+	}
+	gutter := m.Labels.GutterWidth(append(m.scale(m.Candles).RowLabels(plotHeight, m.Labels), priceLabel))
+	visible := func() ([]candles.Candle, []string) {
+		n := max((opts.Width-gutter+1)/(bodyWidth+1), 0)
+		shown := m.Candles[max(len(m.Candles)-n, 0):]
+		return shown, m.scale(shown).RowLabels(plotHeight, m.Labels)
+	}
+	shown, labels := visible()
+	if w := m.Labels.GutterWidth(append(labels, priceLabel)); w > gutter {
+		gutter = w
+		shown, labels = visible()
+	}
+	plotWidth := opts.Width - gutter
+	if plotWidth < bodyWidth {
+		return ""
+	}
+	scale := m.scale(shown)
+	row := func(p float64) int {
+		return min(max(int(math.Round((1-scale.Normalize(p))*float64(plotHeight-1))), 0), plotHeight-1)
+	}
+
+	grid := make([][]string, plotHeight)
+	for y := range grid {
+		grid[y] = make([]string, plotWidth)
+		for x := range grid[y] {
+			grid[y][x] = " "
+		}
+	}
+	for i, c := range shown {
+		if !finite(c) {
+			continue
+		}
+		m.draw(grid, i*(bodyWidth+1), bodyWidth, c, row)
+	}
+
+	// Draw the crosshair through the gaps above and below the candle, before
+	// the indicators are drawn over it.
+	// The anchor of a measurement is drawn in the same way, if it's shown.
+	cross := -1
+	if m.crosshairOn {
+		cross = max(len(shown)-1-m.crosshair, 0)
+		columns := []int{cross}
+		if anchor := len(shown) - 1 - m.anchor; m.measuring && anchor >= 0 {
+			columns = append(columns, anchor)
+		}
+		for _, i := range columns {
+			x := i*(bodyWidth+1) + (bodyWidth-1)/2
+			for y := range grid {
+				if grid[y][x] == " " {
+					grid[y][x] = m.StyleCrosshair.Render(m.Glyphs.Crosshair)
+				}
+			}
+		}
+	}
+	m.drawIndicators(grid, shown, bodyWidth, scale, row)
+
+	// Dash the price line through the gaps between candles, unless the price
+	// is outside a range set with SetYRange.
+	priceRow := -1
+	if hasPrice && price >= scale.Min && price <= scale.Max {
+		priceRow = row(price)
+		for x, cell := range grid[priceRow] {
+			if cell == " " {
+				grid[priceRow][x] = m.StylePriceLine.Render(m.Glyphs.PriceLine)
+			}
+		}
+	}
+
+	rows := make([]string, 0, opts.Height)
+	for y, cells := range grid {
+		label := m.StyleAxis.Render(axis.Gutter(labels[y], gutter, m.LabelSide))
+		if y == priceRow {
+			label = m.priceLabel(priceLabel, gutter)
+		}
+		if m.LabelSide == axis.Left {
+			rows = append(rows, label+strings.Join(cells, ""))
+			continue
+		}
+		rows = append(rows, strings.Join(cells, "")+label)
+	}
+	for _, cells := range m.volume(shown, bodyWidth, plotWidth, volumeHeight) {
+		if m.LabelSide == axis.Left {
+			rows = append(rows, strings.Repeat(" ", gutter)+strings.Join(cells, ""))
+			continue
+		}
+		rows = append(rows, strings.Join(cells, "")+strings.Repeat(" ", gutter))
+	}
+	if plotHeight+volumeHeight < height {
+		rows = append(rows, m.timeAxis(shown, bodyWidth, plotWidth, gutter))
+	}
+	if height < opts.Height {
+		// The crosshair is on the oldest candle shown if it's further back.
+		if from, _, ok := m.Measurement(); ok {
+			rows = append(rows, m.measureReadout(from, len(m.Candles)-len(shown)+cross, opts.Width))
+		} else {
+			rows = append(rows, m.readout(len(m.Candles)-len(shown)+cross, opts.Width))
+		}
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+// drawIndicators marks the value of each enabled indicator at the wick of
+// each candle shown, over the candle. Values outside the range shown aren't
+// marked.
+func (m *Model) drawIndicators(grid [][]string, shown []candles.Candle, bodyWidth int, scale axis.Scale, row func(float64) int) {
+	var closes []float64
+	for _, ind := range m.Indicators {
+		if !ind.Enabled || ind.Compute == nil {
+			continue
+		}
+		if closes == nil {
+			closes = m.closes()
+		}
+		// The indicator is computed over every candle, so it has values for
+		// the first candles shown, and those shown are the latest.
+		values := ind.Compute(closes)
+		values = values[max(len(values)-len(shown), 0):]
+		for i, v := range values {
+			if v >= scale.Min && v <= scale.Max {
+				grid[row(v)][i*(bodyWidth+1)+(bodyWidth-1)/2] = ind.Style.Render(m.Glyphs.Indicator)
+			}
+		}
+	}
+}
+
+// volume returns the cells of the volume pane of the given height, with a
+// bar under each candle shown scaled to the largest volume shown, coloured
+// as the candle.
+func (m *Model) volume(shown []candles.Candle, bodyWidth, plotWidth, height int) [][]string {
+	grid := make([][]string, height)
+	for y := range grid {
+		grid[y] = make([]string, plotWidth)
+		for x := range grid[y] {
+			grid[y][x] = " "
+		}
+	}
+	highest := 0.0
+	for _, c := range shown {
+		if finite(c) && c.Volume > highest && !math.IsInf(c.Volume, 0) {
+			highest = c.Volume
+		}
+	}
+	if highest == 0 {
+		return grid
+	}
+	for i, c := range shown {
+		if !finite(c) || !(c.Volume > 0) || math.IsInf(c.Volume, 0) {
+			continue
+		}
+		style := m.StyleUp
+		if c.Close < c.Open {
+			style = m.StyleDown
+		}
+		// The height of the bar in eighths of a cell, at least one so any
+		// volume shows.
+		eighths := max(int(math.Round(c.Volume/highest*float64(height*8))), 1)
+		for y := range grid {
+			fill := min(eighths-(height-1-y)*8, 8)
+			if fill <= 0 {
+				continue
+			}
+			for x := range bodyWidth {
+				grid[y][i*(bodyWidth+1)+x] = style.Render(volumeBars[fill-1])
+			}
+		}
+	}
+	return grid
+}
+
+// readout renders the row describing the candle at index i of Candles: its
+// time, prices and volume and the values of the enabled indicators, cut
+// short to fit the width.
+func (m *Model) readout(i, width int) string {
+	c := m.Candles[i]
+	parts := []string{
+		c.Time.Format("Jan 2 15:04"),
+		"O " + m.Labels.Format(c.Open),
+		"H " + m.Labels.Format(c.High),
+		"L " + m.Labels.Format(c.Low),
+		"C " + m.Labels.Format(c.Close),
+		"V " + axis.LabelOptions{Precision: -1}.Format(c.Volume),
+	}
+	for _, ind := range m.Indicators {
+		if !ind.Enabled || ind.Compute == nil {
+			continue
+		}
+		values := ind.Compute(m.closes())
+		parts = append(parts, ind.Name+" "+m.Labels.Format(values[i]))
+	}
+	text := ansi.Truncate(strings.Join(parts, "  "), width, "")
+	return m.StyleReadout.Render(text + strings.Repeat(" ", width-ansi.StringWidth(text)))
+}
+
+// measureReadout renders the row describing a measurement from the candle at
+// index from of Candles to the candle at index to: the change in close, as a
+// price and a percentage, the time between them and the number of candles,
+// cut short to fit the width.
+func (m *Model) measureReadout(from, to, width int) string {
+	a, b := m.Candles[from], m.Candles[to]
+	change := m.Labels.Format(b.Close - a.Close)
+	if b.Close > a.Close {
+		change = "+" + change
+	}
+	parts := []string{
+		a.Time.Format("Jan 2 15:04") + " → " + b.Time.Format("Jan 2 15:04"),
+		"Δ " + change,
+	}
+	if a.Close != 0 {
+		parts = append(parts, fmt.Sprintf("%+.2f%%", (b.Close-a.Close)/a.Close*100))
+	}
+	bars := fmt.Sprintf("%d bars", max(to-from, from-to))
+	if to-from == 1 || from-to == 1 {
+		bars = "1 bar"
+	}
+	parts = append(parts, "Δt "+axis.FormatDuration(b.Time.Sub(a.Time)), bars)
+	text := ansi.Truncate(strings.Join(parts, "  "), width, "")
+	return m.StyleReadout.Render(text + strings.Repeat(" ", width-ansi.StringWidth(text)))
+}
+
+// closes returns the close of each candle.
+func (m *Model) closes() []float64 {
+	closes := make([]float64, len(m.Candles))
+	for i, c := range m.Candles {
+		closes[i] = c.Close
+	}
+	return closes
+}
+
+// priceLabel renders the label of the price line in the gutter, highlighted
+// up to the edge of the chart.
+func (m *Model) priceLabel(label string, gutter int) string {
+	text := axis.Gutter(label, gutter, m.LabelSide)
+	if m.LabelSide == axis.Left {
+		return m.StylePriceLabel.Render(text[:len(text)-1]) + " "
+	}
+	return " " + m.StylePriceLabel.Render(text[1:])
+}
+
+// lastPrice returns the close of the latest candle with finite prices.
+func (m *Model) lastPrice() (float64, bool) {
+	for i := len(m.Candles) - 1; i >= 0; i-- {
+		if finite(m.Candles[i]) {
+			return m.Candles[i].Close, true
+		}
+	}
+	return 0, false
+}
+
+// timeAxis renders the row of time labels under the candles, each centred on
+// a candle's wick.
+func (m *Model) timeAxis(shown []candles.Candle, bodyWidth, plotWidth, gutter int) string {
+	times := make([]time.Time, len(shown))
+	columns := make([]int, len(shown))
+	for i, c := range shown {
+		times[i] = c.Time
+		columns[i] = i*(bodyWidth+1) + (bodyWidth-1)/2
+	}
+	labels := axis.TimeAxis(times, columns, plotWidth)
+	if m.LabelSide == axis.Left {
+		return m.StyleAxis.Render(strings.Repeat(" ", gutter) + labels)
+	}
+	return m.StyleAxis.Render(labels + strings.Repeat(" ", gutter))
+}
+
+// draw draws a candle into the grid with its body starting at column x.
+func (m *Model) draw(grid [][]string, x, width int, c candles.Candle, row func(float64) int) {
+	style := m.StyleUp
+	if c.Close < c.Open {
+		style = m.StyleDown
+	}
+	hollow := m.Hollow && c.Close > c.Open
+
+	top, bottom := row(math.Max(c.Open, c.Close)), row(math.Min(c.Open, c.Close))
+	wick := x + (width-1)/2
+	for y := row(c.High); y < top; y++ {
+		grid[y][wick] = style.Render(m.Glyphs.Wick)
+	}
+	for y := bottom + 1; y <= row(c.Low); y++ {
+		grid[y][wick] = style.Render(m.Glyphs.Wick)
+	}
+
+	for y := top; y <= bottom; y++ {
+		for i := range width {
+			glyph := m.Glyphs.Body
+			if hollow {
+				glyph = m.hollowGlyph(i, width, y == top, y == bottom)
+			}
+			grid[y][x+i] = style.Render(glyph)
+		}
+	}
+}
+
+// hollowGlyph returns the glyph for column i of a row of a hollow body.
+func (m *Model) hollowGlyph(i, width int, top, bottom bool) string {
+	o := m.Glyphs.Outline
+	if width == 1 {
+		return m.Glyphs.Hollow
+	}
+	first, last := i == 0, i == width-1
+	switch {
+	case top && bottom:
+		return pick(first, last, o.MiddleLeft, o.Top, o.MiddleRight)
+	case top:
+		return pick(first, last, o.TopLeft, o.Top, o.TopRight)
+	case bottom:
+		return pick(first, last, o.BottomLeft, o.Bottom, o.BottomRight)
+	}
+	return pick(first, last, o.Left, " ", o.Right)
+}
+
+// pick returns left for the first column, right for the last and middle for
+// the rest.
+func pick(first, last bool, left, middle, right string) string {
+	switch {
+	case first:
+		return left
+	case last:
+		return right
+	}
+	return middle
+}
+
+// scale returns the range set by SetYRange, or else a scale fitted to the
+// candles and padded.
+func (m *Model) scale(cs []candles.Candle) axis.Scale {
+	if m.fixed {
+		return m.yRange
+	}
+	return fit(cs).Pad(m.Padding)
+}
+
+// fit returns a scale spanning the prices of the candles.
+func fit(cs []candles.Candle) axis.Scale {
+	prices := make([]float64, 0, len(cs)*4)
+	for _, c := range cs {
+		if finite(c) {
+			prices = append(prices, c.Open, c.High, c.Low, c.Close)
+		}
+	}
+	return axis.Fit(axis.Linear, prices)
+}
+
+// finite reports whether every price of a candle is a finite number.
+func finite(c candles.Candle) bool {
+	for _, p := range []float64{c.Open, c.High, c.Low, c.Close} {
+		if math.IsNaN(p) || math.IsInf(p, 0) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,198 @@
+package block
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Mode defines how many pixels each cell of the canvas holds.
+type Mode int
+
+const (
+	// Half splits each cell into a top and bottom pixel. As terminal cells are
+	// about twice as tall as they are wide, the pixels are roughly square, and
+	// each pixel keeps its own color.
+	Half Mode = iota
+	// Quarter splits each cell into 2 by 2 pixels. A cell can only show two
+	// colors, so when more are used the least used are drawn in the second color.
+	Quarter
+)
+
+// quadrants maps a mask of lit pixels (top left, top right, bottom left,
+// bottom right from the lowest bit) to its glyph.
+var quadrants = []rune(" ▘▝▀▖▌▞▛▗▚▐▜▄▙▟█")
+
+// eighths are the glyphs of a cell filled from the bottom, from empty to full
+// in eighths of the cell.
+var eighths = []rune(" ▁▂▃▄▅▆▇█")
+
+// Eighth returns the glyph of a cell filled from the bottom to n eighths of
+// its height, empty for n of zero or less and full for 8 or more. Bars drawn
+// from the bottom of a plot use it with Floor to fill each of their cells.
+func Eighth(n int) rune {
+	return eighths[min(max(n, 0), 8)]
+}
+
+// Floor returns the eighths of a plot, rows cells tall, below its row y,
+// counting rows from the top. A bar of h eighths fills h-Floor(y, rows)
+// eighths of the cell in row y.
+func Floor(y, rows int) int {
+	return (rows - 1 - y) * 8
+}
+
+// Canvas is a grid of cells drawn with block characters, with per-cell
+// foreground and background colors.
+type Canvas struct {
+	width  int
+	height int
+	mode   Mode
+	pixels []lipgloss.TerminalColor
+}
+
+// New creates an empty canvas of the given size in cells.
+func New(width, height int, mode Mode) *Canvas {
+	c := &Canvas{
+		width:  max(width, 0),
+		height: max(height, 0),
+		mode:   mode,
+	}
+	c.pixels = make([]lipgloss.TerminalColor, c.PixelWidth()*c.PixelHeight())
+	return c
+}
+
+// Width returns the width of the canvas in cells.
+func (c *Canvas) Width() int {
+	return c.width
+}
+
+// Height returns the height of the canvas in cells.
+func (c *Canvas) Height() int {
+	return c.height
+}
+
+// PixelWidth returns the width of the canvas in pixels.
+func (c *Canvas) PixelWidth() int {
+	if c.mode == Quarter {
+		return c.width * 2
+	}
+	return c.width
+}
+
+// PixelHeight returns the height of the canvas in pixels.
+func (c *Canvas) PixelHeight() int {
+	return c.height * 2
+}
+
+// Clear turns off every pixel.
+func (c *Canvas) Clear() {
+	for i := range c.pixels {
+		c.pixels[i] = nil
+	}
+}
+
+// SetPixel sets the color of the pixel at (x, y), ignoring pixels outside of
+// the canvas. A nil color turns the pixel off.
+func (c *Canvas) SetPixel(x, y int, color lipgloss.TerminalColor) {
+	if x < 0 || y < 0 || x >= c.PixelWidth() || y >= c.PixelHeight() {
+		return
+	}
+	c.pixels[y*c.PixelWidth()+x] = color
+}
+
+// Fill sets the color of every pixel in the rectangle with corners (x0, y0)
+// and (x1, y1), inclusive.
+func (c *Canvas) Fill(x0, y0, x1, y1 int, color lipgloss.TerminalColor) {
+	for y := min(y0, y1); y <= max(y0, y1); y++ {
+		for x := min(x0, x1); x <= max(x0, x1); x++ {
+			c.SetPixel(x, y, color)
+		}
+	}
+}
+
+// Rows returns the canvas as rows of text, one per row of cells.
+func (c *Canvas) Rows() []string {
+	rows := make([]string, c.height)
+	for y := 0; y < c.height; y++ {
+		var sb strings.Builder
+		for x := 0; x < c.width; x++ {
+			if c.mode == Quarter {
+				sb.WriteString(c.quarterCell(x, y))
+			} else {
+				sb.WriteString(c.halfCell(x, y))
+			}
+		}
+		rows[y] = sb.String()
+	}
+	return rows
+}
+
+// Render returns the canvas as a block of text.
+func (c *Canvas) Render() string {
+	return strings.Join(c.Rows(), "\n")
+}
+
+// halfCell renders a cell holding a top and bottom pixel.
+func (c *Canvas) halfCell(x, y int) string {
+	top := c.pixels[(y*2)*c.width+x]
+	bottom := c.pixels[(y*2+1)*c.width+x]
+	switch {
+	case top == nil && bottom == nil:
+		return " "
+	case bottom == nil:
+		return lipgloss.NewStyle().Foreground(top).Render("▀")
+	case top == nil:
+		return lipgloss.NewStyle().Foreground(bottom).Render("▄")
+	case top == bottom:
+		return lipgloss.NewStyle().Foreground(top).Render("█")
+	default:
+		return lipgloss.NewStyle().Foreground(top).Background(bottom).Render("▀")
+	}
+}
+
+// quarterCell renders a cell holding 2 by 2 pixels. The most used color is
+// drawn in the foreground, and the next most used in the background.
+func (c *Canvas) quarterCell(x, y int) string {
+	w := c.PixelWidth()
+	cell := [4]lipgloss.TerminalColor{
+		c.pixels[(y*2)*w+x*2], c.pixels[(y*2)*w+x*2+1],
+		c.pixels[(y*2+1)*w+x*2], c.pixels[(y*2+1)*w+x*2+1],
+	}
+
+	// Count how many pixels use each color, keeping the order they were first seen in.
+	var colors []lipgloss.TerminalColor
+	counts := map[lipgloss.TerminalColor]int{}
+	for _, p := range cell {
+		if p == nil {
+			continue
+		}
+		if counts[p] == 0 {
+			colors = append(colors, p)
+		}
+		counts[p]++
+	}
+	if len(colors) == 0 {
+		return " "
+	}
+
+	fg, bg := colors[0], lipgloss.TerminalColor(nil)
+	for _, col := range colors[1:] {
+		if counts[col] > counts[fg] {
+			fg, bg = col, fg
+		} else if bg == nil || counts[col] > counts[bg] {
+			bg = col
+		}
+	}
+
+	mask := 0
+	for i, p := range cell {
+		if p == fg {
+			mask |= 1 << i
+		}
+	}
+	style := lipgloss.NewStyle().Foreground(fg)
+	if bg != nil {
+		style = style.Background(bg)
+	}
+	return style.Render(string(quadrants[mask]))
+}
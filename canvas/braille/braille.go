@@ -0,0 +1,144 @@
+package braille
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// bits maps a dot position within a cell (row, column) to its braille bit.
+var bits = [4][2]rune{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+// Canvas is a grid of braille cells, each 2 dots wide and 4 dots high, so a
+// canvas of w by h cells has 2w by 4h pixels. Pixels are drawn with the current
+// style, and each cell is rendered with the style it was last drawn with.
+type Canvas struct {
+	width  int
+	height int
+	dots   []rune
+	owner  []int
+	styles []lipgloss.Style
+}
+
+// New creates an empty canvas of the given size in cells.
+func New(width, height int) *Canvas {
+	c := &Canvas{
+		width:  max(width, 0),
+		height: max(height, 0),
+	}
+	c.dots = make([]rune, c.width*c.height)
+	c.owner = make([]int, c.width*c.height)
+	c.Clear()
+	return c
+}
+
+// Width returns the width of the canvas in cells.
+func (c *Canvas) Width() int {
+	return c.width
+}
+
+// Height returns the height of the canvas in cells.
+func (c *Canvas) Height() int {
+	return c.height
+}
+
+// SetStyle sets the style used for pixels drawn from now on.
+func (c *Canvas) SetStyle(style lipgloss.Style) {
+	c.styles = append(c.styles, style)
+}
+
+// Clear turns off every pixel and resets the style.
+func (c *Canvas) Clear() {
+	for i := range c.dots {
+		c.dots[i] = 0
+		c.owner[i] = -1
+	}
+	c.styles = c.styles[:0]
+}
+
+// SetPixel turns on the pixel at (x, y), ignoring pixels outside of the canvas.
+func (c *Canvas) SetPixel(x, y int) {
+	if x < 0 || y < 0 || x >= c.width*2 || y >= c.height*4 {
+		return
+	}
+	i := (y/4)*c.width + x/2
+	c.dots[i] |= bits[y%4][x%2]
+	c.owner[i] = len(c.styles) - 1
+}
+
+// Line draws a line of pixels between two points using Bresenham's algorithm.
+func (c *Canvas) Line(x0, y0, x1, y1 int) {
+	dx := abs(x1 - x0)
+	dy := -abs(y1 - y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx + dy
+	for {
+		c.SetPixel(x0, y0)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+// Rows returns the canvas as rows of text, one per row of cells.
+func (c *Canvas) Rows() []string {
+	rows := make([]string, c.height)
+	for y := 0; y < c.height; y++ {
+		var sb strings.Builder
+		// Group runs of cells with the same style to keep the escape sequences down.
+		start := 0
+		for x := 1; x <= c.width; x++ {
+			if x < c.width && c.owner[y*c.width+x] == c.owner[y*c.width+start] {
+				continue
+			}
+			var run strings.Builder
+			for i := start; i < x; i++ {
+				if d := c.dots[y*c.width+i]; d != 0 {
+					run.WriteRune(0x2800 + d)
+				} else {
+					run.WriteByte(' ')
+				}
+			}
+			if owner := c.owner[y*c.width+start]; owner >= 0 {
+				sb.WriteString(c.styles[owner].Render(run.String()))
+			} else {
+				sb.WriteString(run.String())
+			}
+			start = x
+		}
+		rows[y] = sb.String()
+	}
+	return rows
+}
+
+// Render returns the canvas as a block of text.
+func (c *Canvas) Render() string {
+	return strings.Join(c.Rows(), "\n")
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,408 @@
+package clob
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// bigPrecision is the precision exact prices and volumes are parsed with,
+// enough for any tick grid.
+const bigPrecision = 256
+
+// Errors returned by OrderBook.Validate.
+var (
+	// ErrCrossed means the best bid is above the best ask.
+	ErrCrossed = errors.New("crossed book")
+	// ErrLocked means the best bid and ask are at the same price.
+	ErrLocked = errors.New("locked book")
+	// ErrDuplicateLevel means a side of the book has a price more than once.
+	ErrDuplicateLevel = errors.New("duplicate price level")
+	// ErrNegativeVolume means an order has a negative volume.
+	ErrNegativeVolume = errors.New("negative volume")
+	// ErrNotFinite means an order's price or volume is NaN or infinite.
+	ErrNotFinite = errors.New("price or volume not finite")
+)
+
+// NewOrder creates an order from a price and volume in decimal text, keeping
+// the text so the order is shown exactly as the source gave it.
+func NewOrder(price, volume string) (Order, error) {
+	p, err := parseBig(price)
+	if err != nil {
+		return Order{}, fmt.Errorf("invalid price %q: %w", price, err)
+	}
+	v, err := parseBig(volume)
+	if err != nil {
+		return Order{}, fmt.Errorf("invalid volume %q: %w", volume, err)
+	}
+	pf, _ := p.Float64()
+	vf, _ := v.Float64()
+	return Order{Price: pf, Volume: vf, PriceText: price, VolumeText: volume}, nil
+}
+
+// BigPrice returns the price of the order, exactly when it has a PriceText.
+func (o Order) BigPrice() *big.Float {
+	if o.PriceText != "" {
+		if p, err := parseBig(o.PriceText); err == nil {
+			return p
+		}
+	}
+	return new(big.Float).SetPrec(bigPrecision).SetFloat64(o.Price)
+}
+
+// BigVolume returns the volume of the order, exactly when it has a
+// VolumeText.
+func (o Order) BigVolume() *big.Float {
+	if o.VolumeText != "" {
+		if v, err := parseBig(o.VolumeText); err == nil {
+			return v
+		}
+	}
+	return new(big.Float).SetPrec(bigPrecision).SetFloat64(o.Volume)
+}
+
+// parseBig parses a decimal number.
+func parseBig(s string) (*big.Float, error) {
+	f, _, err := big.ParseFloat(s, 10, bigPrecision, big.ToNearestEven)
+	return f, err
+}
+
+// decimals returns the number of decimals in a decimal number.
+func decimals(s string) int {
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		return len(s) - i - 1
+	}
+	return 0
+}
+
+// maxScaledDigits is the most digits parseScaled reads, so they fit in an
+// int64.
+const maxScaledDigits = 18
+
+// parseScaled parses a plain decimal, e.g. "-0.0012", as an integer of d
+// decimals, and false if it isn't plain, has more than d decimals or has too
+// many digits to fit.
+func parseScaled(s string, d int) (int64, bool) {
+	neg := strings.HasPrefix(s, "-")
+	if neg {
+		s = s[1:]
+	}
+	var v int64
+	digits, point := 0, -1
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '.' && point < 0:
+			point = i
+		case c >= '0' && c <= '9':
+			v = v*10 + int64(c-'0')
+			digits++
+		default:
+			return 0, false
+		}
+	}
+	given := 0
+	if point >= 0 {
+		given = len(s) - point - 1
+	}
+	if digits == 0 || given > d || digits+d-given > maxScaledDigits {
+		return 0, false
+	}
+	for range d - given {
+		v *= 10
+	}
+	if neg {
+		v = -v
+	}
+	return v, true
+}
+
+// appendScaled appends an integer of d decimals as a decimal, e.g. -12 of 3
+// decimals as "-0.012".
+func appendScaled(b []byte, v int64, d int) []byte {
+	if v < 0 {
+		b, v = append(b, '-'), -v
+	}
+	var digits, padded [2 * maxScaledDigits]byte
+	n := len(strconv.AppendInt(digits[:0], v, 10))
+	// Pad with zeros to at least a digit before the point.
+	p := padded[:0]
+	for range d + 1 - n {
+		p = append(p, '0')
+	}
+	p = strconv.AppendInt(p, v, 10)
+	whole := len(p) - d
+	b = append(b, p[:whole]...)
+	if d > 0 {
+		b = append(append(b, '.'), p[whole:]...)
+	}
+	return b
+}
+
+// comparePrices compares the prices of two orders, returning -1, 0 or 1. The
+// prices are compared exactly when float64 can't tell them apart and both
+// orders have a PriceText.
+func comparePrices(a, b Order) int {
+	switch {
+	case a.Price < b.Price:
+		return -1
+	case a.Price > b.Price:
+		return 1
+	case a.PriceText == "" || b.PriceText == "" || a.PriceText == b.PriceText:
+		return 0
+	}
+	return a.BigPrice().Cmp(b.BigPrice())
+}
+
+// Validate checks that the book makes sense, returning the problems found
+// joined together, or nil. Each problem matches one of ErrCrossed, ErrLocked,
+// ErrDuplicateLevel, ErrNegativeVolume or ErrNotFinite. The sides don't need
+// to be sorted.
+func (b OrderBook) Validate() error {
+	var errs []error
+	// Levels that aren't finite are reported below, and can't be compared.
+	finite, _ := b.finite()
+	bid, hasBid := finite.BestBid()
+	ask, hasAsk := finite.BestAsk()
+	if hasBid && hasAsk {
+		switch c := comparePrices(bid, ask); {
+		case c > 0:
+			errs = append(errs, fmt.Errorf("%w: best bid %s above best ask %s", ErrCrossed, formatPrice(bid), formatPrice(ask)))
+		case c == 0:
+			errs = append(errs, fmt.Errorf("%w: best bid and ask at %s", ErrLocked, formatPrice(bid)))
+		}
+	}
+	for _, side := range []struct {
+		name   string
+		orders []Order
+	}{{"bid", b.Bids}, {"ask", b.Asks}} {
+		// Levels are grouped by float64 price, then compared exactly, so
+		// exact prices that round to the same float64 aren't duplicates.
+		seen := make(map[float64][]Order, len(side.orders))
+		duplicate, negative, notFinite := false, false, false
+		for _, o := range side.orders {
+			if !o.finite() {
+				if !notFinite {
+					notFinite = true
+					errs = append(errs, fmt.Errorf("%w: %s at %s has volume %s", ErrNotFinite, side.name, formatNumber(o.Price), formatNumber(o.Volume)))
+				}
+				continue
+			}
+			if !duplicate && slices.ContainsFunc(seen[o.Price], func(s Order) bool { return comparePrices(s, o) == 0 }) {
+				duplicate = true
+				errs = append(errs, fmt.Errorf("%w: %s at %s", ErrDuplicateLevel, side.name, formatPrice(o)))
+			}
+			seen[o.Price] = append(seen[o.Price], o)
+			if o.Volume < 0 && !negative {
+				negative = true
+				errs = append(errs, fmt.Errorf("%w: %s at %s has volume %s", ErrNegativeVolume, side.name, formatNumber(o.Price), formatNumber(o.Volume)))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// finite reports whether the order's price and volume are finite numbers.
+func (o Order) finite() bool {
+	return !math.IsNaN(o.Price) && !math.IsInf(o.Price, 0) && !math.IsNaN(o.Volume) && !math.IsInf(o.Volume, 0)
+}
+
+// finite returns the book without orders whose price or volume isn't a finite
+// number, and false if any were left out. The sides are only copied when
+// orders are left out.
+func (b OrderBook) finite() (OrderBook, bool) {
+	bids, bidsOK := finiteOrders(b.Bids)
+	asks, asksOK := finiteOrders(b.Asks)
+	return OrderBook{Bids: bids, Asks: asks}, bidsOK && asksOK
+}
+
+// finiteOrders returns the orders whose price and volume are finite numbers,
+// and false if any were left out.
+func finiteOrders(orders []Order) ([]Order, bool) {
+	for i, o := range orders {
+		if o.finite() {
+			continue
+		}
+		kept := append([]Order(nil), orders[:i]...)
+		for _, o := range orders[i+1:] {
+			if o.finite() {
+				kept = append(kept, o)
+			}
+		}
+		return kept, false
+	}
+	return orders, true
+}
+
+// BestBid returns the bid with the highest price, and false if there are no
+// bids. The bids don't need to be sorted.
+func (b OrderBook) BestBid() (Order, bool) {
+	if len(b.Bids) == 0 {
+		return Order{}, false
+	}
+	best := b.Bids[0]
+	for _, o := range b.Bids[1:] {
+		if comparePrices(o, best) > 0 {
+			best = o
+		}
+	}
+	return best, true
+}
+
+// BestAsk returns the ask with the lowest price, and false if there are no
+// asks. The asks don't need to be sorted.
+func (b OrderBook) BestAsk() (Order, bool) {
+	if len(b.Asks) == 0 {
+		return Order{}, false
+	}
+	best := b.Asks[0]
+	for _, o := range b.Asks[1:] {
+		if comparePrices(o, best) < 0 {
+			best = o
+		}
+	}
+	return best, true
+}
+
+// Spread returns the best ask less the best bid, and false if either side is
+// empty. The spread is negative when the book is crossed.
+func (b OrderBook) Spread() (float64, bool) {
+	bid, hasBid := b.BestBid()
+	ask, hasAsk := b.BestAsk()
+	if !hasBid || !hasAsk {
+		return 0, false
+	}
+	return ask.Price - bid.Price, true
+}
+
+// MidPrice returns the price halfway between the best bid and ask, and false
+// if either side is empty.
+func (b OrderBook) MidPrice() (float64, bool) {
+	bid, hasBid := b.BestBid()
+	ask, hasAsk := b.BestAsk()
+	if !hasBid || !hasAsk {
+		return 0, false
+	}
+	return (bid.Price + ask.Price) / 2, true
+}
+
+// Microprice returns the best bid and ask weighted by the volume on the
+// opposite side, (bid × ask volume + ask × bid volume) / (bid volume + ask
+// volume). It leans towards the side with less volume, as that side is the
+// more likely to be taken next. It returns false if either side is empty or
+// there is no volume at the best bid and ask.
+func (b OrderBook) Microprice() (float64, bool) {
+	bid, hasBid := b.BestBid()
+	ask, hasAsk := b.BestAsk()
+	if !hasBid || !hasAsk || bid.Volume+ask.Volume <= 0 {
+		return 0, false
+	}
+	return (bid.Price*ask.Volume + ask.Price*bid.Volume) / (bid.Volume + ask.Volume), true
+}
+
+// WeightedMid returns a depth-aware fair price over the best levels on each
+// side: the volume-weighted average prices of the bids and asks, weighted by
+// the volume on the opposite side as in Microprice. WeightedMid(1) is the
+// microprice. It returns false if either side is empty or has no volume.
+func (b OrderBook) WeightedMid(levels int) (float64, bool) {
+	bidPrice, bidVolume := vwap(bestLevels(b.Bids, levels, 1))
+	askPrice, askVolume := vwap(bestLevels(b.Asks, levels, -1))
+	if bidVolume <= 0 || askVolume <= 0 {
+		return 0, false
+	}
+	return (bidPrice*askVolume + askPrice*bidVolume) / (bidVolume + askVolume), true
+}
+
+// ImpactPrice returns the average price a market order for size would fill
+// at, walking the book from the best price. side is the side of the order: a
+// Bid buys from the asks and an Ask sells into the bids. It returns false if
+// size isn't positive or the book doesn't have enough volume to fill it.
+func (b OrderBook) ImpactPrice(side Side, size float64) (float64, bool) {
+	s := b.Sweep(side, size)
+	if size <= 0 || s.Unfilled > 0 {
+		return 0, false
+	}
+	return s.AveragePrice()
+}
+
+// bestLevels returns up to n of the best orders on a side, best first, without
+// reordering the book. Bids are best when highest, with a direction of 1, and
+// asks when lowest, with a direction of -1.
+func bestLevels(orders []Order, n, direction int) []Order {
+	sorted := append([]Order(nil), orders...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return comparePrices(sorted[i], sorted[j])*direction > 0
+	})
+	return sorted[:min(max(n, 0), len(sorted))]
+}
+
+// vwap returns the volume-weighted average price of the orders and their
+// total volume.
+func vwap(orders []Order) (price, volume float64) {
+	for _, o := range orders {
+		price += o.Price * o.Volume
+		volume += o.Volume
+	}
+	if volume <= 0 {
+		return 0, 0
+	}
+	return price / volume, volume
+}
+
+// TotalBidVolume returns the volume of every bid.
+func (b OrderBook) TotalBidVolume() float64 {
+	return totalVolume(b.Bids)
+}
+
+// TotalAskVolume returns the volume of every ask.
+func (b OrderBook) TotalAskVolume() float64 {
+	return totalVolume(b.Asks)
+}
+
+// DepthAtPrice returns the volume resting at exactly a price, on either side
+// of the book, or zero if there is no level at the price.
+func (b OrderBook) DepthAtPrice(price float64) float64 {
+	return b.VolumeBetween(price, price)
+}
+
+// VolumeBetween returns the volume of the levels priced from lo to hi
+// inclusive, on both sides of the book, e.g. the liquidity within 1% of the
+// mid price.
+func (b OrderBook) VolumeBetween(lo, hi float64) float64 {
+	volume := 0.0
+	for _, orders := range [][]Order{b.Bids, b.Asks} {
+		for _, o := range orders {
+			if o.Price >= lo && o.Price <= hi {
+				volume += o.Volume
+			}
+		}
+	}
+	return volume
+}
+
+// totalVolume returns the volume of the orders.
+func totalVolume(orders []Order) float64 {
+	total := 0.0
+	for _, o := range orders {
+		total += o.Volume
+	}
+	return total
+}
+
+// formatNumber formats a number in full, without an exponent.
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// formatPrice formats the price of an order for an error, as the source gave
+// it when it has a PriceText.
+func formatPrice(o Order) string {
+	if o.PriceText != "" {
+		return o.PriceText
+	}
+	return formatNumber(o.Price)
+}
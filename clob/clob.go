@@ -2,11 +2,22 @@ package clob
 
 import (
 	"fmt"
-	"sort"
+	"maps"
+	"math"
+	"math/big"
+	"slices"
+	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
 
+	"github.com/allank/chartea/sparkline"
+
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 )
 
 // Orientation defines the orientation of the order book.
@@ -32,20 +43,120 @@ const (
 	AlignRight
 )
 
+// defaultSkeletonDepth is the number of placeholder rows shown on each side
+// of the book when neither the height nor the depth is set.
+const defaultSkeletonDepth = 10
+
+// imbalanceWidth is the width of the imbalance bar in the spread row, and
+// minImbalanceWidth the narrowest it is shown.
+const (
+	imbalanceWidth    = 10
+	minImbalanceWidth = 4
+)
+
+// minPriceWidth is the narrowest a price is shortened to, including the
+// ellipsis, before only the bars are shown.
+const minPriceWidth = 3
+
+// detail defines how much text is shown on each row of the book, as less fits
+// in narrow panes.
+type detail int
+
+const (
+	// detailFull shows the price and volume.
+	detailFull detail = iota
+	// detailPrice shows only the price, the volume is still shown by the bar.
+	detailPrice
+	// detailBars shows only the volume bars.
+	detailBars
+)
+
 // ViewOptions allows you to specify the dimensions of the CLOB view.
 type ViewOptions struct {
 	Width  int
 	Height int
 }
 
+// ErrMsg reports an error to show in place of the book, e.g. when the feed
+// has disconnected. A nil Err clears the error.
+type ErrMsg struct {
+	Err error
+}
+
+// RefreshRequestMsg is sent every RefreshInterval to ask the host app to
+// fetch the book again. ID is the ID of the model that sent it.
+type RefreshRequestMsg struct {
+	ID   int
+	Time time.Time
+}
+
+// refreshTickMsg schedules the next RefreshRequestMsg. The tag discards ticks
+// from a previous refresh loop when it is restarted.
+type refreshTickMsg struct {
+	id   int
+	tag  int
+	time time.Time
+}
+
+// lastID is used to give each model a unique ID.
+var lastID atomic.Int64
+
 // Model represents the state of the CLOB component.
 type Model struct {
-	width  int
-	height int
+	id         int
+	width      int
+	height     int
+	err        error
+	loading    bool
+	refreshTag int
+
+	// countWidth is the width of the count column of the rows being rendered.
+	countWidth int
+
+	// rows holds the buffers the rows of the book are built in, kept from
+	// frame to frame so rendering doesn't allocate them again for every row.
+	rows rowBuffers
+
+	// bidBuffer and askBuffer hold the orders read from BidLevels and AskLevels.
+	bidBuffer []Order
+	askBuffer []Order
+	// bidsSorted and asksSorted record that the bids and asks were loaded
+	// best first from a SortedLevels, so they needn't be sorted or searched.
+	bidsSorted bool
+	asksSorted bool
+
+	// impactInput is the size being typed while entering an impact size, and
+	// impactEditing whether it is being entered.
+	impactInput   string
+	impactEditing bool
+
+	// sweep is the impact order's sweep of the book being rendered.
+	sweep Sweep
+
+	// invalid holds the problems with the book while it is drawn without the
+	// levels that can't be.
+	invalid error
+
+	// spreads are the recent spreads, oldest first, kept when SpreadHistory
+	// is set.
+	spreads []float64
+
+	// flashed holds when each level last changed, kept when FlashFor is set,
+	// and flashBook the book rendered last, to find the next changes from.
+	flashed   map[levelKey]time.Time
+	flashBook OrderBook
 
 	// OrderBook is the data for the order book.
 	OrderBook
 
+	// BidLevels and AskLevels optionally provide the sides of the book from
+	// your own data, e.g. with LevelsOf. When set they are read into the
+	// OrderBook every time the book is rendered, replacing that side. Only
+	// the levels the pane can show are read from a SortedLevels, so the cost
+	// of a frame depends on the size of the pane rather than the book.
+	BidLevels Levels
+	AskLevels Levels
+
 	// Orientation determines whether the order book is displayed vertically or horizontally.
 	Orientation Orientation
 
@@ -59,10 +170,92 @@ type Model struct {
 	PricePrecision  int
 	VolumePrecision int
 
+	// Depth limits the number of levels shown on each side of the book. When
+	// zero, as many levels are shown as fit in the height.
+	Depth int
+
+	// ShowCount shows the number of orders at each level, from Order's Count,
+	// in a column beside the volume. Levels without a count leave it blank.
+	ShowCount bool
+
+	// ShowMid and ShowMicroprice show the mid price and microprice after the
+	// spread, in the Vertical orientation and text mode, as far as they fit.
+	ShowMid        bool
+	ShowMicroprice bool
+
+	// WeightedMidLevels shows the weighted mid over that many levels after
+	// the spread, like the mid price. When zero it isn't shown.
+	WeightedMidLevels int
+
+	// SpreadHistory keeps that many of the most recent spreads and shows them
+	// as a sparkline after the spread in the Vertical orientation, so the
+	// spread widening or tightening is visible at a glance. A spread is kept
+	// whenever it changes between renders. When zero it isn't shown.
+	SpreadHistory int
+
+	// ImbalanceLevels shows a two-tone bar in the Vertical spread row, split
+	// between the bid and ask volume over that many of the best levels, so
+	// the book's lean is visible at a glance. When zero it isn't shown.
+	ImbalanceLevels int
+
+	// FadeAfter dims levels whose Time is older than the duration, so fresh
+	// liquidity stands out from stale quotes. Levels without a Time are never
+	// dimmed. When zero no levels are dimmed.
+	FadeAfter time.Duration
+
+	// FlashFor highlights levels with StyleFlash for the duration after they
+	// are added or change in volume, found with Diff between the books of
+	// successive renders, so activity stands out. When zero no levels are
+	// highlighted.
+	FlashFor time.Duration
+
+	// ImpactSide and ImpactSize overlay a hypothetical market order for
+	// ImpactSize on the book, shading the levels it would take in full with
+	// StyleImpact and the level it would take part of with
+	// StyleImpactPartial. The bottom row shows its average fill price and
+	// what it leaves. A Bid buys from the asks and an Ask sells into the
+	// bids. When ImpactSize is zero nothing is shaded. See StartImpact to
+	// type the size in.
+	ImpactSide Side
+	ImpactSize float64
+
+	// Skeleton shows dimmed placeholder rows, sized to the depth, while the book
+	// is empty, so the layout doesn't jump when the first data arrives.
+	Skeleton bool
+
+	// TextMode renders the book as plain, column-aligned text for use with
+	// screen readers. Each row is labelled BID or ASK rather than relying on
+	// color, and the spread is stated in words.
+	TextMode bool
+
+	// FlagInvalid checks the book with Validate before rendering it, and shows
+	// a warning above a book that doesn't make sense, e.g. a crossed book
+	// from a missed update, rather than rendering it as if it were sound.
+	// Only the levels read from a SortedLevels are checked.
+	FlagInvalid bool
+
+	// RefreshInterval is how often a RefreshRequestMsg is sent, starting from
+	// Init. When zero the model doesn't ask to be refreshed.
+	RefreshInterval time.Duration
+
+	// Spinner is shown while the book is loading.
+	Spinner spinner.Model
+
 	// Styles
-	StyleOffBar lipgloss.Style
-	StyleOnBid  lipgloss.Style
-	StyleOnAsk  lipgloss.Style
+	StyleOffBar   lipgloss.Style
+	StyleOnBid    lipgloss.Style
+	StyleOnAsk    lipgloss.Style
+	StyleError    lipgloss.Style
+	StyleLoading  lipgloss.Style
+	StyleSkeleton lipgloss.Style
+	StyleInvalid  lipgloss.Style
+	StyleImpact   lipgloss.Style
+	// StyleImpactPartial is the style of the level the impact order takes
+	// part of.
+	StyleImpactPartial lipgloss.Style
+	// StyleFlash is the style of levels that have just changed, see
+	// FlashFor.
+	StyleFlash lipgloss.Style
 }
 
 // OrderBook represents the full order book.
@@ -73,13 +266,29 @@ type OrderBook struct {
 
 // Order represents a single order in the book.
 type Order struct {
-	Volume float64
-	Price  float64
+	Volume float64 `json:"volume"`
+	Price  float64 `json:"price"`
+
+	// PriceText and VolumeText optionally hold the price and volume exactly as
+	// the source gave them, e.g. "0.00000001234", for instruments whose tick
+	// grid float64 can't represent. When set they are shown as they are
+	// instead of being formatted with the precision, and prices are compared
+	// exactly. See NewOrder.
+	PriceText  string `json:"price_text,omitempty"`
+	VolumeText string `json:"volume_text,omitempty"`
+
+	// Count is the number of orders at the level, for feeds that provide it,
+	// or zero when unknown.
+	Count int `json:"count,omitempty"`
+
+	// Time is when the level last changed, or zero when unknown.
+	Time time.Time `json:"time,omitzero"`
 }
 
 // New creates a new CLOB model with default styles.
 func New() Model {
 	return Model{
+		id:              int(lastID.Add(1)),
 		Spacing:         1,
 		PricePrecision:  2,
 		VolumePrecision: 2,
@@ -91,12 +300,143 @@ func New() Model {
 		StyleOnAsk: lipgloss.NewStyle().
 			Foreground(lipgloss.Color("188")).
 			Background(lipgloss.Color("124")),
+		StyleError: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("188")).
+			Background(lipgloss.Color("124")).
+			Padding(0, 1),
+		StyleLoading: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "240", Dark: "245"}),
+		StyleSkeleton: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "252", Dark: "237"}),
+		StyleInvalid: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("232")).
+			Background(lipgloss.Color("214")),
+		StyleImpact: lipgloss.NewStyle().
+			Background(lipgloss.AdaptiveColor{Light: "252", Dark: "238"}),
+		StyleImpactPartial: lipgloss.NewStyle().
+			Background(lipgloss.AdaptiveColor{Light: "254", Dark: "236"}),
+		StyleFlash: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.AdaptiveColor{Light: "16", Dark: "231"}),
+		Spinner: spinner.New(
+			spinner.WithSpinner(spinner.Dot),
+			spinner.WithStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("34"))),
+		),
+	}
+}
+
+// SetLoading shows a spinner in place of the book while loading is true. It
+// returns the command that starts the spinner, which should be passed back to
+// Bubble Tea.
+func (m *Model) SetLoading(loading bool) tea.Cmd {
+	if loading == m.loading {
+		return nil
+	}
+	m.loading = loading
+	if loading {
+		return m.Spinner.Tick
+	}
+	return nil
+}
+
+// Loading reports whether the book is loading.
+func (m *Model) Loading() bool {
+	return m.loading
+}
+
+// SetError sets an error to show in place of the book until it is cleared
+// with a nil error.
+func (m *Model) SetError(err error) {
+	m.err = err
+}
+
+// Err returns the error being shown, if any.
+func (m *Model) Err() error {
+	return m.err
+}
+
+// StartImpact starts entering an impact size for an order on a side. While
+// entering, the model takes digits, a decimal point and backspace to edit the
+// size, tab to switch sides, enter to finish and esc to clear the size. The
+// shading follows the size as it is typed.
+func (m *Model) StartImpact(side Side) {
+	m.ImpactSide = side
+	m.impactEditing = true
+	m.impactInput = ""
+	if m.ImpactSize > 0 {
+		m.impactInput = strconv.FormatFloat(m.ImpactSize, 'f', -1, 64)
+	}
+}
+
+// EnteringImpact reports whether an impact size is being entered, so the
+// host app can send its key presses to the model rather than acting on them.
+func (m *Model) EnteringImpact() bool {
+	return m.impactEditing
+}
+
+// updateImpact handles a key press while entering an impact size.
+func (m *Model) updateImpact(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.impactEditing = false
+		return
+	case tea.KeyEsc:
+		m.impactEditing = false
+		m.impactInput = ""
+		m.ImpactSize = 0
+		return
+	case tea.KeyTab:
+		if m.ImpactSide == Bid {
+			m.ImpactSide = Ask
+		} else {
+			m.ImpactSide = Bid
+		}
+		return
+	case tea.KeyBackspace:
+		if m.impactInput != "" {
+			m.impactInput = m.impactInput[:len(m.impactInput)-1]
+		}
+	case tea.KeyRunes:
+		for _, r := range msg.Runes {
+			if (r >= '0' && r <= '9') || (r == '.' && !strings.Contains(m.impactInput, ".")) {
+				m.impactInput += string(r)
+			}
+		}
+	default:
+		return
 	}
+	m.ImpactSize, _ = strconv.ParseFloat(m.impactInput, 64)
 }
 
 // Init initializes the CLOB model.
 func (m Model) Init() tea.Cmd {
-	return nil
+	return m.refreshTick()
+}
+
+// ID returns the unique ID of the model, to match RefreshRequestMsgs to the
+// model that sent them.
+func (m *Model) ID() int {
+	return m.id
+}
+
+// StartRefresh restarts the refresh loop, e.g. after changing the
+// RefreshInterval, replacing the loop started by Init.
+func (m *Model) StartRefresh() tea.Cmd {
+	m.refreshTag++
+	return m.refreshTick()
+}
+
+// refreshTick returns the command for the next tick of the refresh loop.
+func (m *Model) refreshTick() tea.Cmd {
+	if m.RefreshInterval <= 0 {
+		return nil
+	}
+	id, tag := m.id, m.refreshTag
+	return tea.Tick(m.RefreshInterval, func(t time.Time) tea.Msg {
+		return refreshTickMsg{id: id, tag: tag, time: t}
+	})
 }
 
 // Update handles messages for the CLOB model.
@@ -105,6 +445,29 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+	case ErrMsg:
+		m.err = msg.Err
+	case tea.KeyMsg:
+		if m.impactEditing {
+			m.updateImpact(msg)
+		}
+	case refreshTickMsg:
+		if msg.id != m.id || msg.tag != m.refreshTag {
+			return m, nil
+		}
+		request := RefreshRequestMsg{ID: m.id, Time: msg.time}
+		return m, tea.Batch(
+			func() tea.Msg { return request },
+			m.refreshTick(),
+		)
+	case spinner.TickMsg:
+		// Stop ticking once loading has finished.
+		if !m.loading {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.Spinner, cmd = m.Spinner.Update(msg)
+		return m, cmd
 	}
 	return m, nil
 }
@@ -122,27 +485,165 @@ func (m *Model) ViewWithOptions(opts ViewOptions) string {
 	if opts.Width <= 0 {
 		return "Initializing..."
 	}
+	m.loadLevels(m.levelLimit(opts))
+	// Levels whose price or volume isn't a finite number can't be drawn, so
+	// the book is drawn without them. FlagInvalid still reports them.
+	if finite, ok := m.OrderBook.finite(); !ok {
+		book := m.OrderBook
+		m.OrderBook, m.invalid = finite, book.Validate()
+		defer func() { m.OrderBook, m.invalid = book, nil }()
+	}
+	m.recordSpread()
+	m.recordFlashes()
+	if m.loading {
+		return m.renderLoading(opts)
+	}
+	if m.err != nil {
+		return m.renderError(opts)
+	}
+	if m.impactEditing || (m.ImpactSize > 0 && !math.IsInf(m.ImpactSize, 1)) {
+		return m.renderImpact(opts)
+	}
+	return m.renderContent(opts)
+}
+
+// levelLimit returns the most levels of a side rendering at the given size
+// uses, or zero when it needs every level, as an impact order may sweep past
+// the levels shown.
+func (m *Model) levelLimit(opts ViewOptions) int {
+	if opts.Height <= 0 || m.ImpactSize > 0 || m.impactEditing {
+		return 0
+	}
+	return max(m.depth(opts.Height), m.ImbalanceLevels, m.WeightedMidLevels)
+}
+
+// renderContent renders the book, or its placeholder or warning.
+func (m *Model) renderContent(opts ViewOptions) string {
+	if m.FlagInvalid {
+		err := m.invalid
+		if err == nil {
+			err = m.OrderBook.Validate()
+		}
+		if err != nil {
+			return m.renderInvalid(err, opts)
+		}
+	}
+	if m.TextMode {
+		return m.renderText(opts)
+	}
+	if m.Skeleton && len(m.Bids) == 0 && len(m.Asks) == 0 {
+		return m.renderSkeleton(opts)
+	}
+	return m.renderBook(opts)
+}
+
+// renderImpact renders the book with the levels taken by the impact order
+// shaded, above a row with its average fill price.
+func (m *Model) renderImpact(opts ViewOptions) string {
+	side := "Buy"
+	if m.ImpactSide == Ask {
+		side = "Sell"
+	}
+	text := side + " " + formatNumber(m.ImpactSize)
+	if m.impactEditing {
+		text = side + " size: " + m.impactInput + "▏"
+	}
+	m.sweep = m.OrderBook.Sweep(m.ImpactSide, m.ImpactSize)
+	defer func() { m.sweep = Sweep{} }()
+	if price, ok := m.sweep.AveragePrice(); ok && !math.IsInf(price, 0) {
+		text += "  avg " + strconv.FormatFloat(price, 'f', m.PricePrecision, 64)
+	}
+	// Follow with what the order leaves: the rest of the order when the book
+	// is too thin, or the rest of the last level it takes.
+	if m.sweep.Unfilled > 0 && m.ImpactSize > 0 {
+		text += "  " + strconv.FormatFloat(m.sweep.Unfilled, 'f', m.VolumePrecision, 64) + " unfilled"
+	} else if n := len(m.sweep.Fills); n > 0 && m.sweep.Fills[n-1].Partial() {
+		last := m.sweep.Fills[n-1]
+		text += "  leaves " + strconv.FormatFloat(last.Residual(), 'f', m.VolumePrecision, 64) + " @ " + m.priceString(last.Order)
+	}
 
+	line := ansi.Truncate(text, opts.Width, "…")
+	if opts.Height == 1 {
+		// Only the order fits.
+		if m.TextMode {
+			return line
+		}
+		return m.StyleOffBar.Width(opts.Width).Render(line)
+	}
+	opts.Height = max(opts.Height-1, 0)
+	if m.TextMode {
+		return m.renderContent(opts) + "\n" + line
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, m.renderContent(opts), m.StyleOffBar.Width(opts.Width).Render(line))
+}
+
+// renderInvalid renders the book below a warning of the problems with it.
+func (m *Model) renderInvalid(err error, opts ViewOptions) string {
+	text := strings.ReplaceAll(err.Error(), "\n", "; ")
+	if m.TextMode {
+		warning := ansi.Truncate("Warning: "+text, opts.Width, "…")
+		if opts.Height == 1 {
+			return warning
+		}
+		opts.Height = max(opts.Height-1, 0)
+		return warning + "\n" + m.renderText(opts)
+	}
+	warning := m.StyleInvalid.Width(opts.Width).Render(ansi.Truncate("⚠ "+text, opts.Width, "…"))
+	if opts.Height == 1 {
+		// Only the warning fits.
+		return warning
+	}
+	opts.Height = max(opts.Height-1, 0)
+	return lipgloss.JoinVertical(lipgloss.Left, warning, m.renderBook(opts))
+}
+
+// renderBook renders the bars of the book in its orientation.
+func (m *Model) renderBook(opts ViewOptions) string {
 	switch m.Orientation {
 	case Vertical:
 		// Sort the bids and asks before rendering.
 		m.sortBids(true)
 		m.sortAsks(true)
 
+		// Leave just the spread when there's no room for a level either side.
+		if opts.Height > 0 && opts.Height < 3 {
+			return lipgloss.Place(opts.Width, opts.Height, lipgloss.Center, lipgloss.Center, m.renderSpread(opts.Width))
+		}
+
 		// Truncate the bids and asks if a height is specified.
 		// Account for the spread when using Vertical orientation
-		bids, asks := m.truncateOrders((opts.Height - 1) / 2)
+		bids, asks := m.truncateOrders(m.depth((opts.Height - 1) / 2))
+		m.countWidth = countWidth(bids, asks)
 
 		// Find the maximum volume in the order book to scale the bars correctly.
 		maxVolume := m.calculateMaxVolume(bids, asks)
 
+		// Work out how much text fits on each row.
+		d := m.textDetail(bids, asks, opts.Width)
+
 		// Render the bid and ask sides of the book.
-		askView := m.renderVerticalAsks(asks, opts.Width, maxVolume)
-		spreadView := m.renderSpread(opts.Width)
-		bidView := m.renderVerticalBids(bids, opts.Width, maxVolume)
+		buf := &m.rows
+		buf.startFrame()
+		priceFirst := m.Alignment == AlignRight
+		m.renderRows(&buf.asks, asks, Ask, opts.Width, maxVolume, d, priceFirst, !priceFirst)
+		buf.spread = m.appendSpread(buf.spread[:0], opts.Width)
+		m.renderRows(&buf.bids, bids, Bid, opts.Width, maxVolume, d, priceFirst, !priceFirst)
 
-		bookPanel := lipgloss.JoinVertical(lipgloss.Left, askView, spreadView, bidView)
-		// bookPanel := lipgloss.JoinVertical(lipgloss.Left, askView)
+		// Lay a full book out straight into the frame, every line the width
+		// of the pane.
+		if len(bids) > 0 && len(asks) > 0 && !buf.rendered {
+			buf.beginFrame(opts.Width, opts.Height, opts.Width, len(asks)+1+len(bids))
+			for i := range asks {
+				buf.line(buf.asks.row(i))
+			}
+			buf.line(buf.spread)
+			for i := range bids {
+				buf.line(buf.bids.row(i))
+			}
+			return buf.endFrame()
+		}
+
+		bookPanel := lipgloss.JoinVertical(lipgloss.Left, buf.asks.String(), string(buf.spread), buf.bids.String())
 
 		// Place the book panel in the center of the available space.
 		return lipgloss.Place(
@@ -158,22 +659,55 @@ func (m *Model) ViewWithOptions(opts ViewOptions) string {
 		m.sortAsks(false)
 
 		// Truncate the bids and asks if a height is specified.
-		bids, asks := m.truncateOrders(opts.Height)
+		bids, asks := m.truncateOrders(m.depth(opts.Height))
+		m.countWidth = countWidth(bids, asks)
 
-		// Calculate the width of each column.
-		columnWidth := (opts.Width - m.Spacing) / 2
+		// Calculate the width of each column. When the pane is too narrow for
+		// the text, the spacing between the columns is dropped first.
+		spacing := min(max(m.Spacing, 0), opts.Width)
+		columnWidth := max((opts.Width-spacing)/2, 0)
+		d := m.textDetail(bids, asks, columnWidth)
+		if wide := max(opts.Width/2, 0); spacing > 0 && m.textDetail(bids, asks, wide) < d {
+			spacing, columnWidth = 0, wide
+			d = m.textDetail(bids, asks, wide)
+		}
 
 		// Find the maximum volume in the order book to scale the bars correctly.
 		maxVolume := m.calculateMaxVolume(bids, asks)
 		// Render the bid and ask sides of the book.
-		bidView := m.renderBids(bids, columnWidth, maxVolume)
-		askView := m.renderAsks(asks, columnWidth, maxVolume)
+		buf := &m.rows
+		buf.startFrame()
+		m.renderRows(&buf.bids, bids, Bid, columnWidth, maxVolume, d, true, false)
+		m.renderRows(&buf.asks, asks, Ask, columnWidth, maxVolume, d, false, true)
+
+		// Lay the columns out side by side straight into the frame, padding
+		// the shorter with blank rows.
+		if len(bids) > 0 && len(asks) > 0 && columnWidth > 0 && !buf.rendered {
+			lines := max(len(bids), len(asks))
+			buf.beginFrame(opts.Width, opts.Height, 2*columnWidth+spacing, lines)
+			for i := range lines {
+				buf.beginLine()
+				if i < len(bids) {
+					buf.frame = append(buf.frame, buf.bids.row(i)...)
+				} else {
+					buf.frame = appendSpaces(buf.frame, columnWidth)
+				}
+				buf.frame = appendSpaces(buf.frame, spacing)
+				if i < len(asks) {
+					buf.frame = append(buf.frame, buf.asks.row(i)...)
+				} else {
+					buf.frame = appendSpaces(buf.frame, columnWidth)
+				}
+				buf.endLine()
+			}
+			return buf.endFrame()
+		}
 
 		// Create a spacer between the two columns.
-		spacer := lipgloss.NewStyle().Width(m.Spacing).Render("")
+		spacer := lipgloss.NewStyle().Width(spacing).Render("")
 
 		// Join the bid, spacer, and ask views horizontally.
-		bookPanel := lipgloss.JoinHorizontal(lipgloss.Top, bidView, spacer, askView)
+		bookPanel := lipgloss.JoinHorizontal(lipgloss.Top, buf.bids.String(), spacer, buf.asks.String())
 
 		// Place the book panel in the center of the available space.
 		return lipgloss.Place(
@@ -187,123 +721,518 @@ func (m *Model) ViewWithOptions(opts ViewOptions) string {
 	return ""
 }
 
-// renderSpread renders the spread between the best bid and ask.
-func (m *Model) renderSpread(width int) string {
-	if len(m.Asks) == 0 || len(m.Bids) == 0 {
-		return ""
-	}
-	bestAsk := m.Asks[len(m.Asks)-1].Price
-	bestBid := m.Bids[0].Price
-	spread := bestAsk - bestBid
-	priceFormat := fmt.Sprintf("Spread: %%.%df", m.PricePrecision)
-	spreadString := fmt.Sprintf(priceFormat, spread)
-	align := lipgloss.Left
-	if m.Alignment == AlignLeft {
-		align = lipgloss.Right
+// renderSkeleton renders dimmed placeholder rows in the same layout as the book.
+func (m *Model) renderSkeleton(opts ViewOptions) string {
+	var bookPanel string
+	switch m.Orientation {
+	case Vertical:
+		rows := m.depth((opts.Height - 1) / 2)
+		if rows <= 0 {
+			rows = defaultSkeletonDepth
+		}
+		// A blank row stands in for the spread, leaving no room for a level
+		// either side of it in fewer than three rows.
+		if opts.Height <= 0 || opts.Height >= 3 {
+			side := m.skeletonRows(rows, opts.Width)
+			bookPanel = lipgloss.JoinVertical(lipgloss.Left, side, "", side)
+		}
+	default:
+		rows := m.depth(opts.Height)
+		if rows <= 0 {
+			rows = defaultSkeletonDepth
+		}
+		spacing := min(max(m.Spacing, 0), opts.Width)
+		side := m.skeletonRows(rows, max((opts.Width-spacing)/2, 0))
+		spacer := lipgloss.NewStyle().Width(spacing).Render("")
+		bookPanel = lipgloss.JoinHorizontal(lipgloss.Top, side, spacer, side)
+	}
+	return lipgloss.Place(
+		opts.Width,
+		opts.Height,
+		lipgloss.Center,
+		lipgloss.Center,
+		bookPanel,
+	)
+}
+
+// skeletonRows renders a column of placeholder rows.
+func (m *Model) skeletonRows(rows, width int) string {
+	row := m.StyleSkeleton.Render(strings.Repeat("░", width))
+	return strings.TrimSuffix(strings.Repeat(row+"\n", rows), "\n")
+}
+
+// renderLoading renders the spinner in the center of the view.
+func (m *Model) renderLoading(opts ViewOptions) string {
+	if m.TextMode {
+		return "Loading..."
 	}
-	return lipgloss.NewStyle().Width(width).Align(align).Render(m.StyleOffBar.Render(spreadString))
+	return lipgloss.Place(
+		opts.Width,
+		opts.Height,
+		lipgloss.Center,
+		lipgloss.Center,
+		m.Spinner.View()+m.StyleLoading.Render(" Loading..."),
+	)
 }
 
-// renderVerticalBids renders the bid side of the order book for vertical orientation.
-func (m *Model) renderVerticalBids(orders []Order, width int, maxVolume float64) string {
-	rows := make([]string, 0, len(orders))
-	priceFormat := fmt.Sprintf("%%.%df", m.PricePrecision)
-	volumeFormat := fmt.Sprintf("%%.%df", m.VolumePrecision)
+// renderError renders the error as a banner in the center of the view.
+func (m *Model) renderError(opts ViewOptions) string {
+	text := "Error: " + m.err.Error()
+	if m.TextMode {
+		return ansi.Wrap(text, opts.Width, "")
+	}
+	// Wrap the message to fit inside the banner's padding.
+	inner := max(opts.Width-m.StyleError.GetHorizontalFrameSize(), 1)
+	banner := m.StyleError.Render(ansi.Wrap(text, inner, ""))
+	return lipgloss.Place(
+		opts.Width,
+		opts.Height,
+		lipgloss.Center,
+		lipgloss.Center,
+		banner,
+	)
+}
 
-	for _, o := range orders {
-		priceString := fmt.Sprintf(priceFormat, o.Price)
-		volumeString := fmt.Sprintf(volumeFormat, o.Volume)
+// renderText renders the book as plain text, with the spread followed by the
+// asks and bids, best first. Rows of the table are cut to the width.
+func (m *Model) renderText(opts ViewOptions) string {
+	m.sortBids(true)
+	m.sortAsks(false)
 
-		padding := width - len(priceString) - len(volumeString)
-		if padding < 0 {
-			padding = 0
-		}
+	// Leave room for the spread and the column headings.
+	depth := 0
+	if opts.Height > 0 {
+		depth = max((opts.Height-2)/2, 1)
+	}
+	depth = m.depth(depth)
+	bids, asks := m.Bids, m.Asks
+	if depth > 0 {
+		bids, asks = bids[:min(len(bids), depth)], asks[:min(len(asks), depth)]
+	}
 
-		var output string
-		if m.Alignment == AlignLeft {
-			output = fmt.Sprintf("%s%s%s", volumeString, strings.Repeat(" ", padding), priceString)
-		} else {
-			output = fmt.Sprintf("%s%s%s", priceString, strings.Repeat(" ", padding), volumeString)
+	priceWidth, volumeWidth, countWidth := len("PRICE"), len("VOLUME"), len("COUNT")
+	for _, o := range append(asks[:len(asks):len(asks)], bids...) {
+		priceWidth = max(priceWidth, len(m.priceString(o)))
+		volumeWidth = max(volumeWidth, len(m.volumeString(o)))
+		countWidth = max(countWidth, len(strconv.Itoa(o.Count)))
+	}
+	// The count column is added on the end when shown.
+	count := func(o Order) string {
+		switch {
+		case !m.ShowCount:
+			return ""
+		case o.Count > 0:
+			return fmt.Sprintf(" %*d", countWidth, o.Count)
 		}
+		return fmt.Sprintf(" %*s", countWidth, "-")
+	}
 
-		onLen := int(float64(width) * (o.Volume / maxVolume))
-		offLen := width - onLen
+	var spread string
+	switch {
+	case len(bids) == 0 && len(asks) == 0:
+		spread = "The order book is empty."
+	case len(bids) == 0:
+		spread = "No bids. Best ask " + m.priceString(asks[0]) + "."
+	case len(asks) == 0:
+		spread = "No asks. Best bid " + m.priceString(bids[0]) + "."
+	default:
+		spread = "Spread " + m.spreadString(bids[0], asks[0]) + " between best bid " +
+			m.priceString(bids[0]) + " and best ask " + m.priceString(asks[0]) + "."
+	}
+	if prices := m.spreadPrices(); len(prices) > 0 {
+		spread += " " + strings.Join(prices, ", ") + "."
+	}
 
-		var bar string
-		if m.Alignment == AlignLeft {
-			onStr := m.StyleOnBid.Width(onLen).Render(output[:onLen])
-			offStr := m.StyleOffBar.Width(offLen).Render(output[onLen:])
-			bar = lipgloss.JoinHorizontal(lipgloss.Left, onStr, offStr)
-		} else {
-			offStr := m.StyleOffBar.Width(offLen).Render(output[:offLen])
-			onStr := m.StyleOnBid.Width(onLen).Render(output[offLen:])
-			bar = lipgloss.JoinHorizontal(lipgloss.Right, offStr, onStr)
+	// Wrap the spread rather than cutting it, so it can always be read in full.
+	lines := strings.Split(ansi.Wrap(spread, opts.Width, ""), "\n")
+	header := fmt.Sprintf("%-4s %*s %*s", "SIDE", priceWidth, "PRICE", volumeWidth, "VOLUME")
+	if m.ShowCount {
+		header += fmt.Sprintf(" %*s", countWidth, "COUNT")
+	}
+	lines = append(lines, header)
+	for _, side := range []struct {
+		label  string
+		side   Side
+		orders []Order
+	}{{"ASK", Ask, asks}, {"BID", Bid, bids}} {
+		for _, o := range side.orders {
+			line := fmt.Sprintf("%-4s %*s %*s", side.label,
+				priceWidth, m.priceString(o),
+				volumeWidth, m.volumeString(o)) + count(o)
+			// Levels the impact order takes are marked in words.
+			if f, ok := m.sweep.fill(o, side.side); ok && f.Partial() {
+				line += " PARTIAL"
+			} else if ok {
+				line += " TAKEN"
+			}
+			lines = append(lines, line)
 		}
-		rows = append(rows, bar)
 	}
-	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, opts.Width, "…")
+	}
+	return strings.Join(lines, "\n")
 }
 
-// renderVerticalAsks renders the ask side of the order book for vertical orientation.
-func (m *Model) renderVerticalAsks(orders []Order, width int, maxVolume float64) string {
-	rows := make([]string, 0, len(orders))
-	priceFormat := fmt.Sprintf("%%.%df", m.PricePrecision)
-	volumeFormat := fmt.Sprintf("%%.%df", m.VolumePrecision)
+// recordSpread adds the spread to the history when it has changed.
+func (m *Model) recordSpread() {
+	if m.SpreadHistory <= 0 {
+		m.spreads = nil
+		return
+	}
+	spread, ok := m.OrderBook.Spread()
+	if !ok || (len(m.spreads) > 0 && m.spreads[len(m.spreads)-1] == spread) {
+		return
+	}
+	m.spreads = append(m.spreads, spread)
+	if len(m.spreads) > m.SpreadHistory {
+		m.spreads = m.spreads[len(m.spreads)-m.SpreadHistory:]
+	}
+}
 
-	for _, o := range orders {
-		priceString := fmt.Sprintf(priceFormat, o.Price)
-		volumeString := fmt.Sprintf(volumeFormat, o.Volume)
+// levelKey identifies a level of the book.
+type levelKey struct {
+	side  Side
+	price float64
+}
 
-		padding := width - len(priceString) - len(volumeString)
-		if padding < 0 {
-			padding = 0
+// recordFlashes records when the levels that changed since the last render
+// changed, and forgets those that have finished flashing. Nothing flashes on
+// the first render.
+func (m *Model) recordFlashes() {
+	if m.FlashFor <= 0 {
+		m.flashed, m.flashBook = nil, OrderBook{}
+		return
+	}
+	now := time.Now()
+	if m.flashed == nil {
+		m.flashed = map[levelKey]time.Time{}
+	} else {
+		for _, c := range Diff(m.flashBook, m.OrderBook) {
+			if c.Kind() != Removed {
+				m.flashed[levelKey{c.Side, c.Price}] = now
+			}
 		}
+		maps.DeleteFunc(m.flashed, func(_ levelKey, t time.Time) bool {
+			return now.Sub(t) >= m.FlashFor
+		})
+	}
+	m.flashBook.Bids = append(m.flashBook.Bids[:0], m.Bids...)
+	m.flashBook.Asks = append(m.flashBook.Asks[:0], m.Asks...)
+}
+
+// flashing reports whether a level is flashing.
+func (m *Model) flashing(o Order, side Side) bool {
+	t, ok := m.flashed[levelKey{side, o.Price}]
+	return ok && time.Since(t) < m.FlashFor
+}
+
+// Spreads returns the recent spreads kept when SpreadHistory is set, oldest
+// first.
+func (m *Model) Spreads() []float64 {
+	return m.spreads
+}
+
+// renderSpread renders the spread between the best bid and ask.
+func (m *Model) renderSpread(width int) string {
+	m.rows.startFrame()
+	return string(m.appendSpread(nil, width))
+}
 
-		var output string
+// appendSpread appends the spread between the best bid and ask, the width of
+// the row, or nothing when a side is empty.
+func (m *Model) appendSpread(b []byte, width int) []byte {
+	bid, hasBid := m.bestBid()
+	ask, hasAsk := m.bestAsk()
+	if !hasBid || !hasAsk {
+		return b
+	}
+	buf := &m.rows
+	text := m.appendSpreadString(append(buf.text[:0], "Spread: "...), bid, ask)
+	barWidth := 0
+	// Drop the label, and then shorten the spread, if they don't fit.
+	if len(text) <= width {
+		// Make room for the imbalance bar, then follow the spread with its
+		// history, shortened to the room left, and as many of the prices as fit.
+		if m.ImbalanceLevels > 0 {
+			if room := width - len(text) - 1; room >= minImbalanceWidth {
+				barWidth = min(room, imbalanceWidth)
+			}
+		}
+		if len(m.spreads) > 1 {
+			room := width - len(text) - 1
+			if barWidth > 0 {
+				room -= barWidth + 1
+			}
+			if n := min(len(m.spreads), room); n > 1 {
+				text = append(append(text, ' '), sparkline.Render(m.spreads, n)...)
+			}
+		}
+		for _, price := range m.spreadPrices() {
+			if utf8.RuneCount(text)+2+len(price)+barWidth+1 > width {
+				break
+			}
+			text = append(append(text, "  "...), price...)
+		}
+	} else {
+		text = append(text[:0], ansi.Truncate(string(text[len("Spread: "):]), width, "…")...)
+	}
+	buf.text = text
+	textWidth := utf8.RuneCount(text)
+	if barWidth > 0 {
+		// The bar goes on the edge away from the spread.
 		if m.Alignment == AlignLeft {
-			output = fmt.Sprintf("%s%s%s", volumeString, strings.Repeat(" ", padding), priceString)
-		} else {
-			output = fmt.Sprintf("%s%s%s", priceString, strings.Repeat(" ", padding), volumeString)
+			b = append(b, m.imbalanceBar(barWidth)...)
+			b = appendSpaces(b, width-textWidth-barWidth)
+			return buf.appendStyled(b, m.StyleOffBar, text)
 		}
+		b = buf.appendStyled(b, m.StyleOffBar, text)
+		b = appendSpaces(b, width-textWidth-barWidth)
+		return append(b, m.imbalanceBar(barWidth)...)
+	}
+	if m.Alignment == AlignLeft {
+		return buf.appendStyled(appendSpaces(b, width-textWidth), m.StyleOffBar, text)
+	}
+	return appendSpaces(buf.appendStyled(b, m.StyleOffBar, text), width-textWidth)
+}
 
-		onLen := int(float64(width) * (o.Volume / maxVolume))
-		offLen := width - onLen
+// imbalanceBar renders a bar of the given width split between the bid and
+// ask volume over the best ImbalanceLevels, bids on the left.
+func (m *Model) imbalanceBar(width int) string {
+	_, bidVolume := vwap(topLevels(m.Bids, m.bidsSorted, m.ImbalanceLevels, 1))
+	_, askVolume := vwap(topLevels(m.Asks, m.asksSorted, m.ImbalanceLevels, -1))
+	if bidVolume+askVolume <= 0 {
+		return m.StyleOffBar.Render(strings.Repeat("─", width))
+	}
+	bidWidth := int(math.Round(float64(width) * bidVolume / (bidVolume + askVolume)))
+	return m.StyleOnBid.Render(strings.Repeat(" ", bidWidth)) +
+		m.StyleOnAsk.Render(strings.Repeat(" ", width-bidWidth))
+}
 
-		var bar string
-		if m.Alignment == AlignLeft {
-			onStr := m.StyleOnAsk.Width(onLen).Render(output[:onLen])
-			offStr := m.StyleOffBar.Width(offLen).Render(output[onLen:])
-			bar = lipgloss.JoinHorizontal(lipgloss.Left, onStr, offStr)
-		} else {
-			offStr := m.StyleOffBar.Render(output[:offLen])
-			onStr := m.StyleOnAsk.Render(output[offLen:])
-			bar = lipgloss.JoinHorizontal(lipgloss.Right, offStr, onStr)
+// spreadPrices returns the labelled prices to show after the spread.
+func (m *Model) spreadPrices() []string {
+	var prices []string
+	// Prices that overflow, e.g. weighting huge volumes, are left out.
+	add := func(label string, price float64, ok bool) {
+		if ok && !math.IsNaN(price) && !math.IsInf(price, 0) {
+			prices = append(prices, label+": "+strconv.FormatFloat(price, 'f', m.PricePrecision, 64))
+		}
+	}
+	if m.ShowMid {
+		mid, ok := m.OrderBook.MidPrice()
+		add("Mid", mid, ok)
+	}
+	if m.ShowMicroprice {
+		micro, ok := m.OrderBook.Microprice()
+		add("Micro", micro, ok)
+	}
+	if m.WeightedMidLevels > 0 {
+		wmid, ok := m.OrderBook.WeightedMid(m.WeightedMidLevels)
+		add(fmt.Sprintf("WMid(%d)", m.WeightedMidLevels), wmid, ok)
+	}
+	return prices
+}
+
+// priceString formats the price of an order, as the source gave it when it
+// has a PriceText.
+func (m *Model) priceString(o Order) string {
+	if o.PriceText != "" {
+		return o.PriceText
+	}
+	return strconv.FormatFloat(o.Price, 'f', m.PricePrecision, 64)
+}
+
+// volumeString formats the volume of an order, as the source gave it when it
+// has a VolumeText.
+func (m *Model) volumeString(o Order) string {
+	if o.VolumeText != "" {
+		return o.VolumeText
+	}
+	return strconv.FormatFloat(o.Volume, 'f', m.VolumePrecision, 64)
+}
+
+// appendPrice appends the price of an order, like priceString.
+func (m *Model) appendPrice(b []byte, o Order) []byte {
+	if o.PriceText != "" {
+		return append(b, o.PriceText...)
+	}
+	return strconv.AppendFloat(b, o.Price, 'f', m.PricePrecision, 64)
+}
+
+// appendVolume appends the volume of an order, like volumeString.
+func (m *Model) appendVolume(b []byte, o Order) []byte {
+	if o.VolumeText != "" {
+		return append(b, o.VolumeText...)
+	}
+	return strconv.AppendFloat(b, o.Volume, 'f', m.VolumePrecision, 64)
+}
+
+// levelStyles returns the styles for the bar and the rest of a level's row
+// on a side, shaded when the impact order takes all or part of the level,
+// highlighted when it has just changed and made faint when it is stale.
+func (m *Model) levelStyles(o Order, side Side) (lipgloss.Style, lipgloss.Style) {
+	on, off := m.StyleOnBid, m.StyleOffBar
+	if side == Ask {
+		on = m.StyleOnAsk
+	}
+	if f, ok := m.sweep.fill(o, side); ok && f.Partial() {
+		off = m.StyleImpactPartial.Inherit(off)
+	} else if ok {
+		off = m.StyleImpact.Inherit(off)
+	}
+	if m.flashing(o, side) {
+		return m.StyleFlash.Inherit(on), m.StyleFlash.Inherit(off)
+	}
+	if m.FadeAfter > 0 && !o.Time.IsZero() && time.Since(o.Time) > m.FadeAfter {
+		return on.Faint(true), off.Faint(true)
+	}
+	return on, off
+}
+
+// appendVolumeColumn appends the volume of an order along with its count
+// when ShowCount is set, with the count last or first so it is on the outer
+// edge of the row. Counts are padded to line up.
+func (m *Model) appendVolumeColumn(b []byte, o Order, countLast bool) []byte {
+	if !m.ShowCount || m.countWidth == 0 {
+		return m.appendVolume(b, o)
+	}
+	var digits [20]byte
+	count := digits[:0]
+	if o.Count > 0 {
+		count = strconv.AppendInt(count, int64(o.Count), 10)
+	}
+	pad := m.countWidth - len(count)
+	if len(count) > 0 {
+		pad -= 2
+	}
+	if countLast {
+		b = m.appendVolume(b, o)
+		b = appendSpaces(append(b, ' '), pad)
+		return appendCount(b, count)
+	}
+	b = appendSpaces(appendCount(b, count), pad)
+	return m.appendVolume(append(b, ' '), o)
+}
+
+// appendCount appends the digits of a count in brackets, or nothing when there
+// are none.
+func appendCount(b, digits []byte) []byte {
+	if len(digits) == 0 {
+		return b
+	}
+	b = append(b, '(')
+	b = append(b, digits...)
+	return append(b, ')')
+}
+
+// countWidth returns the width of the widest count, with its brackets, or
+// zero if no order has a count.
+func countWidth(bids, asks []Order) int {
+	width := 0
+	for _, orders := range [][]Order{bids, asks} {
+		for _, o := range orders {
+			if o.Count > 0 {
+				width = max(width, len(strconv.Itoa(o.Count))+2)
+			}
 		}
-		rows = append(rows, bar)
 	}
-	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+	return width
+}
+
+// spreadString formats the spread between the best bid and ask, like
+// appendSpreadString.
+func (m *Model) spreadString(bid, ask Order) string {
+	return string(m.appendSpreadString(nil, bid, ask))
+}
+
+// appendSpreadString appends the spread between the best bid and ask. When
+// both prices are exact the spread is worked out exactly, with as many
+// decimals as the prices.
+func (m *Model) appendSpreadString(b []byte, bid, ask Order) []byte {
+	if bid.PriceText == "" || ask.PriceText == "" {
+		return strconv.AppendFloat(b, ask.Price-bid.Price, 'f', m.PricePrecision, 64)
+	}
+	d := max(decimals(bid.PriceText), decimals(ask.PriceText))
+	// Prices that fit an int64 of d decimals are subtracted as integers.
+	if bidScaled, ok := parseScaled(bid.PriceText, d); ok {
+		if askScaled, ok := parseScaled(ask.PriceText, d); ok {
+			return appendScaled(b, askScaled-bidScaled, d)
+		}
+	}
+	spread := new(big.Float).Sub(ask.BigPrice(), bid.BigPrice())
+	return spread.Append(b, 'f', d)
 }
 
 // sortBids sorts the bids in descending order by price.
 func (m *Model) sortBids(desc bool) {
-	sort.Slice(m.Bids, func(i, j int) bool {
+	if m.bidsSorted && desc {
+		return
+	}
+	slices.SortFunc(m.Bids, func(a, b Order) int {
 		if desc {
-			return m.Bids[i].Price > m.Bids[j].Price
+			return comparePrices(b, a)
 		}
-		return m.Bids[i].Price < m.Bids[j].Price
+		return comparePrices(a, b)
 	})
 }
 
 // sortAsks sorts the asks in ascending order by price.
 func (m *Model) sortAsks(desc bool) {
-	sort.Slice(m.Asks, func(i, j int) bool {
+	if m.asksSorted {
 		if desc {
-			return m.Asks[i].Price > m.Asks[j].Price
+			slices.Reverse(m.Asks)
+			m.asksSorted = false
 		}
-		return m.Asks[i].Price < m.Asks[j].Price
+		return
+	}
+	slices.SortFunc(m.Asks, func(a, b Order) int {
+		if desc {
+			return comparePrices(b, a)
+		}
+		return comparePrices(a, b)
 	})
 }
 
+// bestBid returns the best bid, and false if there are no bids.
+func (m *Model) bestBid() (Order, bool) {
+	if m.bidsSorted {
+		if len(m.Bids) == 0 {
+			return Order{}, false
+		}
+		return m.Bids[0], true
+	}
+	return m.OrderBook.BestBid()
+}
+
+// bestAsk returns the best ask, and false if there are no asks.
+func (m *Model) bestAsk() (Order, bool) {
+	if m.asksSorted {
+		if len(m.Asks) == 0 {
+			return Order{}, false
+		}
+		return m.Asks[0], true
+	}
+	return m.OrderBook.BestAsk()
+}
+
+// topLevels returns up to n of the best orders on a side, best first, sliced
+// from the orders when they're sorted best first.
+func topLevels(orders []Order, sorted bool, n, direction int) []Order {
+	if sorted {
+		return orders[:min(max(n, 0), len(orders))]
+	}
+	return bestLevels(orders, n, direction)
+}
+
+// depth returns the number of levels to show on each side of the book given
+// the rows available, where zero means no limit.
+func (m *Model) depth(rows int) int {
+	if m.Depth > 0 && (rows <= 0 || m.Depth < rows) {
+		return m.Depth
+	}
+	return rows
+}
+
 // truncateOrders truncates the bids and asks to the given height.
 func (m *Model) truncateOrders(height int) ([]Order, []Order) {
 	bids := m.Bids
@@ -345,58 +1274,70 @@ func (m *Model) calculateMaxVolume(bids, asks []Order) float64 {
 	return maxVolume
 }
 
-// renderBids renders the bid side of the order book.
-func (m *Model) renderBids(orders []Order, width int, maxVolume float64) string {
-	rows := make([]string, 0, len(orders))
-	priceFormat := fmt.Sprintf("%%.%df", m.PricePrecision)
-	volumeFormat := fmt.Sprintf("%%.%df", m.VolumePrecision)
-
-	for _, o := range orders {
-		priceString := fmt.Sprintf(priceFormat, o.Price)
-		volumeString := fmt.Sprintf(volumeFormat, o.Volume)
-
-		padding := width - len(priceString) - len(volumeString)
-		if padding < 0 {
-			padding = 0
+// textDetail returns how much text fits in a column of the given width. The
+// volume is dropped first, as the bar still shows it, and then the price is
+// shortened until only a couple of digits would be left.
+func (m *Model) textDetail(bids, asks []Order, width int) detail {
+	buf := &m.rows
+	priceWidth, volumeWidth := 0, 0
+	for _, orders := range [][]Order{bids, asks} {
+		for _, o := range orders {
+			buf.price = m.appendPrice(buf.price[:0], o)
+			buf.volume = m.appendVolumeColumn(buf.volume[:0], o, true)
+			priceWidth = max(priceWidth, len(buf.price))
+			volumeWidth = max(volumeWidth, len(buf.volume))
 		}
-		output := fmt.Sprintf("%s%s%s", priceString, strings.Repeat(" ", padding), volumeString)
-
-		onLen := int(float64(width) * (o.Volume / maxVolume))
-		offLen := width - onLen
-
-		offStr := m.StyleOffBar.Width(offLen).Render(output[:offLen])
-		onStr := m.StyleOnBid.Width(onLen).Render(output[offLen:])
-
-		bar := lipgloss.JoinHorizontal(lipgloss.Right, offStr, onStr)
-		rows = append(rows, bar)
 	}
-	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+	switch {
+	case priceWidth+1+volumeWidth <= width:
+		return detailFull
+	case width >= min(priceWidth, minPriceWidth):
+		return detailPrice
+	default:
+		return detailBars
+	}
 }
 
-// renderAsks renders the ask side of the order book.
-func (m *Model) renderAsks(orders []Order, width int, maxVolume float64) string {
-	rows := make([]string, 0, len(orders))
-	priceFormat := fmt.Sprintf("%%.%df", m.PricePrecision)
-	volumeFormat := fmt.Sprintf("%%.%df", m.VolumePrecision)
-
-	for _, o := range orders {
-		priceString := fmt.Sprintf(priceFormat, o.Price)
-		volumeString := fmt.Sprintf(volumeFormat, o.Volume)
-
-		padding := width - len(priceString) - len(volumeString)
+// appendRowText lays out the text for a row of the given width, with the
+// price first or last and the volume at the other end, showing as much as the
+// detail allows. A price that doesn't fit is shortened with an ellipsis.
+func appendRowText(b, price, volume []byte, width int, priceFirst bool, d detail) []byte {
+	switch d {
+	case detailFull:
+		padding := width - len(price) - len(volume)
+		if priceFirst {
+			return append(appendSpaces(append(b, price...), padding), volume...)
+		}
+		return append(appendSpaces(append(b, volume...), padding), price...)
+	case detailPrice:
+		padding := width - len(price)
 		if padding < 0 {
-			padding = 0
+			// Only shortened prices are copied.
+			truncated := ansi.Truncate(string(price), width, "…")
+			price, padding = []byte(truncated), width-ansi.StringWidth(truncated)
 		}
-		output := fmt.Sprintf("%s%s%s", volumeString, strings.Repeat(" ", padding), priceString)
-
-		onLen := int(float64(width) * (o.Volume / maxVolume))
-		offLen := width - onLen
+		if priceFirst {
+			return appendSpaces(append(b, price...), padding)
+		}
+		return append(appendSpaces(b, padding), price...)
+	default:
+		return appendSpaces(b, width)
+	}
+}
 
-		onStr := m.StyleOnAsk.Width(onLen).Render(output[:onLen])
-		offStr := m.StyleOffBar.Width(offLen).Render(output[onLen:])
+// appendSpaces appends n spaces, or none when n isn't positive.
+func appendSpaces(b []byte, n int) []byte {
+	for ; n > 0; n-- {
+		b = append(b, ' ')
+	}
+	return b
+}
 
-		bar := lipgloss.JoinHorizontal(lipgloss.Left, onStr, offStr)
-		rows = append(rows, bar)
+// barLength returns the length of the volume bar for a row of the given width.
+func barLength(volume, maxVolume float64, width int) int {
+	ratio := math.Min(volume/maxVolume, 1)
+	if !(ratio > 0) || !(maxVolume > 0) {
+		return 0
 	}
-	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+	return int(float64(width) * ratio)
 }
@@ -0,0 +1,133 @@
+package clob
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Options of FuzzViewWithOptions, packed into a byte.
+const (
+	fuzzVertical uint8 = 1 << iota
+	fuzzTextMode
+	fuzzShowCount
+	fuzzFlagInvalid
+	fuzzImpact
+	fuzzSpreadPrices
+	fuzzSpreadRow
+	fuzzRightAligned
+)
+
+// fuzzInput is an input of FuzzViewWithOptions.
+type fuzzInput struct {
+	width, height                   int
+	pricePrecision, volumePrecision int
+	bidPrice, bidVolume             float64
+	askPrice, askVolume             float64
+	// depth is the gap in price to a second level on each side, with
+	// depthVolume.
+	depth, depthVolume float64
+	spacing            int
+	options            uint8
+}
+
+// fuzzCrashers are inputs that panicked or rendered garbage before rendering
+// was hardened against them.
+var fuzzCrashers = []fuzzInput{
+	// NaN shown in the spread row.
+	{width: 30, height: 8, pricePrecision: 2, volumePrecision: 2, bidPrice: math.NaN(), bidVolume: 1, askPrice: 101, askVolume: 1, depth: 1, depthVolume: 1, spacing: 1, options: fuzzVertical | fuzzFlagInvalid},
+	// Numbers that aren't finite making bars of a negative length.
+	{width: 30, height: 8, pricePrecision: 2, volumePrecision: 2, bidPrice: 99, bidVolume: math.Inf(1), askPrice: 101, askVolume: 1, depth: 1, depthVolume: 1, spacing: 1, options: fuzzVertical},
+	{width: 30, height: 8, pricePrecision: 2, volumePrecision: 2, bidPrice: 99, bidVolume: math.NaN(), askPrice: math.Inf(1), askVolume: 1, depth: 1, depthVolume: 1, spacing: 1},
+	// -Inf shown as a price.
+	{width: 30, height: 8, pricePrecision: 2, volumePrecision: 2, bidPrice: math.Inf(-1), bidVolume: 1, askPrice: 101, askVolume: math.Inf(-1), depth: 1, depthVolume: 1, spacing: 1, options: fuzzVertical | fuzzSpreadPrices | fuzzSpreadRow},
+	// Spacing wider than the pane.
+	{width: 3, height: 4, pricePrecision: 2, volumePrecision: 2, bidPrice: 99, bidVolume: 1, askPrice: 101, askVolume: 1, depth: 1, depthVolume: 1, spacing: 10},
+	{width: 1, height: 1, pricePrecision: 2, volumePrecision: 2, bidPrice: 99, bidVolume: 1, askPrice: 101, askVolume: 1, depth: 1, depthVolume: 1, spacing: 5, options: fuzzShowCount},
+	// An impact order of infinite size.
+	{width: 30, height: 8, pricePrecision: 2, volumePrecision: 2, bidPrice: 99, bidVolume: math.Inf(1), askPrice: 101, askVolume: 1, depth: 1, depthVolume: 1, spacing: 1, options: fuzzVertical | fuzzImpact},
+}
+
+// fuzzEdges are ordinary books, and books at the edges of what rendering
+// handles.
+var fuzzEdges = []fuzzInput{
+	{width: 40, height: 10, pricePrecision: 2, volumePrecision: 2, bidPrice: 99, bidVolume: 1, askPrice: 101, askVolume: 2, depth: 1, depthVolume: 3, spacing: 1, options: fuzzVertical},
+	{width: 40, height: 10, pricePrecision: 2, volumePrecision: 2, bidPrice: 99, bidVolume: 1, askPrice: 101, askVolume: 2, depth: 1, depthVolume: 3, spacing: 1},
+	// Volumes whose ratio to the largest overflows.
+	{width: 30, height: 8, pricePrecision: 2, volumePrecision: 2, bidPrice: 99, bidVolume: 5e-324, askPrice: 101, askVolume: 1e300, depth: 1, depthVolume: -1e300, spacing: 1, options: fuzzVertical},
+	{width: 30, height: 8, pricePrecision: 2, volumePrecision: 2, bidPrice: 1e300, bidVolume: 1e300, askPrice: -1e300, askVolume: 1e300, depth: 1e300, depthVolume: 1e300, spacing: 1, options: fuzzVertical | fuzzSpreadPrices},
+	// Every volume zero, so the largest volume is zero.
+	{width: 30, height: 8, pricePrecision: 2, volumePrecision: 2, bidPrice: 99, askPrice: 101, depth: 1, spacing: 1, options: fuzzVertical | fuzzSpreadRow},
+	{width: 30, height: 8, pricePrecision: 2, volumePrecision: 2, bidPrice: 99, askPrice: 101, depth: 1, spacing: 1},
+	// Negative prices and volumes.
+	{width: 30, height: 8, pricePrecision: 2, volumePrecision: 2, bidPrice: -1, bidVolume: -1, askPrice: -0.5, askVolume: 2, depth: -1, depthVolume: -2, spacing: 1, options: fuzzVertical | fuzzFlagInvalid | fuzzSpreadRow},
+	{width: 30, height: 8, pricePrecision: 2, volumePrecision: 2, bidPrice: 99, bidVolume: 1e300, askPrice: 101, askVolume: 1e300, depth: 1, depthVolume: 1e300, spacing: 1, options: fuzzImpact | fuzzTextMode},
+	// Negative and very large precisions.
+	{width: 30, height: 8, pricePrecision: -3, volumePrecision: 31, bidPrice: 99.5, bidVolume: 1, askPrice: 101.25, askVolume: 1, depth: 0.125, depthVolume: 1, spacing: 1, options: fuzzVertical | fuzzShowCount | fuzzRightAligned},
+}
+
+// FuzzViewWithOptions renders random books at random sizes, checking that
+// rendering doesn't panic, never shows NaN or Inf, and keeps to the pane:
+// every line of the book is the width of the pane, and there are as many
+// lines as its height. Text mode doesn't pad lines, and wraps the spread so
+// it can be read in full, so its lines are only checked to fit the width.
+func FuzzViewWithOptions(f *testing.F) {
+	for _, in := range append(fuzzCrashers, fuzzEdges...) {
+		f.Add(in.width, in.height, in.pricePrecision, in.volumePrecision,
+			in.bidPrice, in.bidVolume, in.askPrice, in.askVolume,
+			in.depth, in.depthVolume, in.spacing, in.options)
+	}
+	f.Fuzz(func(t *testing.T, width, height, pricePrecision, volumePrecision int,
+		bidPrice, bidVolume, askPrice, askVolume, depth, depthVolume float64,
+		spacing int, options uint8) {
+		// Keep sizes small enough to render quickly.
+		width, height, spacing = width%256, height%128, spacing%64
+		pricePrecision, volumePrecision = pricePrecision%32, volumePrecision%32
+
+		m := New()
+		m.Bids = []Order{{Price: bidPrice, Volume: bidVolume, Count: 1}, {Price: bidPrice - depth, Volume: depthVolume}}
+		m.Asks = []Order{{Price: askPrice, Volume: askVolume, Count: 2}, {Price: askPrice + depth, Volume: depthVolume}}
+		m.PricePrecision, m.VolumePrecision = pricePrecision, volumePrecision
+		m.Spacing = spacing
+		if options&fuzzVertical != 0 {
+			m.Orientation = Vertical
+		}
+		if options&fuzzRightAligned != 0 {
+			m.Alignment = AlignRight
+		}
+		m.TextMode = options&fuzzTextMode != 0
+		m.ShowCount = options&fuzzShowCount != 0
+		m.FlagInvalid = options&fuzzFlagInvalid != 0
+		if options&fuzzImpact != 0 {
+			m.ImpactSide, m.ImpactSize = Bid, bidVolume
+		}
+		if options&fuzzSpreadPrices != 0 {
+			m.ShowMid, m.ShowMicroprice, m.WeightedMidLevels = true, true, 2
+		}
+		if options&fuzzSpreadRow != 0 {
+			m.SpreadHistory, m.ImbalanceLevels = 5, 2
+		}
+
+		out := m.ViewWithOptions(ViewOptions{Width: width, Height: height})
+		if width <= 0 || height <= 0 {
+			return
+		}
+		lines := strings.Split(out, "\n")
+		for i, line := range lines {
+			w := ansi.StringWidth(line)
+			if m.TextMode && w > width || !m.TextMode && w != width {
+				t.Fatalf("line %d is %d wide, want %d: %q", i, w, width, ansi.Strip(line))
+			}
+			// Only the warning of a book that isn't finite may mention NaN
+			// or Inf.
+			if text := ansi.Strip(line); !strings.Contains(text, "finite") && (strings.Contains(text, "NaN") || strings.Contains(text, "Inf")) {
+				t.Fatalf("line %d shows a number that isn't finite: %q", i, text)
+			}
+		}
+		if !m.TextMode && len(lines) != height {
+			t.Fatalf("got %d lines, want %d:\n%s", len(lines), height, ansi.Strip(out))
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package clob
+
+// LevelChange describes a change in the volume at a price level. The old
+// volume is zero when the level was added, and the new volume zero when it
+// was removed.
+type LevelChange struct {
+	Side      Side
+	Price     float64
+	OldVolume float64
+	NewVolume float64
+}
+
+// ChangeKind is the kind of a change to a level.
+type ChangeKind int
+
+const (
+	// Changed means the volume at the level changed.
+	Changed ChangeKind = iota
+	// Added means the level is new.
+	Added
+	// Removed means the level was removed.
+	Removed
+)
+
+// Kind returns the kind of the change.
+func (c LevelChange) Kind() ChangeKind {
+	switch {
+	case c.OldVolume == 0:
+		return Added
+	case c.NewVolume == 0:
+		return Removed
+	}
+	return Changed
+}
+
+// Diff returns the changes to the levels from book a to book b, bids then
+// asks, e.g. to highlight the levels that changed between two snapshots. On
+// each side the levels of a that were removed or changed come first, in the
+// order of a, then the levels added in b, in the order of b.
+func Diff(a, b OrderBook) []LevelChange {
+	return append(diffSide(Bid, a.Bids, b.Bids), diffSide(Ask, a.Asks, b.Asks)...)
+}
+
+// diffSide returns the changes to the levels of a side of the book: the
+// levels of a that were removed or changed, then the levels added in b.
+func diffSide(side Side, a, b []Order) []LevelChange {
+	volumes := make(map[float64]float64, len(b))
+	for _, o := range b {
+		volumes[o.Price] = o.Volume
+	}
+	var changes []LevelChange
+	seen := make(map[float64]bool, len(a))
+	for _, o := range a {
+		seen[o.Price] = true
+		if v := volumes[o.Price]; v != o.Volume {
+			changes = append(changes, LevelChange{Side: side, Price: o.Price, OldVolume: o.Volume, NewVolume: v})
+		}
+	}
+	for _, o := range b {
+		if !seen[o.Price] && o.Volume != 0 {
+			seen[o.Price] = true
+			changes = append(changes, LevelChange{Side: side, Price: o.Price, NewVolume: o.Volume})
+		}
+	}
+	return changes
+}
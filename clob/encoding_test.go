@@ -0,0 +1,109 @@
+package clob_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/allank/chartea/clob"
+	"github.com/allank/chartea/depth"
+	"github.com/allank/chartea/panel"
+)
+
+// encodingBook is a book with exact prices, as from an exchange.
+func encodingBook(t *testing.T) clob.OrderBook {
+	t.Helper()
+	bid, err := clob.NewOrder("99.95", "1.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return clob.OrderBook{Bids: []clob.Order{bid}, Asks: []clob.Order{{Price: 100.05, Volume: 2, Count: 3}}}
+}
+
+// TestSnapshotRoundTrip checks that a Snapshot decodes to the book it was
+// encoded from, in JSON and gob.
+func TestSnapshotRoundTrip(t *testing.T) {
+	book := encodingBook(t)
+
+	data, err := json.Marshal(clob.Snapshot(book))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fromJSON clob.Snapshot
+	if err := json.Unmarshal(data, &fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(clob.OrderBook(fromJSON), book) {
+		t.Errorf("JSON: got %+v, want %+v", fromJSON, book)
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(clob.Snapshot(book)); err != nil {
+		t.Fatal(err)
+	}
+	var fromGob clob.Snapshot
+	if err := gob.NewDecoder(&buf).Decode(&fromGob); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(clob.OrderBook(fromGob), book) {
+		t.Errorf("gob: got %+v, want %+v", fromGob, book)
+	}
+
+	var newer clob.Snapshot
+	if err := json.Unmarshal([]byte(`{"version":99,"bids":[],"asks":[]}`), &newer); !errors.Is(err, clob.ErrUnsupportedVersion) {
+		t.Errorf("newer version: got %v, want ErrUnsupportedVersion", err)
+	}
+}
+
+// TestEmbeddedBookRoundTrip checks that models embedding OrderBook encode
+// their own settings along with the book, rather than just the book.
+func TestEmbeddedBookRoundTrip(t *testing.T) {
+	book := encodingBook(t)
+
+	chart := depth.Model{OrderBook: book, Levels: 7, PricePrecision: 3}
+	data, err := json.Marshal(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotChart depth.Model
+	if err := json.Unmarshal(data, &gotChart); err != nil {
+		t.Fatal(err)
+	}
+	if gotChart.Levels != 7 || gotChart.PricePrecision != 3 || !reflect.DeepEqual(gotChart.OrderBook, book) {
+		t.Errorf("depth.Model: got %s", data)
+	}
+
+	pane := panel.BookDepth{OrderBook: book, Ratio: 0.4, Spacing: 2}
+	data, err = json.Marshal(pane)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotPane panel.BookDepth
+	if err := json.Unmarshal(data, &gotPane); err != nil {
+		t.Fatal(err)
+	}
+	if gotPane.Ratio != 0.4 || gotPane.Spacing != 2 || !reflect.DeepEqual(gotPane.OrderBook, book) {
+		t.Errorf("panel.BookDepth: got Ratio %v, Spacing %v, book %+v", gotPane.Ratio, gotPane.Spacing, gotPane.OrderBook)
+	}
+
+	// Gob can't encode the models' styles, so check a type of its own
+	// embedding the book.
+	type snapshot struct {
+		clob.OrderBook
+		Market string
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(snapshot{book, "BTC-USD"}); err != nil {
+		t.Fatal(err)
+	}
+	var gotSnapshot snapshot
+	if err := gob.NewDecoder(&buf).Decode(&gotSnapshot); err != nil {
+		t.Fatal(err)
+	}
+	if gotSnapshot.Market != "BTC-USD" || !reflect.DeepEqual(gotSnapshot.OrderBook, book) {
+		t.Errorf("gob: got %+v", gotSnapshot)
+	}
+}
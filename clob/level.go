@@ -0,0 +1,87 @@
+package clob
+
+// Level is a price level of the book from your own data, e.g. an internal
+// order struct, so it can be rendered without copying it into an Order.
+type Level interface {
+	Price() float64
+	Volume() float64
+}
+
+// TextLevel is a Level that can also give its price and volume exactly, as
+// in Order's PriceText and VolumeText. Implementing it is optional.
+type TextLevel interface {
+	Level
+	PriceText() string
+	VolumeText() string
+}
+
+// CountLevel is a Level that can also give the number of orders at the level,
+// as in Order's Count. Implementing it is optional.
+type CountLevel interface {
+	Level
+	Count() int
+}
+
+// Levels is a side of the book made of Levels.
+type Levels interface {
+	Len() int
+	At(i int) Level
+}
+
+// LevelsOf adapts a slice of any type of Level to Levels, without copying it.
+func LevelsOf[T Level](levels []T) Levels {
+	return levelSlice[T](levels)
+}
+
+// levelSlice is a slice of Levels.
+type levelSlice[T Level] []T
+
+func (s levelSlice[T]) Len() int {
+	return len(s)
+}
+
+func (s levelSlice[T]) At(i int) Level {
+	return s[i]
+}
+
+// loadLevels loads the BidLevels and AskLevels, when set, into the book. The
+// orders are kept in buffers reused from one render to the next. Only the best
+// limit levels of a SortedLevels are loaded, or all of them when limit is zero
+// or less, and the sides they load are marked as sorted.
+func (m *Model) loadLevels(limit int) {
+	m.bidsSorted, m.asksSorted = false, false
+	if m.BidLevels != nil {
+		m.bidBuffer, m.bidsSorted = appendLevels(m.bidBuffer[:0], m.BidLevels, Bid, limit)
+		m.Bids = m.bidBuffer
+	}
+	if m.AskLevels != nil {
+		m.askBuffer, m.asksSorted = appendLevels(m.askBuffer[:0], m.AskLevels, Ask, limit)
+		m.Asks = m.askBuffer
+	}
+}
+
+// appendLevels appends levels of a side to orders, and reports whether they
+// were appended best first. Only the best limit levels of a SortedLevels of
+// the side are appended, or all of them when limit is zero or less.
+func appendLevels(orders []Order, levels Levels, side Side, limit int) ([]Order, bool) {
+	if s, ok := levels.(*SortedLevels); ok {
+		// Its orders are copied whole, keeping their times. Levels of the
+		// other side are sorted worst first, so all of them are needed.
+		if s.Side() != side {
+			return s.AppendOrders(orders, 0), false
+		}
+		return s.AppendOrders(orders, limit), true
+	}
+	for i := range levels.Len() {
+		l := levels.At(i)
+		o := Order{Price: l.Price(), Volume: l.Volume()}
+		if t, ok := l.(TextLevel); ok {
+			o.PriceText, o.VolumeText = t.PriceText(), t.VolumeText()
+		}
+		if c, ok := l.(CountLevel); ok {
+			o.Count = c.Count()
+		}
+		orders = append(orders, o)
+	}
+	return orders, false
+}
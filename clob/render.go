@@ -0,0 +1,222 @@
+package clob
+
+import (
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/termenv"
+)
+
+// maxTemplates is the number of style templates kept. A book uses a handful,
+// so more only builds up when its styles keep changing.
+const maxTemplates = 32
+
+// rowBuffers holds the buffers the book is built in, kept from frame to frame
+// so a steady stream of renders reuses the memory of the last rather than
+// allocating its rows again.
+//
+// Rows are styled with templates of the escape sequences of their styles,
+// worked out once with lipgloss and reused until a style changes, rather
+// than rendering each part of each row with lipgloss. The lines of the book
+// are then laid out in the pane as lipgloss.Place would, straight into the
+// frame, so a frame allocates little more than the string it returns.
+type rowBuffers struct {
+	price, volume, text []byte
+	spread              []byte
+	bids, asks          sideRows
+	frame               []byte
+
+	templates []template
+	profile   termenv.Profile
+	dark      bool
+	// rendered is set when a style couldn't be templated, and a row was
+	// rendered with lipgloss instead, so it may not fit on a line.
+	rendered bool
+
+	// left and right pad each line of the frame, blank is the width of an
+	// empty line, and lines counts the lines written.
+	left, right, blank, lines int
+	bottom                    int
+}
+
+// sideRows holds the rows of a side of the book, separated by newlines, and
+// where each row ends.
+type sideRows struct {
+	b    []byte
+	ends []int
+}
+
+// row returns the ith row.
+func (r *sideRows) row(i int) []byte {
+	start := 0
+	if i > 0 {
+		start = r.ends[i-1] + 1
+	}
+	return r.b[start:r.ends[i]]
+}
+
+// String returns the rows joined by newlines.
+func (r *sideRows) String() string {
+	return string(r.b)
+}
+
+// styleKey is what the escape sequences of a style depend on.
+type styleKey struct {
+	fg, bg  lipgloss.TerminalColor
+	attrs   [7]bool
+	frame   [2]int
+	profile termenv.Profile
+	dark    bool
+}
+
+// template is the escape sequences a style wraps a line of text in. Styles
+// that do more than that, e.g. underlining only the words or padding the
+// text, can't be templated.
+type template struct {
+	key         styleKey
+	open, close string
+	ok          bool
+}
+
+// startFrame resets the buffers for a frame, taking the colour profile and
+// background the styles render with.
+func (r *rowBuffers) startFrame() {
+	r.profile, r.dark = lipgloss.ColorProfile(), lipgloss.HasDarkBackground()
+	r.rendered = false
+}
+
+// template returns the template of a style, working it out the first time
+// the style is used.
+func (r *rowBuffers) template(s lipgloss.Style) template {
+	key := styleKey{
+		fg: s.GetForeground(),
+		bg: s.GetBackground(),
+		attrs: [7]bool{
+			s.GetBold(), s.GetItalic(), s.GetUnderline(), s.GetStrikethrough(),
+			s.GetReverse(), s.GetBlink(), s.GetFaint(),
+		},
+		frame:   [2]int{s.GetHorizontalFrameSize(), s.GetVerticalFrameSize()},
+		profile: r.profile,
+		dark:    r.dark,
+	}
+	for _, t := range r.templates {
+		if t.key == key {
+			return t
+		}
+	}
+	t := template{key: key}
+	const probe = "x x"
+	if out := s.UnsetWidth().Render(probe); ansi.StringWidth(out) == len(probe) {
+		if i := strings.Index(out, probe); i >= 0 {
+			t.open, t.close, t.ok = out[:i], out[i+len(probe):], true
+		}
+	}
+	if len(r.templates) == maxTemplates {
+		r.templates = r.templates[:0]
+	}
+	r.templates = append(r.templates, t)
+	return t
+}
+
+// appendStyled appends text rendered in a style, from its template when it
+// has one.
+func (r *rowBuffers) appendStyled(b []byte, s lipgloss.Style, text []byte) []byte {
+	t := r.template(s)
+	if !t.ok {
+		r.rendered = true
+		return append(b, s.Render(string(text))...)
+	}
+	b = append(b, t.open...)
+	b = append(b, text...)
+	return append(b, t.close...)
+}
+
+// renderRows renders the levels of a side of the book into rows, a row each,
+// with the price first or last, the count on the same edge as the price, and
+// the volume bar at the start or end of the row.
+func (m *Model) renderRows(rows *sideRows, orders []Order, side Side, width int, maxVolume float64, d detail, priceFirst, barFirst bool) {
+	buf := &m.rows
+	rows.b, rows.ends = rows.b[:0], rows.ends[:0]
+	for i, o := range orders {
+		buf.price = m.appendPrice(buf.price[:0], o)
+		buf.volume = m.appendVolumeColumn(buf.volume[:0], o, priceFirst)
+		buf.text = appendRowText(buf.text[:0], buf.price, buf.volume, width, priceFirst, d)
+
+		on, off := m.levelStyles(o, side)
+		onLen := barLength(o.Volume, maxVolume, width)
+		offLen := width - onLen
+
+		if i > 0 {
+			rows.b = append(rows.b, '\n')
+		}
+		if barFirst {
+			at := cellIndex(buf.text, onLen)
+			rows.b = buf.appendStyled(rows.b, on.Width(onLen), buf.text[:at])
+			rows.b = buf.appendStyled(rows.b, off.Width(offLen), buf.text[at:])
+		} else {
+			at := cellIndex(buf.text, offLen)
+			rows.b = buf.appendStyled(rows.b, off.Width(offLen), buf.text[:at])
+			rows.b = buf.appendStyled(rows.b, on.Width(onLen), buf.text[at:])
+		}
+		rows.ends = append(rows.ends, len(rows.b))
+	}
+}
+
+// cellIndex returns the index of the byte starting the nth cell of a row's
+// text, each of whose characters takes a cell.
+func cellIndex(text []byte, n int) int {
+	i := 0
+	for ; n > 0 && i < len(text); n-- {
+		_, size := utf8.DecodeRune(text[i:])
+		i += size
+	}
+	return i
+}
+
+// beginFrame starts laying out lines of the given width in a pane, centred as
+// lipgloss.Place centres them, writing the blank lines above them.
+func (r *rowBuffers) beginFrame(paneWidth, paneHeight, width, lines int) {
+	r.frame, r.lines = r.frame[:0], 0
+	gap := max(paneWidth-width, 0)
+	r.right = int(math.Round(float64(gap) * 0.5))
+	r.left = gap - r.right
+	r.blank = width + gap
+	vgap := max(paneHeight-lines, 0)
+	r.bottom = int(math.Round(float64(vgap) * 0.5))
+	for range vgap - r.bottom {
+		r.frame = append(appendSpaces(r.frame, r.blank), '\n')
+	}
+}
+
+// beginLine starts a line of the frame.
+func (r *rowBuffers) beginLine() {
+	if r.lines > 0 {
+		r.frame = append(r.frame, '\n')
+	}
+	r.lines++
+	r.frame = appendSpaces(r.frame, r.left)
+}
+
+// endLine ends a line of the frame.
+func (r *rowBuffers) endLine() {
+	r.frame = appendSpaces(r.frame, r.right)
+}
+
+// line writes a line of the frame.
+func (r *rowBuffers) line(text []byte) {
+	r.beginLine()
+	r.frame = append(r.frame, text...)
+	r.endLine()
+}
+
+// endFrame writes the blank lines below the lines laid out, and returns the
+// frame.
+func (r *rowBuffers) endFrame() string {
+	for range r.bottom {
+		r.frame = appendSpaces(append(r.frame, '\n'), r.blank)
+	}
+	return string(r.frame)
+}
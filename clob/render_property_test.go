@@ -0,0 +1,146 @@
+package clob
+
+import (
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// propertyRuns is the number of random books each property is checked on.
+const propertyRuns = 2000
+
+// randomModel returns a model of a random book, with random options, and a
+// random size to render it at.
+func randomModel(r *rand.Rand) (Model, ViewOptions) {
+	m := New()
+	price := 100 + r.Float64()*1000
+	for range r.IntN(30) {
+		price -= 0.01 + r.Float64()
+		m.Bids = append(m.Bids, randomOrder(r, price))
+	}
+	price += 1 + r.Float64()*1000
+	for range r.IntN(30) {
+		price += 0.01 + r.Float64()
+		m.Asks = append(m.Asks, randomOrder(r, price))
+	}
+	r.Shuffle(len(m.Bids), func(i, j int) { m.Bids[i], m.Bids[j] = m.Bids[j], m.Bids[i] })
+
+	m.Orientation = Orientation(r.IntN(2))
+	m.Alignment = Alignment(r.IntN(2))
+	m.Spacing = r.IntN(5)
+	m.PricePrecision, m.VolumePrecision = r.IntN(8), r.IntN(8)
+	m.Depth = r.IntN(4) * r.IntN(10)
+	m.ShowCount = r.IntN(2) == 0
+	m.ShowMid = r.IntN(2) == 0
+	m.ShowMicroprice = r.IntN(2) == 0
+	m.SpreadHistory = r.IntN(3) * 5
+	m.ImbalanceLevels = r.IntN(3)
+	m.Skeleton = r.IntN(2) == 0
+	m.FlagInvalid = r.IntN(2) == 0
+	if r.IntN(4) == 0 {
+		m.ImpactSide, m.ImpactSize = Side(r.IntN(2)), r.Float64()*50
+	}
+	return m, ViewOptions{Width: 1 + r.IntN(120), Height: 1 + r.IntN(60)}
+}
+
+// randomOrder returns an order at a price with a random volume, given as text
+// some of the time.
+func randomOrder(r *rand.Rand, price float64) Order {
+	o := Order{Price: price, Volume: r.Float64() * 10, Count: r.IntN(20)}
+	if r.IntN(4) == 0 {
+		o, _ = NewOrder(strconv.FormatFloat(price, 'f', 4, 64), strconv.FormatFloat(o.Volume, 'f', 3, 64))
+	}
+	return o
+}
+
+// TestViewWidthAndHeight checks that every line of a rendered book is as wide
+// as the pane, and there are no more lines than its height.
+func TestViewWidthAndHeight(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 1684))
+	for range propertyRuns {
+		m, opts := randomModel(r)
+		out := m.ViewWithOptions(opts)
+		lines := strings.Split(out, "\n")
+		if len(lines) > opts.Height {
+			t.Fatalf("%+v: got %d lines, want at most %d:\n%s", opts, len(lines), opts.Height, ansi.Strip(out))
+		}
+		for i, line := range lines {
+			if w := ansi.StringWidth(line); w != opts.Width {
+				t.Fatalf("%+v: line %d is %d wide, want %d:\n%s", opts, i, w, opts.Width, ansi.Strip(out))
+			}
+		}
+	}
+}
+
+// TestBarLengthMonotonic checks that bars never get shorter as the volume
+// rises, at a fixed largest volume and width.
+func TestBarLengthMonotonic(t *testing.T) {
+	r := rand.New(rand.NewPCG(2, 1684))
+	for range propertyRuns {
+		maxVolume := r.Float64() * 1000
+		width := r.IntN(200)
+		volumes := make([]float64, 50)
+		for i := range volumes {
+			volumes[i] = (r.Float64()*1.5 - 0.25) * maxVolume
+		}
+		volumes = append(volumes, 0, maxVolume)
+		slices.Sort(volumes)
+
+		last := 0
+		for _, v := range volumes {
+			n := barLength(v, maxVolume, width)
+			if n < last {
+				t.Fatalf("barLength(%v, %v, %d) = %d, shorter than %d for a smaller volume", v, maxVolume, width, n, last)
+			}
+			if n < 0 || n > width {
+				t.Fatalf("barLength(%v, %v, %d) = %d, outside 0 to %d", v, maxVolume, width, n, width)
+			}
+			last = n
+		}
+	}
+}
+
+// TestViewStable checks that rendering the same model twice gives the same
+// output.
+func TestViewStable(t *testing.T) {
+	r := rand.New(rand.NewPCG(3, 1684))
+	for range propertyRuns {
+		m, opts := randomModel(r)
+		m.TextMode = r.IntN(4) == 0
+		first := m.ViewWithOptions(opts)
+		if second := m.ViewWithOptions(opts); second != first {
+			t.Fatalf("%+v: renders differ:\n%s\n--\n%s", opts, first, second)
+		}
+	}
+}
+
+// TestViewSortedLevels checks that a book rendered from SortedLevels, which
+// only loads the levels the pane can show, renders as the same book in
+// slices.
+func TestViewSortedLevels(t *testing.T) {
+	r := rand.New(rand.NewPCG(4, 1706))
+	for range propertyRuns {
+		m, opts := randomModel(r)
+		m.TextMode = r.IntN(4) == 0
+		// A level of no volume can't be set.
+		m.Bids = slices.DeleteFunc(m.Bids, func(o Order) bool { return o.Volume == 0 })
+		m.Asks = slices.DeleteFunc(m.Asks, func(o Order) bool { return o.Volume == 0 })
+		want := m.ViewWithOptions(opts)
+
+		bids, asks := NewSortedLevels(Bid), NewSortedLevels(Ask)
+		for _, o := range m.Bids {
+			bids.Set(o)
+		}
+		for _, o := range m.Asks {
+			asks.Set(o)
+		}
+		m.BidLevels, m.AskLevels = bids, asks
+		if got := m.ViewWithOptions(opts); got != want {
+			t.Fatalf("%+v: renders differ:\n%s\n--\n%s", opts, ansi.Strip(want), ansi.Strip(got))
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package clob_test
+
+import (
+	"testing"
+
+	"github.com/allank/chartea/clob"
+	"github.com/allank/chartea/sim"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// maxFrameAllocs is the most allocations rendering a frame of a book may make
+// once the first frame has sized the buffers the rest reuse: the string the
+// frame is returned in.
+const maxFrameAllocs = 1
+
+// fullDepth is the number of levels on each side of a full depth book.
+const fullDepth = 100_000
+
+// renderCases are the books rendered by BenchmarkViewWithOptions and
+// TestViewWithOptionsAllocs: 40 levels on each side, in each orientation, and
+// a full depth book in SortedLevels, which should cost no more to draw.
+var renderCases = []struct {
+	name        string
+	orientation clob.Orientation
+	opts        clob.ViewOptions
+	fullDepth   bool
+}{
+	{"Vertical", clob.Vertical, clob.ViewOptions{Width: 60, Height: 81}, false},
+	{"Horizontal", clob.Horizontal, clob.ViewOptions{Width: 60, Height: 40}, false},
+	{"VerticalFullDepth", clob.Vertical, clob.ViewOptions{Width: 60, Height: 81}, true},
+	{"HorizontalFullDepth", clob.Horizontal, clob.ViewOptions{Width: 60, Height: 40}, true},
+}
+
+// newRenderModel returns a model of a 40 level book, or a full depth book in
+// SortedLevels, rendered once.
+func newRenderModel(orientation clob.Orientation, opts clob.ViewOptions, full bool) clob.Model {
+	m := clob.New()
+	m.Orientation = orientation
+	m.PricePrecision, m.VolumePrecision = sim.PriceDecimals, sim.VolumeDecimals
+	if full {
+		bids, asks := clob.NewSortedLevels(clob.Bid), clob.NewSortedLevels(clob.Ask)
+		for i := range fullDepth {
+			volume := float64(1 + i%7)
+			bids.Set(clob.Order{Price: 100 - float64(i+1)/100, Volume: volume, Count: 1})
+			asks.Set(clob.Order{Price: 100 + float64(i+1)/100, Volume: volume, Count: 1})
+		}
+		m.BidLevels, m.AskLevels = bids, asks
+	} else {
+		m.OrderBook = sim.DeterministicBook(1, 40).Snapshot()
+	}
+	m.ViewWithOptions(opts)
+	return m
+}
+
+// useColor renders with colours, as in a terminal, rather than the plain text
+// of a test's output, until the test ends.
+func useColor(tb testing.TB) {
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	tb.Cleanup(func() { lipgloss.SetColorProfile(profile) })
+}
+
+func BenchmarkViewWithOptions(b *testing.B) {
+	useColor(b)
+	for _, c := range renderCases {
+		b.Run(c.name, func(b *testing.B) {
+			m := newRenderModel(c.orientation, c.opts, c.fullDepth)
+			b.ReportAllocs()
+			for b.Loop() {
+				m.ViewWithOptions(c.opts)
+			}
+		})
+	}
+}
+
+// TestViewWithOptionsAllocs checks that redrawing a book, as a dashboard does
+// many times a second, allocates no more than maxFrameAllocs a frame.
+func TestViewWithOptionsAllocs(t *testing.T) {
+	useColor(t)
+	for _, c := range renderCases {
+		m := newRenderModel(c.orientation, c.opts, c.fullDepth)
+		allocs := testing.AllocsPerRun(100, func() {
+			m.ViewWithOptions(c.opts)
+		})
+		if allocs > maxFrameAllocs {
+			t.Errorf("%s: a frame made %v allocations, want at most %d", c.name, allocs, maxFrameAllocs)
+		}
+	}
+}
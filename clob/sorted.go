@@ -0,0 +1,389 @@
+package clob
+
+import "slices"
+
+// degree is the least number of children of a node of a SortedLevels' tree,
+// other than the root. A node holds degree-1 to maxNodeOrders orders.
+const degree = 32
+
+// maxNodeOrders is the most orders a node holds.
+const maxNodeOrders = 2*degree - 1
+
+// SortedLevels is a side of the book kept sorted by price, best first, for
+// full depth feeds of tens of thousands of levels, where keeping a slice
+// sorted costs a copy of the levels behind each one changed. The levels are
+// held in a B-tree counting the levels under each node, so setting, removing
+// and finding a level, and the ith level, take O(log n).
+//
+// SortedLevels implements Levels, so it can be shown with BidLevels or
+// AskLevels, which copy its levels straight into the book. Prices are
+// compared as by the clob, exactly when orders have a PriceText. A
+// SortedLevels isn't safe for concurrent use.
+type SortedLevels struct {
+	side Side
+	root *node
+}
+
+// node is a node of a SortedLevels' tree. An internal node has a child either
+// side of each order, holding the orders that sort between them.
+type node struct {
+	orders   []Order
+	children []*node
+	// size is the number of orders in the subtree.
+	size int
+}
+
+// NewSortedLevels creates an empty side of the book, sorting bids from the
+// highest price and asks from the lowest.
+func NewSortedLevels(side Side) *SortedLevels {
+	return &SortedLevels{side: side}
+}
+
+// Side returns the side of the book.
+func (s *SortedLevels) Side() Side {
+	return s.side
+}
+
+// Len returns the number of levels.
+func (s *SortedLevels) Len() int {
+	if s.root == nil {
+		return 0
+	}
+	return s.root.size
+}
+
+// At returns the ith level, best first.
+func (s *SortedLevels) At(i int) Level {
+	return orderLevel{s.Order(i)}
+}
+
+// Order returns the ith level, best first. It panics if i is out of range.
+func (s *SortedLevels) Order(i int) Order {
+	if i < 0 || i >= s.Len() {
+		panic("clob: SortedLevels index out of range")
+	}
+	n := s.root
+	for !n.leaf() {
+		j := 0
+		for ; i >= n.children[j].size; j++ {
+			i -= n.children[j].size
+			if i == 0 {
+				return n.orders[j]
+			}
+			i--
+		}
+		n = n.children[j]
+	}
+	return n.orders[i]
+}
+
+// Best returns the best level, and false if there are none.
+func (s *SortedLevels) Best() (Order, bool) {
+	if s.Len() == 0 {
+		return Order{}, false
+	}
+	return s.Order(0), true
+}
+
+// Get returns the level at a price, and false if there is none.
+func (s *SortedLevels) Get(price float64) (Order, bool) {
+	o := Order{Price: price}
+	for n := s.root; n != nil; {
+		i, found := slices.BinarySearchFunc(n.orders, o, s.compare)
+		if found {
+			return n.orders[i], true
+		}
+		if n.leaf() {
+			break
+		}
+		n = n.children[i]
+	}
+	return Order{}, false
+}
+
+// Set sets the level at the order's price to the order, adding the level if
+// it is new, and returns the level it replaced, if any. An order with a
+// volume of zero removes the level, as in the deltas of a feed.
+func (s *SortedLevels) Set(o Order) (old Order, replaced bool) {
+	if o.Volume == 0 {
+		return s.remove(o)
+	}
+	if s.root == nil {
+		s.root = &node{}
+	}
+	if len(s.root.orders) == maxNodeOrders {
+		root := s.root
+		s.root = &node{children: []*node{root}, size: root.size}
+		s.root.split(0)
+	}
+	return s.insert(s.root, o)
+}
+
+// Delete removes the level at a price, returning it, and false if there was
+// none.
+func (s *SortedLevels) Delete(price float64) (Order, bool) {
+	return s.remove(Order{Price: price})
+}
+
+// Clear removes every level.
+func (s *SortedLevels) Clear() {
+	s.root = nil
+}
+
+// AppendOrders appends the best n levels, best first, or every level when n
+// is zero or less.
+func (s *SortedLevels) AppendOrders(orders []Order, n int) []Order {
+	if n <= 0 || n > s.Len() {
+		n = s.Len()
+	}
+	if s.root != nil {
+		orders = s.root.appendOrders(orders, len(orders)+n)
+	}
+	return orders
+}
+
+// compare compares the prices of two orders in the order of the side.
+func (s *SortedLevels) compare(a, b Order) int {
+	if s.side == Bid {
+		return comparePrices(b, a)
+	}
+	return comparePrices(a, b)
+}
+
+// insert inserts or replaces an order in the subtree of a node that isn't
+// full.
+func (s *SortedLevels) insert(n *node, o Order) (Order, bool) {
+	i, found := slices.BinarySearchFunc(n.orders, o, s.compare)
+	if found {
+		old := n.orders[i]
+		n.orders[i] = o
+		return old, true
+	}
+	if n.leaf() {
+		n.orders = slices.Insert(n.orders, i, o)
+		n.size++
+		return Order{}, false
+	}
+	if len(n.children[i].orders) == maxNodeOrders {
+		n.split(i)
+		switch c := s.compare(o, n.orders[i]); {
+		case c == 0:
+			old := n.orders[i]
+			n.orders[i] = o
+			return old, true
+		case c > 0:
+			i++
+		}
+	}
+	old, replaced := s.insert(n.children[i], o)
+	if !replaced {
+		n.size++
+	}
+	return old, replaced
+}
+
+// removal is what to remove from a subtree.
+type removal int
+
+const (
+	removeOrder removal = iota
+	removeFirst
+	removeLast
+)
+
+// remove removes the level at an order's price from the tree.
+func (s *SortedLevels) remove(o Order) (Order, bool) {
+	if s.root == nil {
+		return Order{}, false
+	}
+	old, removed := s.removeFrom(s.root, o, removeOrder)
+	if len(s.root.orders) == 0 {
+		if s.root.leaf() {
+			s.root = nil
+		} else {
+			s.root = s.root.children[0]
+		}
+	}
+	return old, removed
+}
+
+// removeFrom removes an order, or the first or last order, from the subtree
+// of a node with more than the least orders, unless it's the root.
+func (s *SortedLevels) removeFrom(n *node, o Order, r removal) (Order, bool) {
+	var (
+		i     int
+		found bool
+	)
+	switch r {
+	case removeFirst:
+		if n.leaf() {
+			first := n.orders[0]
+			n.orders = slices.Delete(n.orders, 0, 1)
+			n.size--
+			return first, true
+		}
+	case removeLast:
+		i = len(n.orders)
+		if n.leaf() {
+			last := n.orders[i-1]
+			n.orders = slices.Delete(n.orders, i-1, i)
+			n.size--
+			return last, true
+		}
+	default:
+		i, found = slices.BinarySearchFunc(n.orders, o, s.compare)
+		if n.leaf() {
+			if !found {
+				return Order{}, false
+			}
+			old := n.orders[i]
+			n.orders = slices.Delete(n.orders, i, i+1)
+			n.size--
+			return old, true
+		}
+	}
+
+	// Make sure the child removed from keeps enough orders, then try again.
+	if len(n.children[i].orders) < degree {
+		n.grow(i)
+		return s.removeFrom(n, o, r)
+	}
+	if found {
+		// The order is replaced by the last order before it.
+		old := n.orders[i]
+		n.orders[i], _ = s.removeFrom(n.children[i], Order{}, removeLast)
+		n.size--
+		return old, true
+	}
+	old, removed := s.removeFrom(n.children[i], o, r)
+	if removed {
+		n.size--
+	}
+	return old, removed
+}
+
+// leaf reports whether the node has no children.
+func (n *node) leaf() bool {
+	return len(n.children) == 0
+}
+
+// split splits the full ith child of the node in two, moving its middle
+// order up into the node between them.
+func (n *node) split(i int) {
+	child := n.children[i]
+	middle := child.orders[degree-1]
+	right := &node{orders: slices.Clone(child.orders[degree:])}
+	if !child.leaf() {
+		right.children = slices.Clone(child.children[degree:])
+		clear(child.children[degree:])
+		child.children = child.children[:degree]
+	}
+	clear(child.orders[degree-1:])
+	child.orders = child.orders[:degree-1]
+	right.size = right.count()
+	child.size -= right.size + 1
+
+	n.orders = slices.Insert(n.orders, i, middle)
+	n.children = slices.Insert(n.children, i+1, right)
+}
+
+// grow gives the ith child of the node, which has the least orders, another
+// order, from a sibling with orders to spare or by merging it with one.
+func (n *node) grow(i int) {
+	child := n.children[i]
+	switch {
+	case i > 0 && len(n.children[i-1].orders) >= degree:
+		// Rotate the last order of the left sibling through the node.
+		left := n.children[i-1]
+		last := len(left.orders) - 1
+		child.orders = slices.Insert(child.orders, 0, n.orders[i-1])
+		n.orders[i-1] = left.orders[last]
+		left.orders = slices.Delete(left.orders, last, last+1)
+		moved := 1
+		if !left.leaf() {
+			last := len(left.children) - 1
+			moved += left.children[last].size
+			child.children = slices.Insert(child.children, 0, left.children[last])
+			left.children = slices.Delete(left.children, last, last+1)
+		}
+		left.size -= moved
+		child.size += moved
+	case i < len(n.orders) && len(n.children[i+1].orders) >= degree:
+		// Rotate the first order of the right sibling through the node.
+		right := n.children[i+1]
+		child.orders = append(child.orders, n.orders[i])
+		n.orders[i] = right.orders[0]
+		right.orders = slices.Delete(right.orders, 0, 1)
+		moved := 1
+		if !right.leaf() {
+			moved += right.children[0].size
+			child.children = append(child.children, right.children[0])
+			right.children = slices.Delete(right.children, 0, 1)
+		}
+		right.size -= moved
+		child.size += moved
+	default:
+		// Merge the child with a sibling and the order between them.
+		if i == len(n.orders) {
+			i--
+		}
+		left, right := n.children[i], n.children[i+1]
+		left.orders = append(append(left.orders, n.orders[i]), right.orders...)
+		left.children = append(left.children, right.children...)
+		left.size += 1 + right.size
+		n.orders = slices.Delete(n.orders, i, i+1)
+		n.children = slices.Delete(n.children, i+1, i+2)
+	}
+}
+
+// count counts the orders in the subtree.
+func (n *node) count() int {
+	size := len(n.orders)
+	for _, c := range n.children {
+		size += c.size
+	}
+	return size
+}
+
+// appendOrders appends the orders of the subtree in order, until there are
+// limit orders.
+func (n *node) appendOrders(orders []Order, limit int) []Order {
+	for i, c := range n.children {
+		if len(orders) >= limit {
+			return orders
+		}
+		orders = c.appendOrders(orders, limit)
+		if i < len(n.orders) && len(orders) < limit {
+			orders = append(orders, n.orders[i])
+		}
+	}
+	if n.leaf() {
+		orders = append(orders, n.orders[:min(len(n.orders), limit-len(orders))]...)
+	}
+	return orders
+}
+
+// orderLevel adapts an Order to a Level.
+type orderLevel struct {
+	o Order
+}
+
+func (l orderLevel) Price() float64 {
+	return l.o.Price
+}
+
+func (l orderLevel) Volume() float64 {
+	return l.o.Volume
+}
+
+func (l orderLevel) PriceText() string {
+	return l.o.PriceText
+}
+
+func (l orderLevel) VolumeText() string {
+	return l.o.VolumeText
+}
+
+func (l orderLevel) Count() int {
+	return l.o.Count
+}
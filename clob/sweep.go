@@ -0,0 +1,90 @@
+package clob
+
+// Sweep is the outcome of a hypothetical aggressive order walking the book
+// from the best price, for pre-trade what-if views. See OrderBook.Sweep.
+type Sweep struct {
+	// Side is the side of the order: a Bid buys from the asks and an Ask
+	// sells into the bids.
+	Side Side
+	// Size is the size of the order.
+	Size float64
+	// Fills are the levels the order takes, best first. Every level but the
+	// last is taken in full.
+	Fills []Fill
+	// Filled is the volume the book fills, and Unfilled the rest of the order
+	// when the book isn't deep enough to fill it.
+	Filled   float64
+	Unfilled float64
+}
+
+// Fill is the volume an order takes from a level.
+type Fill struct {
+	Order  Order
+	Volume float64
+}
+
+// Residual returns the volume left at a level the order takes part of.
+func (f Fill) Residual() float64 {
+	return max(f.Order.Volume-f.Volume, 0)
+}
+
+// Partial reports whether the order takes only part of the level.
+func (f Fill) Partial() bool {
+	return f.Volume < f.Order.Volume
+}
+
+// Sweep walks the book from the best price with an aggressive order for size
+// on a side, and returns the levels it takes. The sides don't need to be
+// sorted.
+func (b OrderBook) Sweep(side Side, size float64) Sweep {
+	orders, direction := b.Asks, -1
+	if side == Ask {
+		orders, direction = b.Bids, 1
+	}
+	s := Sweep{Side: side, Size: size}
+	remaining := max(size, 0)
+	for _, o := range bestLevels(orders, len(orders), direction) {
+		if remaining <= 0 {
+			break
+		}
+		if o.Volume <= 0 {
+			continue
+		}
+		volume := min(o.Volume, remaining)
+		s.Fills = append(s.Fills, Fill{Order: o, Volume: volume})
+		s.Filled += volume
+		remaining -= volume
+	}
+	s.Unfilled = remaining
+	return s
+}
+
+// AveragePrice returns the average price of the volume filled, and false if
+// nothing is filled.
+func (s Sweep) AveragePrice() (float64, bool) {
+	if s.Filled <= 0 {
+		return 0, false
+	}
+	cost := 0.0
+	for _, f := range s.Fills {
+		cost += f.Order.Price * f.Volume
+	}
+	return cost / s.Filled, true
+}
+
+// fill returns what the sweep takes from a level on a side, and false if it
+// doesn't reach the level.
+func (s Sweep) fill(o Order, side Side) (Fill, bool) {
+	if side == s.Side || len(s.Fills) == 0 {
+		return Fill{}, false
+	}
+	last := s.Fills[len(s.Fills)-1]
+	c := comparePrices(o, last.Order)
+	switch {
+	case c == 0:
+		return last, true
+	case side == Ask && c < 0, side == Bid && c > 0:
+		return Fill{Order: o, Volume: o.Volume}, true
+	}
+	return Fill{}, false
+}
@@ -0,0 +1,157 @@
+package clob
+
+import (
+	"sync"
+	"time"
+)
+
+// Side is a side of the book.
+type Side int
+
+const (
+	// Bid is the buy side of the book.
+	Bid Side = iota
+	// Ask is the sell side of the book.
+	Ask
+)
+
+// SyncBook is an order book that is safe for concurrent use, so a feed can
+// update it from its own goroutine while the model renders snapshots of it.
+// Its sides are kept sorted in SortedLevels, so setting a level takes
+// O(log n) however deep the book. The zero value is an empty book.
+type SyncBook struct {
+	mu         sync.RWMutex
+	bids, asks *SortedLevels
+
+	onLevel []func(LevelChange)
+	onTop   []func(bid, ask Order)
+}
+
+// OnLevelChange registers a function called with every change to a level, so
+// alerts and analytics can react to the changes they care about. Functions
+// are called after the book is updated, on the goroutine that updated it, and
+// may read the book but mustn't update it.
+func (s *SyncBook) OnLevelChange(fn func(LevelChange)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onLevel = append(s.onLevel, fn)
+}
+
+// OnTopOfBookChange registers a function called with the best bid and ask
+// whenever either changes in price or volume. A side that is empty is passed
+// as a zero Order. Functions are called like those of OnLevelChange.
+func (s *SyncBook) OnTopOfBookChange(fn func(bid, ask Order)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onTop = append(s.onTop, fn)
+}
+
+// Snapshot returns a copy of the book, best levels first, which the caller
+// may keep and modify.
+func (s *SyncBook) Snapshot() OrderBook {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.snapshot()
+}
+
+// Replace replaces the whole book with a copy of another, e.g. a snapshot
+// from a feed. Levels with no volume are left out.
+func (s *SyncBook) Replace(book OrderBook) {
+	s.update(func() []LevelChange {
+		changes := Diff(s.snapshot(), book)
+		s.bids.Clear()
+		s.asks.Clear()
+		for _, o := range book.Bids {
+			s.bids.Set(o)
+		}
+		for _, o := range book.Asks {
+			s.asks.Set(o)
+		}
+		return changes
+	})
+}
+
+// SetLevel sets the volume at a price on a side of the book, adding the level
+// if it is new, and stamps it with the current time. A volume of zero removes
+// the level.
+func (s *SyncBook) SetLevel(side Side, price, volume float64) {
+	now := time.Now()
+	s.update(func() []LevelChange {
+		levels := s.bids
+		if side == Ask {
+			levels = s.asks
+		}
+		o, ok := levels.Get(price)
+		if !ok {
+			o = Order{Price: price}
+		}
+		change := LevelChange{Side: side, Price: price, OldVolume: o.Volume, NewVolume: volume}
+		if ok || volume != 0 {
+			o.Volume, o.Time = volume, now
+			levels.Set(o)
+		}
+		return changedLevels(change)
+	})
+}
+
+// Clear removes every level from the book.
+func (s *SyncBook) Clear() {
+	s.update(func() []LevelChange {
+		changes := Diff(s.snapshot(), OrderBook{})
+		s.bids.Clear()
+		s.asks.Clear()
+		return changes
+	})
+}
+
+// snapshot returns a copy of the book. The lock must be held.
+func (s *SyncBook) snapshot() OrderBook {
+	if s.bids == nil {
+		return OrderBook{}
+	}
+	return OrderBook{
+		Bids: s.bids.AppendOrders(nil, 0),
+		Asks: s.asks.AppendOrders(nil, 0),
+	}
+}
+
+// update applies a mutation under the lock, then calls the registered
+// functions with the changes once the lock is released.
+func (s *SyncBook) update(mutate func() []LevelChange) {
+	s.mu.Lock()
+	if s.bids == nil {
+		s.bids, s.asks = NewSortedLevels(Bid), NewSortedLevels(Ask)
+	}
+	oldBid, _ := s.bids.Best()
+	oldAsk, _ := s.asks.Best()
+	changes := mutate()
+	bid, _ := s.bids.Best()
+	ask, _ := s.asks.Best()
+	onLevel, onTop := s.onLevel, s.onTop
+	s.mu.Unlock()
+
+	for _, change := range changes {
+		for _, fn := range onLevel {
+			fn(change)
+		}
+	}
+	if !sameLevel(bid, oldBid) || !sameLevel(ask, oldAsk) {
+		for _, fn := range onTop {
+			fn(bid, ask)
+		}
+	}
+}
+
+// sameLevel reports whether two levels have the same price and volume, so
+// changes only to their time or count don't count as a change.
+func sameLevel(a, b Order) bool {
+	return a.Price == b.Price && a.PriceText == b.PriceText && a.Volume == b.Volume
+}
+
+// changedLevels returns the change, unless the volume stayed the same.
+func changedLevels(change LevelChange) []LevelChange {
+	if change.OldVolume == change.NewVolume {
+		return nil
+	}
+	return []LevelChange{change}
+}
@@ -0,0 +1,202 @@
+// Command chartea is a terminal market data dashboard, showing the order
+// book, candles and trades of a market streamed live from an exchange.
+//
+//	go install github.com/allank/chartea/cmd/chartea@latest
+//	chartea --exchange kraken --market BTC/USD --layout book,candles,tape
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/allank/chartea/config"
+	"github.com/allank/chartea/feed"
+	"github.com/allank/chartea/feed/replay"
+	"github.com/allank/chartea/metrics"
+	"github.com/allank/chartea/theme"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	// Exchange adapters register themselves with feed.
+	_ "github.com/allank/chartea/exchange/kraken"
+)
+
+func main() {
+	// Flags override the environment, which overrides the defaults.
+	cfg, err := config.Default().FromEnv()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "chartea:", err)
+		os.Exit(1)
+	}
+	flag.StringVar(&cfg.Exchange, "exchange", cfg.Exchange, "the exchange to stream from, e.g. kraken ($"+config.EnvExchange+")")
+	flag.StringVar(&cfg.Market, "market", cfg.Market, "the market to show, e.g. BTC/USD ($"+config.EnvMarket+")")
+	flag.StringVar(&cfg.Layout, "layout", cfg.Layout, "the panes to show, left to right: book, depth, candles, tape ($"+config.EnvLayout+")")
+	flag.StringVar(&cfg.Theme, "theme", cfg.Theme, "a JSON theme file to style the panes with ($"+config.EnvTheme+")")
+	flag.DurationVar(&cfg.Refresh, "refresh", cfg.Refresh, "how often to redraw between updates, or 0 to only redraw on updates ($"+config.EnvRefresh+")")
+	flag.StringVar(&cfg.Metrics, "metrics", cfg.Metrics, "an address to serve Prometheus metrics on at /metrics, e.g. :9090 ($"+config.EnvMetrics+")")
+	flag.StringVar(&cfg.Log, "log", cfg.Log, "a file to log connects, reconnects and errors to ($"+config.EnvLog+")")
+	flag.StringVar(&cfg.Replay, "replay", cfg.Replay, "a recording to play back instead of streaming live ($"+config.EnvReplay+")")
+	flag.Parse()
+
+	if err := run(cfg); err != nil {
+		fmt.Fprintln(os.Stderr, "chartea:", err)
+		os.Exit(1)
+	}
+}
+
+// frame is how often updates are sent to the dashboard at most.
+const frame = time.Second / 30
+
+// run loads the market, then shows the dashboard until the user quits.
+func run(cfg config.Config) error {
+	layout, err := parseLayout(cfg.Layout)
+	if err != nil {
+		return err
+	}
+	var t theme.Theme
+	if cfg.Theme != "" {
+		if t, err = theme.Load(cfg.Theme); err != nil {
+			return err
+		}
+	}
+
+	req := feed.Request{Market: cfg.Market, Channels: layout.channels()}
+	if cfg.Log != "" {
+		// The dashboard owns the terminal, so logs can only go to a file.
+		f, err := os.OpenFile(cfg.Log, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		req.Logger = slog.New(slog.NewTextHandler(f, nil))
+	}
+	var collector *metrics.Collector
+	if cfg.Metrics != "" {
+		collector = metrics.New()
+		req.Metrics = collector
+		if err := serveMetrics(cfg.Metrics, collector); err != nil {
+			return err
+		}
+	}
+
+	var m model
+	if cfg.Replay != "" {
+		events, err := replay.Load(cfg.Replay)
+		if err != nil {
+			return err
+		}
+		player := replay.NewPlayer(events)
+		stream := player.Stream()
+		if stream.Market == "" {
+			stream.Market = cfg.Market
+		}
+		m = newModel("replay", stream, layout, cfg.Refresh)
+		m.player = player
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		stream, err := feed.Open(ctx, cfg.Exchange, req)
+		if err != nil {
+			return err
+		}
+		m = newModel(cfg.Exchange, stream, layout, cfg.Refresh)
+	}
+	m.metrics = collector
+	if err := m.applyTheme(t); err != nil {
+		return err
+	}
+
+	p := tea.NewProgram(m, tea.WithAltScreen())
+	// Bursts of updates are sent a frame at a time, so a slow terminal redraws
+	// the latest book rather than falling behind.
+	sources := feed.Start(context.Background(), p.Send, feed.Coalesce(m.stream, frame))
+	defer sources.Stop()
+
+	_, err = p.Run()
+	return err
+}
+
+// serveMetrics serves the collector's metrics, along with the Go runtime's,
+// on the address in the background.
+func serveMetrics(addr string, collector *metrics.Collector) error {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector, collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+	// Listen first, so a bad address is reported before the dashboard starts.
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("could not serve metrics: %w", err)
+	}
+	go http.Serve(l, mux)
+	return nil
+}
+
+// pane is a component of the dashboard.
+type pane string
+
+// Panes that can be shown.
+const (
+	paneBook    pane = "book"
+	paneDepth   pane = "depth"
+	paneCandles pane = "candles"
+	paneTape    pane = "tape"
+)
+
+// layout is the panes shown, left to right.
+type layout []pane
+
+// parseLayout parses a comma separated list of panes.
+func parseLayout(spec string) (layout, error) {
+	var l layout
+	for _, name := range strings.Split(spec, ",") {
+		p := pane(strings.TrimSpace(name))
+		switch p {
+		case paneBook, paneDepth, paneCandles, paneTape:
+			l = append(l, p)
+		case "":
+		default:
+			return nil, fmt.Errorf("unknown pane %q in layout", name)
+		}
+	}
+	if len(l) == 0 {
+		return nil, fmt.Errorf("layout %q has no panes", spec)
+	}
+	return l, nil
+}
+
+// has reports whether the layout shows a pane.
+func (l layout) has(p pane) bool {
+	for _, q := range l {
+		if q == p {
+			return true
+		}
+	}
+	return false
+}
+
+// channels returns the feed channels the layout's panes need.
+func (l layout) channels() []string {
+	var channels []string
+	if l.has(paneBook) || l.has(paneDepth) {
+		channels = append(channels, feed.ChannelBook)
+	}
+	if l.has(paneCandles) {
+		channels = append(channels, feed.ChannelCandles)
+	}
+	if l.has(paneTape) {
+		channels = append(channels, feed.ChannelTrades)
+	}
+	return channels
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/allank/chartea/candlestick"
+	"github.com/allank/chartea/clob"
+	"github.com/allank/chartea/depth"
+	"github.com/allank/chartea/feed"
+	"github.com/allank/chartea/feed/replay"
+	"github.com/allank/chartea/indicator"
+	"github.com/allank/chartea/metrics"
+	"github.com/allank/chartea/tape"
+	"github.com/allank/chartea/theme"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// model is the dashboard, showing the panes of its layout side by side above
+// a status bar.
+type model struct {
+	exchange string
+	market   string
+	stream   feed.Stream
+	layout   layout
+	refresh  time.Duration
+	width    int
+	height   int
+
+	book   clob.Model
+	depth  depth.Model
+	chart  candlestick.Model
+	tape   tape.Model
+	status string
+
+	// player, if set, is playing a recording back instead of a live stream.
+	player *replay.Player
+
+	// metrics, if set, records the time each pane takes to render.
+	metrics *metrics.Collector
+
+	stylePane      lipgloss.Style
+	styleStatusKey lipgloss.Style
+	styleStatus    lipgloss.Style
+}
+
+// newModel creates the dashboard for a market.
+func newModel(exchangeName string, stream feed.Stream, l layout, refresh time.Duration) model {
+	m := model{
+		exchange: exchangeName,
+		market:   stream.Market,
+		stream:   stream,
+		layout:   l,
+		refresh:  refresh,
+		book:     clob.New(),
+		depth:    depth.New(),
+		chart:    candlestick.New(),
+		tape:     tape.New(),
+		status:   "Connecting...",
+		stylePane: lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.AdaptiveColor{Light: "250", Dark: "238"}),
+		styleStatusKey: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.AdaptiveColor{Light: "232", Dark: "255"}),
+		styleStatus: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("240")),
+	}
+	m.book.Orientation = clob.Vertical
+	m.book.Skeleton = true
+	m.book.FadeAfter = 30 * time.Second
+	m.book.PricePrecision = stream.PriceDecimals
+	m.book.VolumePrecision = stream.VolumeDecimals
+	m.depth.PricePrecision = stream.PriceDecimals
+	m.chart.Capacity = 720
+	m.chart.Padding = 5
+	m.chart.Labels.Precision = stream.PriceDecimals
+	m.chart.ShowPriceLine = true
+	m.chart.ShowVolume = true
+	m.chart.Indicators = []indicator.Indicator{indicator.SMA(20)}
+	m.tape.PricePrecision = stream.PriceDecimals
+	m.tape.VolumePrecision = stream.VolumeDecimals
+	return m
+}
+
+// applyTheme styles the panes with a theme, by the names of their packages.
+func (m *model) applyTheme(t theme.Theme) error {
+	return errors.Join(
+		t.Apply("clob", &m.book),
+		t.Apply("depth", &m.depth),
+		t.Apply("candlestick", &m.chart),
+		t.Apply("tape", &m.tape),
+	)
+}
+
+// redrawMsg redraws the dashboard between updates.
+type redrawMsg struct{}
+
+// redraw returns the command for the next redraw, or nil if the dashboard is
+// only redrawn on updates.
+func (m *model) redraw() tea.Cmd {
+	if m.refresh <= 0 {
+		return nil
+	}
+	return tea.Tick(m.refresh, func(time.Time) tea.Msg { return redrawMsg{} })
+}
+
+// Init is the first command that is run when the program starts.
+func (m model) Init() tea.Cmd {
+	return tea.Batch(m.book.Init(), m.redraw())
+}
+
+// Update handles all incoming messages and updates the model accordingly.
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+	case tea.KeyMsg:
+		// Let the book have the keys while an impact size is typed in.
+		if m.book.EnteringImpact() {
+			var cmd tea.Cmd
+			m.book, cmd = m.book.Update(msg)
+			return m, cmd
+		}
+		switch msg.String() {
+		case "ctrl+c", "q":
+			return m, tea.Quit
+		case "i":
+			m.book.StartImpact(clob.Bid)
+		case "t":
+			m.book.TextMode = !m.book.TextMode
+		case " ", "+", "-", ".":
+			m.control(msg.String())
+		default:
+			// Let the chart toggle its indicators and volume.
+			var cmd tea.Cmd
+			m.chart, cmd = m.chart.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+	case redrawMsg:
+		return m, m.redraw()
+	case feed.BookUpdateMsg:
+		m.book.OrderBook = msg.Book
+		m.depth.OrderBook = msg.Book
+		return m, nil
+	case feed.TradeMsg:
+		m.tape.Push(msg.Trades...)
+		return m, nil
+	case feed.CandleMsg:
+		m.chart.Push(msg.Candles...)
+		return m, nil
+	case feed.ErrMsg:
+		m.status = msg.Error()
+		return m, nil
+	case feed.GapDetectedMsg:
+		// Flag the gap until the status next changes.
+		m.status = fmt.Sprintf("Missed %d %s updates", msg.Missed(), msg.Channel)
+		return m, nil
+	case feed.StateMsg:
+		switch msg.State {
+		case feed.Live:
+			m.status = "Live"
+			m.book.SetError(nil)
+		case feed.Reconnecting:
+			m.status = fmt.Sprintf("Reconnecting in %s...", msg.Delay.Round(time.Second))
+			m.book.SetError(fmt.Errorf("Connection lost, reconnecting in %s...", msg.Delay.Round(time.Second)))
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.book, cmd = m.book.Update(msg)
+	return m, cmd
+}
+
+// control controls the playback of a recording with a key: space to pause
+// and resume, + and - to speed up and slow down, and . to step while paused.
+func (m *model) control(key string) {
+	if m.player == nil {
+		return
+	}
+	switch key {
+	case " ":
+		if m.player.Paused() {
+			m.player.Resume()
+		} else {
+			m.player.Pause()
+		}
+	case "+":
+		m.player.Faster()
+	case "-":
+		m.player.Slower()
+	case ".":
+		m.player.Step()
+	}
+}
+
+// View renders the panes side by side, sharing the width, above the status bar.
+func (m model) View() string {
+	if m.width <= 0 {
+		return "Initializing..."
+	}
+	frameWidth, frameHeight := m.stylePane.GetFrameSize()
+	paneHeight := max(m.height-1-frameHeight, 0)
+
+	panes := make([]string, len(m.layout))
+	for i, p := range m.layout {
+		// Give any width left over to the last pane.
+		width := m.width / len(m.layout)
+		if i == len(m.layout)-1 {
+			width = m.width - width*(len(m.layout)-1)
+		}
+		inner := max(width-frameWidth, 0)
+		panes[i] = m.stylePane.Width(inner).Height(paneHeight).Render(m.renderPane(p, inner, paneHeight))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lipgloss.JoinHorizontal(lipgloss.Top, panes...), m.renderStatus())
+}
+
+// renderPane renders a pane of the given size, timing it when metrics are
+// recorded.
+func (m *model) renderPane(p pane, width, height int) string {
+	if m.metrics != nil {
+		return m.metrics.Time(string(p), func() string { return m.viewPane(p, width, height) })
+	}
+	return m.viewPane(p, width, height)
+}
+
+// viewPane renders a pane's component.
+func (m *model) viewPane(p pane, width, height int) string {
+	switch p {
+	case paneBook:
+		return m.book.ViewWithOptions(clob.ViewOptions{Width: width, Height: height})
+	case paneDepth:
+		return m.depth.ViewWithOptions(depth.ViewOptions{Width: width, Height: height})
+	case paneCandles:
+		return m.chart.ViewWithOptions(candlestick.ViewOptions{Width: width, Height: height})
+	case paneTape:
+		return m.tape.ViewWithOptions(tape.ViewOptions{Width: width, Height: height})
+	}
+	return ""
+}
+
+// renderStatus renders the status bar.
+func (m *model) renderStatus() string {
+	state := m.status
+	if m.player != nil {
+		state = m.playback()
+	}
+	parts := []string{
+		m.styleStatusKey.Render(m.market), " ",
+		m.styleStatus.Render(fmt.Sprintf("%s  %s  |  ", m.exchange, state)),
+	}
+	if m.player != nil {
+		parts = append(parts,
+			m.styleStatusKey.Render("space:"), m.styleStatus.Render(" pause  "),
+			m.styleStatusKey.Render("+-:"), m.styleStatus.Render(" speed  "),
+			m.styleStatusKey.Render(".:"), m.styleStatus.Render(" step  "),
+		)
+	}
+	parts = append(parts,
+		m.styleStatusKey.Render("i:"), m.styleStatus.Render(" impact  "),
+		m.styleStatusKey.Render("t:"), m.styleStatus.Render(" text mode  "),
+		m.styleStatusKey.Render("1:"), m.styleStatus.Render(" SMA  "),
+		m.styleStatusKey.Render("v:"), m.styleStatus.Render(" volume  "),
+		m.styleStatusKey.Render("←→:"), m.styleStatus.Render(" crosshair  "),
+		m.styleStatusKey.Render("m:"), m.styleStatus.Render(" measure  "),
+		m.styleStatusKey.Render("q:"), m.styleStatus.Render(" quit"),
+	)
+	return lipgloss.NewStyle().MaxWidth(m.width).Render(lipgloss.JoinHorizontal(lipgloss.Center, parts...))
+}
+
+// playback describes the playback of a recording for the status bar.
+func (m *model) playback() string {
+	played, total := m.player.Position()
+	switch {
+	case played == total:
+		return fmt.Sprintf("Ended  %d/%d", played, total)
+	case m.player.Paused():
+		return fmt.Sprintf("Paused  %d/%d", played, total)
+	}
+	return fmt.Sprintf("%gx  %d/%d", m.player.Speed(), played, total)
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Environment variables read by FromEnv.
+const (
+	EnvExchange = "CHARTEA_EXCHANGE"
+	EnvMarket   = "CHARTEA_MARKET"
+	EnvLayout   = "CHARTEA_LAYOUT"
+	EnvTheme    = "CHARTEA_THEME"
+	EnvRefresh  = "CHARTEA_REFRESH"
+	EnvMetrics  = "CHARTEA_METRICS"
+	EnvLog      = "CHARTEA_LOG"
+	EnvReplay   = "CHARTEA_REPLAY"
+)
+
+// Config is the configuration of a dashboard.
+type Config struct {
+	// Exchange is the exchange to stream from, e.g. kraken.
+	Exchange string
+	// Market is the market to show, e.g. BTC/USD.
+	Market string
+	// Layout is the panes to show, e.g. book,candles,tape.
+	Layout string
+	// Theme is the path of a theme file, or empty for the default styles.
+	Theme string
+	// Refresh is how often the dashboard is redrawn between updates, so
+	// time based styling like stale levels keeps up. When zero it is only
+	// redrawn on updates.
+	Refresh time.Duration
+	// Metrics is the address to serve Prometheus metrics on, e.g. :9090, or
+	// empty to not serve them.
+	Metrics string
+	// Log is the path of a file to log connects, reconnects and errors to,
+	// or empty to not log them.
+	Log string
+	// Replay is the path of a recording to play back instead of streaming
+	// from the exchange, or empty to stream live. See the replay package.
+	Replay string
+}
+
+// Default returns the configuration used when nothing is set.
+func Default() Config {
+	return Config{
+		Exchange: "kraken",
+		Market:   "BTC/USD",
+		Layout:   "book,candles,tape",
+		Refresh:  time.Second,
+	}
+}
+
+// FromEnv returns the configuration with the settings given by environment
+// variables overriding it. CHARTEA_REFRESH is a duration, e.g. 500ms.
+func (c Config) FromEnv() (Config, error) {
+	return c.FromLookup(os.LookupEnv)
+}
+
+// FromLookup is like FromEnv, but looks the variables up with a function, for
+// configuration from elsewhere or in tests.
+func (c Config) FromLookup(lookup func(string) (string, bool)) (Config, error) {
+	for name, field := range map[string]*string{
+		EnvExchange: &c.Exchange,
+		EnvMarket:   &c.Market,
+		EnvLayout:   &c.Layout,
+		EnvTheme:    &c.Theme,
+		EnvMetrics:  &c.Metrics,
+		EnvLog:      &c.Log,
+		EnvReplay:   &c.Replay,
+	} {
+		if v, ok := lookup(name); ok {
+			*field = v
+		}
+	}
+	if v, ok := lookup(EnvRefresh); ok {
+		refresh, err := time.ParseDuration(v)
+		if err != nil || refresh < 0 {
+			return c, fmt.Errorf("invalid %s %q: must be a duration such as 1s", EnvRefresh, v)
+		}
+		c.Refresh = refresh
+	}
+	return c, nil
+}
@@ -0,0 +1,232 @@
+package cvd
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/allank/chartea/linechart"
+	"github.com/allank/chartea/trades"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ViewOptions allows you to specify the dimensions of the CVD view.
+type ViewOptions struct {
+	Width  int
+	Height int
+}
+
+// Model represents the state of the cumulative volume delta component.
+type Model struct {
+	width  int
+	height int
+
+	// Values are the cumulative volume delta readings, oldest first.
+	Values []float64
+
+	// Interval groups trades into readings. Trades in the same interval update
+	// the latest reading, with no interval every trade adds a reading.
+	Interval time.Duration
+
+	// Capacity is the number of readings kept, older readings are dropped.
+	Capacity int
+
+	// Precision for the axis labels.
+	Precision int
+
+	// Styles
+	StylePositive lipgloss.Style
+	StyleNegative lipgloss.Style
+	StyleAxis     lipgloss.Style
+
+	total float64
+	last  time.Time
+}
+
+// New creates a new CVD model with default styles.
+func New() Model {
+	return Model{
+		Capacity:  500,
+		Precision: 2,
+		StylePositive: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("34")),
+		StyleNegative: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("124")),
+		StyleAxis: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "240", Dark: "245"}),
+	}
+}
+
+// Push adds a trade to the running delta. Buys add their volume, sells subtract
+// it, and trades with an unknown side are ignored. Trades whose volume isn't a
+// finite number are dropped, as one would spoil every later reading.
+func (m *Model) Push(t trades.Trade) {
+	if math.IsNaN(t.Volume) || math.IsInf(t.Volume, 0) {
+		return
+	}
+	m.total += delta(t)
+
+	if m.Interval > 0 && len(m.Values) > 0 && t.Time.Truncate(m.Interval).Equal(m.last) {
+		m.Values[len(m.Values)-1] = m.total
+		return
+	}
+	m.last = t.Time.Truncate(m.Interval)
+	m.Values = append(m.Values, m.total)
+	if m.Capacity > 0 && len(m.Values) > m.Capacity {
+		m.Values = m.Values[len(m.Values)-m.Capacity:]
+	}
+}
+
+// Series returns the readings as a series that can be plotted on a line chart.
+func (m *Model) Series(name string, style lipgloss.Style) linechart.Series {
+	return linechart.Series{Name: name, Data: m.Values, Style: style}
+}
+
+// Init initializes the CVD model.
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages for the CVD model.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	}
+	return m, nil
+}
+
+// View renders the oscillator, taking up the full width and height of the model.
+func (m *Model) View() string {
+	if m.width <= 0 {
+		return "Initializing..."
+	}
+	return m.ViewWithOptions(ViewOptions{Width: m.width, Height: m.height})
+}
+
+// ViewWithOptions renders the CVD as an oscillator with the given options. Each
+// reading is a column drawn up or down from the zero line, scaled so the largest
+// visible reading fills its half of the pane.
+func (m *Model) ViewWithOptions(opts ViewOptions) string {
+	if opts.Width <= 0 {
+		return "Initializing..."
+	}
+	if opts.Height <= 0 {
+		return ""
+	}
+
+	// Scale on the largest reading that could be visible so the labels can be sized.
+	values := m.Values
+	extent := largest(values)
+	labelFormat := fmt.Sprintf("%%+.%df", m.Precision)
+	labelWidth := max(len(fmt.Sprintf(labelFormat, -extent)), len(fmt.Sprintf(labelFormat, 0.0)))
+	plotWidth := opts.Width - labelWidth - 1
+	if plotWidth <= 0 {
+		return ""
+	}
+
+	// Show the most recent readings that fit, and rescale on them.
+	if len(values) > plotWidth {
+		values = values[len(values)-plotWidth:]
+		extent = largest(values)
+	}
+
+	// Each half of the pane is measured in half cells.
+	half := float64(opts.Height) / 2
+	centre := opts.Height / 2
+
+	rows := make([]string, 0, opts.Height)
+	for y := 0; y < opts.Height; y++ {
+		var sb strings.Builder
+		sb.WriteString(strings.Repeat(" ", plotWidth-len(values)))
+		for _, v := range values {
+			sb.WriteString(m.cell(v, extent, y, centre, half))
+		}
+		label := ""
+		switch y {
+		case 0:
+			label = fmt.Sprintf(labelFormat, extent)
+		case centre:
+			label = fmt.Sprintf(labelFormat, 0.0)
+		case opts.Height - 1:
+			label = fmt.Sprintf(labelFormat, -extent)
+		}
+		sb.WriteString(m.StyleAxis.Render(fmt.Sprintf(" %*s", labelWidth, label)))
+		rows = append(rows, sb.String())
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+// largest returns the size of the largest reading, leaving out readings that
+// aren't finite numbers.
+func largest(values []float64) float64 {
+	extent := 0.0
+	for _, v := range values {
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			extent = max(extent, math.Abs(v))
+		}
+	}
+	return extent
+}
+
+// cell renders row y of the column for a single reading. Readings that aren't
+// finite numbers are left blank.
+func (m *Model) cell(v, extent float64, y, centre int, half float64) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) || extent == 0 {
+		return " "
+	}
+	// Length of the bar in half cells.
+	length := int(math.Round(math.Abs(v) / extent * half * 2))
+	if v >= 0 {
+		// Bars grow up from the bottom of the row above the centre.
+		filled := (centre-1-y)*2 + 2
+		switch {
+		case y >= centre || length <= filled-2:
+			return " "
+		case length == filled-1:
+			return m.StylePositive.Render("▄")
+		default:
+			return m.StylePositive.Render("█")
+		}
+	}
+	// Bars grow down from the top of the centre row.
+	filled := (y-centre)*2 + 2
+	switch {
+	case y < centre || length <= filled-2:
+		return " "
+	case length == filled-1:
+		return m.StyleNegative.Render("▀")
+	default:
+		return m.StyleNegative.Render("█")
+	}
+}
+
+// Cumulative returns the cumulative volume delta after each of the given trades.
+func Cumulative(ts []trades.Trade) []float64 {
+	values := make([]float64, len(ts))
+	total := 0.0
+	for i, t := range ts {
+		total += delta(t)
+		values[i] = total
+	}
+	return values
+}
+
+// delta returns the signed volume of a trade, or zero when its volume isn't a
+// finite number.
+func delta(t trades.Trade) float64 {
+	if math.IsNaN(t.Volume) || math.IsInf(t.Volume, 0) {
+		return 0
+	}
+	switch t.Side {
+	case trades.Buy:
+		return t.Volume
+	case trades.Sell:
+		return -t.Volume
+	}
+	return 0
+}
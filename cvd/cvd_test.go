@@ -0,0 +1,59 @@
+package cvd
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/allank/chartea/trades"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// TestPushNotFinite checks that a trade whose volume isn't a finite number
+// is dropped, rather than spoiling every later reading.
+func TestPushNotFinite(t *testing.T) {
+	m := New()
+	now := time.Now()
+	m.Push(trades.Trade{Time: now, Side: trades.Buy, Volume: 2})
+	m.Push(trades.Trade{Time: now, Side: trades.Sell, Volume: math.NaN()})
+	m.Push(trades.Trade{Time: now, Side: trades.Buy, Volume: math.Inf(1)})
+	m.Push(trades.Trade{Time: now, Side: trades.Sell, Volume: 0.5})
+	if want := []float64{2, 1.5}; !slices.Equal(m.Values, want) {
+		t.Errorf("got readings %v, want %v", m.Values, want)
+	}
+
+	got := Cumulative([]trades.Trade{
+		{Side: trades.Buy, Volume: 2},
+		{Side: trades.Sell, Volume: math.Inf(-1)},
+		{Side: trades.Sell, Volume: 0.5},
+	})
+	if want := []float64{2, 2, 1.5}; !slices.Equal(got, want) {
+		t.Errorf("Cumulative: got %v, want %v", got, want)
+	}
+}
+
+// TestViewWidth checks that every line is as wide as the pane, whatever the
+// readings.
+func TestViewWidth(t *testing.T) {
+	for _, values := range [][]float64{
+		{1, -2, 3},
+		{0, 0},
+		{math.NaN(), 1},
+		{math.Inf(1), -1},
+		{999.996, -1},
+	} {
+		m := New()
+		m.Values = values
+		out := m.ViewWithOptions(ViewOptions{Width: 40, Height: 6})
+		for i, line := range strings.Split(out, "\n") {
+			if w := ansi.StringWidth(line); w != 40 {
+				t.Errorf("%v: line %d is %d wide, want 40:\n%s", values, i, w, ansi.Strip(out))
+			}
+			if strings.Contains(line, "NaN") || strings.Contains(line, "Inf") {
+				t.Errorf("%v: line %d shows a number that isn't finite: %q", values, i, ansi.Strip(line))
+			}
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package depth
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/allank/chartea/axis"
+	"github.com/allank/chartea/canvas/block"
+	"github.com/allank/chartea/clob"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// ViewOptions allows you to specify the dimensions of the depth chart view.
+type ViewOptions struct {
+	Width  int
+	Height int
+}
+
+// Model represents the state of the depth chart component.
+type Model struct {
+	width  int
+	height int
+
+	// OrderBook is the book to chart. The sides don't need to be sorted.
+	clob.OrderBook
+
+	// Levels limits the chart to that many of the best levels on each side.
+	// When zero every level is charted.
+	Levels int
+
+	// PricePrecision for the price labels.
+	PricePrecision int
+
+	// Styles
+	StyleBid  lipgloss.Style
+	StyleAsk  lipgloss.Style
+	StyleAxis lipgloss.Style
+}
+
+// New creates a new depth chart model with default styles.
+func New() Model {
+	return Model{
+		PricePrecision: 2,
+		StyleBid: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("34")),
+		StyleAsk: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("124")),
+		StyleAxis: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "240", Dark: "245"}),
+	}
+}
+
+// Init initializes the depth chart model.
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages for the depth chart model.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	}
+	return m, nil
+}
+
+// View renders the chart, taking up the full width and height of the model.
+func (m *Model) View() string {
+	if m.width <= 0 {
+		return "Initializing..."
+	}
+	return m.ViewWithOptions(ViewOptions{Width: m.width, Height: m.height})
+}
+
+// ViewWithOptions renders the chart with the given options. Price runs from
+// the lowest bid on the left to the highest ask on the right, and each column
+// is filled up to the cumulative volume available from the best price out to
+// the column's price: bids stepping up to the left of the spread and asks to
+// the right. The price range is labelled on the bottom row when there is room.
+func (m *Model) ViewWithOptions(opts ViewOptions) string {
+	if opts.Width <= 0 {
+		return "Initializing..."
+	}
+	if opts.Height <= 0 {
+		return ""
+	}
+
+	bids := best(m.Bids, m.Levels, func(a, b float64) bool { return a > b })
+	asks := best(m.Asks, m.Levels, func(a, b float64) bool { return a < b })
+	if len(bids) == 0 && len(asks) == 0 {
+		text := ansi.Truncate("No depth", opts.Width, "…")
+		return lipgloss.Place(opts.Width, opts.Height, lipgloss.Center, lipgloss.Center, m.StyleAxis.Render(text))
+	}
+	low, high := priceRange(bids, asks)
+	total := math.Max(cumulative(bids), cumulative(asks))
+	switch {
+	case total <= 0:
+		// Keep the volume axis sensible for a book without volume.
+		total = 1
+	case math.IsInf(total, 1):
+		// The volumes overflowed when added up.
+		total = math.MaxFloat64
+	}
+
+	plotHeight := opts.Height
+	if opts.Height >= 3 {
+		plotHeight--
+	}
+
+	// Aim for a volume tick every few rows, placing each on the row nearest its value.
+	labels := make([]string, plotHeight)
+	ticks, text := axis.Fit(axis.Linear, []float64{0, total}).TickLabels(max(plotHeight/3, 2))
+	gutter := 1
+	for i, v := range ticks {
+		y := plotHeight - 1 - int(math.Round(v/total*float64(plotHeight-1)))
+		if y >= 0 && y < plotHeight && labels[y] == "" {
+			labels[y] = text[i]
+			gutter = max(gutter, len(text[i])+1)
+		}
+	}
+	plotWidth := opts.Width - gutter
+	if plotWidth <= 0 {
+		return ""
+	}
+
+	// Work out the cumulative volume reached within each column, in eighths
+	// of a cell, so the levels at the edges of the range are shown.
+	heights := make([]int, plotWidth)
+	isBid := make([]bool, plotWidth)
+	for x := range heights {
+		left := low + (high-low)*float64(x)/float64(plotWidth)
+		right := low + (high-low)*float64(x+1)/float64(plotWidth)
+		volume := 0.0
+		if len(bids) > 0 && left <= bids[0].Price {
+			isBid[x] = true
+			for _, o := range bids {
+				if o.Price >= left {
+					volume += o.Volume
+				}
+			}
+		} else if len(asks) > 0 && right >= asks[0].Price {
+			for _, o := range asks {
+				if o.Price <= right {
+					volume += o.Volume
+				}
+			}
+		}
+		if volume > 0 {
+			// Always show at least a sliver so thin levels are visible.
+			heights[x] = max(int(math.Round(volume/total*float64(plotHeight*8))), 1)
+		}
+	}
+
+	rows := make([]string, 0, opts.Height)
+	for y := 0; y < plotHeight; y++ {
+		floor := block.Floor(y, plotHeight)
+		var sb strings.Builder
+		for x := 0; x < plotWidth; {
+			// Render runs of columns on the same side together.
+			end := x + 1
+			for end < plotWidth && isBid[end] == isBid[x] {
+				end++
+			}
+			var run strings.Builder
+			for _, h := range heights[x:end] {
+				run.WriteRune(block.Eighth(h - floor))
+			}
+			style := m.StyleAsk
+			if isBid[x] {
+				style = m.StyleBid
+			}
+			sb.WriteString(style.Render(run.String()))
+			x = end
+		}
+		rows = append(rows, sb.String()+m.StyleAxis.Render(fmt.Sprintf(" %-*s", gutter-1, labels[y])))
+	}
+	if plotHeight < opts.Height {
+		rows = append(rows, m.StyleAxis.Render(m.priceAxis(low, high, plotWidth)+strings.Repeat(" ", gutter)))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+// priceAxis labels the lowest, middle and highest prices of the chart, as
+// many as fit.
+func (m *Model) priceAxis(low, high float64, width int) string {
+	format := func(p float64) string { return strconv.FormatFloat(p, 'f', m.PricePrecision, 64) }
+	left, mid, right := format(low), format((low+high)/2), format(high)
+	switch {
+	case len(left)+len(mid)+len(right)+2 <= width:
+		gap := width - len(left) - len(mid) - len(right)
+		return left + strings.Repeat(" ", gap/2) + mid + strings.Repeat(" ", gap-gap/2) + right
+	case len(left)+len(right)+1 <= width:
+		return left + strings.Repeat(" ", width-len(left)-len(right)) + right
+	}
+	return strings.Repeat(" ", width)
+}
+
+// best returns up to n of the best orders on a side, best first, without
+// reordering the book. When n is zero every order is returned. Orders that
+// can't be charted, with a price or volume that isn't a finite number or a
+// negative volume, are left out.
+func best(orders []clob.Order, n int, better func(a, b float64) bool) []clob.Order {
+	sorted := make([]clob.Order, 0, len(orders))
+	for _, o := range orders {
+		if !math.IsNaN(o.Price) && !math.IsInf(o.Price, 0) && o.Volume >= 0 && !math.IsInf(o.Volume, 1) {
+			sorted = append(sorted, o)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return better(sorted[i].Price, sorted[j].Price)
+	})
+	if n > 0 && len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+// priceRange returns the lowest and highest prices to chart, from the
+// furthest bid to the furthest ask.
+func priceRange(bids, asks []clob.Order) (float64, float64) {
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, orders := range [][]clob.Order{bids, asks} {
+		for _, o := range orders {
+			low = math.Min(low, o.Price)
+			high = math.Max(high, o.Price)
+		}
+	}
+	return low, high
+}
+
+// cumulative returns the total volume of the orders.
+func cumulative(orders []clob.Order) float64 {
+	total := 0.0
+	for _, o := range orders {
+		total += o.Volume
+	}
+	return total
+}
@@ -0,0 +1,185 @@
+package donut
+
+import (
+	"math"
+
+	"github.com/allank/chartea/canvas/block"
+	"github.com/allank/chartea/legend"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// palette is used for slices that don't set their own color.
+var palette = []lipgloss.Color{"34", "214", "33", "124", "129", "37", "178", "160"}
+
+// ViewOptions allows you to specify the dimensions of the donut view.
+type ViewOptions struct {
+	Width  int
+	Height int
+}
+
+// Slice is a single part of the breakdown.
+type Slice struct {
+	Label string
+	Value float64
+	// Color of the slice. When nil a color is picked from the default palette.
+	Color lipgloss.TerminalColor
+}
+
+// Model represents the state of the donut chart component.
+type Model struct {
+	width  int
+	height int
+
+	// Slices are drawn clockwise from the top, in order.
+	Slices []Slice
+
+	// Hole is the size of the hole as a fraction of the radius. Zero draws a pie chart.
+	Hole float64
+
+	// ShowLegend lists the slices and their percentages next to the chart.
+	ShowLegend bool
+	// Legend holds the styles of the legend, its entries are filled in from the slices when rendering.
+	Legend legend.Model
+}
+
+// New creates a new donut chart model with a legend.
+func New() Model {
+	l := legend.New()
+	l.Precision = 1
+	l.Suffix = "%"
+	return Model{
+		Hole:       0.5,
+		ShowLegend: true,
+		Legend:     l,
+	}
+}
+
+// Init initializes the donut chart model.
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages for the donut chart model.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	}
+	return m, nil
+}
+
+// View renders the chart, taking up the full width and height of the model.
+func (m *Model) View() string {
+	if m.width <= 0 {
+		return "Initializing..."
+	}
+	return m.ViewWithOptions(ViewOptions{Width: m.width, Height: m.height})
+}
+
+// ViewWithOptions renders the chart with the given options. The legend is
+// placed to the right of the chart, or below it if there isn't room.
+func (m *Model) ViewWithOptions(opts ViewOptions) string {
+	if opts.Width <= 0 {
+		return "Initializing..."
+	}
+	if opts.Height <= 0 {
+		return ""
+	}
+
+	total := 0.0
+	for _, s := range m.Slices {
+		total += math.Max(s.Value, 0)
+	}
+	if total <= 0 {
+		return ""
+	}
+
+	legendView := ""
+	if m.ShowLegend {
+		l := m.Legend
+		l.Entries = make([]legend.Entry, 0, len(m.Slices))
+		for i, s := range m.Slices {
+			style := lipgloss.NewStyle().Foreground(m.color(i))
+			l.Entries = append(l.Entries, legend.Entry{Label: s.Label, Style: style, Value: math.Max(s.Value, 0) / total * 100})
+		}
+		legendView = l.View()
+	}
+
+	// Each cell holds two pixels stacked vertically, which are roughly square,
+	// so the chart is twice as wide in cells as it is high.
+	size := min(opts.Height, opts.Width/2)
+	legendBeside := legendView != "" && opts.Width-size*2 >= lipgloss.Width(legendView)
+	if legendView != "" && !legendBeside {
+		size = min(opts.Width/2, opts.Height-lipgloss.Height(legendView))
+	}
+	if size <= 0 {
+		return legendView
+	}
+
+	chart := m.renderDonut(size, total)
+	switch {
+	case legendView == "":
+		return chart
+	case legendBeside:
+		return lipgloss.JoinHorizontal(lipgloss.Center, chart, legendView)
+	default:
+		return lipgloss.JoinVertical(lipgloss.Center, chart, legendView)
+	}
+}
+
+// renderDonut draws the donut in a square of pixels, size cells high and twice as wide.
+func (m *Model) renderDonut(size int, total float64) string {
+	w, h := size*2, size*2
+	radius := float64(h) / 2
+	hole := radius * math.Max(0, math.Min(m.Hole, 1))
+
+	// The cumulative fraction of the whole at the end of each slice.
+	ends := make([]float64, len(m.Slices))
+	sum := 0.0
+	for i, s := range m.Slices {
+		sum += math.Max(s.Value, 0)
+		ends[i] = sum / total
+	}
+
+	// pixel returns the slice covering the pixel, or -1 if it's outside the donut.
+	pixel := func(px, py int) int {
+		dx := (float64(px)+0.5)/float64(w)*float64(h) - radius
+		dy := float64(py) + 0.5 - radius
+		d := math.Hypot(dx, dy)
+		if d > radius || d < hole {
+			return -1
+		}
+		// Fraction of the way round clockwise from the top.
+		angle := math.Atan2(dx, -dy) / (2 * math.Pi)
+		if angle < 0 {
+			angle++
+		}
+		for i, end := range ends {
+			if angle < end {
+				return i
+			}
+		}
+		return len(ends) - 1
+	}
+
+	c := block.New(w, size, block.Half)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if i := pixel(x, y); i >= 0 {
+				c.SetPixel(x, y, m.color(i))
+			}
+		}
+	}
+	return c.Render()
+}
+
+// color returns the color of a slice.
+func (m *Model) color(i int) lipgloss.TerminalColor {
+	if c := m.Slices[i].Color; c != nil {
+		return c
+	}
+	return palette[i%len(palette)]
+}
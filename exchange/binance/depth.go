@@ -0,0 +1,199 @@
+package binance
+
+import (
+	"errors"
+	"time"
+
+	"github.com/allank/chartea/clob"
+	"github.com/allank/chartea/feed"
+)
+
+// maxBuffered is the number of updates buffered while waiting for a
+// snapshot. Older updates are dropped beyond it, so a snapshot that never
+// arrives doesn't grow the buffer forever.
+const maxBuffered = 10000
+
+var (
+	// ErrStaleSnapshot is returned by DepthSync.Snapshot for a snapshot older
+	// than the updates buffered, which can't be joined up with them. Another
+	// snapshot should be fetched.
+	ErrStaleSnapshot = errors.New("snapshot is older than the buffered updates")
+)
+
+// ChannelDepth is the channel of the diff. depth stream named in the
+// feed.GapErrors of a DepthSync.
+const ChannelDepth = "depth"
+
+// DepthUpdate is an event of Binance's diff. depth stream, <symbol>@depth.
+// Levels are [price, quantity] pairs in decimal text, and a quantity of zero
+// removes the level.
+type DepthUpdate struct {
+	EventTime     int64       `json:"E"`
+	Symbol        string      `json:"s"`
+	FirstUpdateID int64       `json:"U"`
+	FinalUpdateID int64       `json:"u"`
+	Bids          [][2]string `json:"b"`
+	Asks          [][2]string `json:"a"`
+}
+
+// DepthSnapshot is a snapshot of a book from Binance's REST API,
+// GET /api/v3/depth.
+type DepthSnapshot struct {
+	LastUpdateID int64       `json:"lastUpdateId"`
+	Bids         [][2]string `json:"bids"`
+	Asks         [][2]string `json:"asks"`
+}
+
+// DepthSync keeps a book up to date from Binance's diff. depth stream,
+// joining the stream up with a snapshot as Binance describes:
+//
+//  1. Updates from the stream are passed to Update, which buffers them until
+//     the book is synced.
+//  2. A snapshot is fetched and passed to Snapshot. If it's older than the
+//     first update buffered it's rejected with ErrStaleSnapshot, and another
+//     should be fetched.
+//  3. The buffered updates up to the snapshot's lastUpdateId are dropped, and
+//     the rest applied on top of it.
+//  4. From then on each update must start where the last one ended, or
+//     Update returns a *feed.GapError and goes back to buffering until the
+//     next snapshot.
+//
+// NeedsSnapshot reports when a snapshot is needed. A DepthSync isn't safe
+// for concurrent use.
+type DepthSync struct {
+	// bids and asks are kept sorted, so updates to full depth books stay
+	// cheap.
+	bids, asks *clob.SortedLevels
+	// lastID is the ID of the last update applied, or of the snapshot.
+	lastID   int64
+	synced   bool
+	buffered []DepthUpdate
+}
+
+// NewDepthSync creates a DepthSync waiting for its first snapshot.
+func NewDepthSync() *DepthSync {
+	return &DepthSync{bids: clob.NewSortedLevels(clob.Bid), asks: clob.NewSortedLevels(clob.Ask)}
+}
+
+// Synced reports whether the book has been synced with a snapshot, and every
+// update since has been applied.
+func (s *DepthSync) Synced() bool {
+	return s.synced
+}
+
+// NeedsSnapshot reports whether updates are being buffered for a snapshot.
+// Binance's snapshot must be fetched after the first update is buffered, so
+// it's only needed once there's one.
+func (s *DepthSync) NeedsSnapshot() bool {
+	return !s.synced && len(s.buffered) > 0
+}
+
+// Update applies an update from the stream, or buffers it until the book is
+// synced. Updates the book already includes are ignored. An update that
+// doesn't follow on from the last returns a *feed.GapError, with Got the
+// first ID of the update, and the book is no longer synced until the next
+// snapshot.
+func (s *DepthSync) Update(u DepthUpdate) error {
+	if !s.synced {
+		s.buffered = append(s.buffered, u)
+		if len(s.buffered) > maxBuffered {
+			s.buffered = s.buffered[len(s.buffered)-maxBuffered:]
+		}
+		return nil
+	}
+	return s.apply(u)
+}
+
+// apply applies an update to the synced book.
+func (s *DepthSync) apply(u DepthUpdate) error {
+	if u.FinalUpdateID <= s.lastID {
+		return nil
+	}
+	if u.FirstUpdateID > s.lastID+1 {
+		err := &feed.GapError{Channel: ChannelDepth, Market: u.Symbol, Expected: s.lastID + 1, Got: u.FirstUpdateID}
+		s.Reset()
+		s.buffered = append(s.buffered, u)
+		return err
+	}
+	var t time.Time
+	if u.EventTime > 0 {
+		t = time.UnixMilli(u.EventTime)
+	}
+	if err := applyLevels(s.bids, u.Bids, t); err != nil {
+		return err
+	}
+	if err := applyLevels(s.asks, u.Asks, t); err != nil {
+		return err
+	}
+	s.lastID = u.FinalUpdateID
+	return nil
+}
+
+// Snapshot syncs the book with a snapshot, then applies the updates buffered
+// since. A snapshot older than the first update buffered returns
+// ErrStaleSnapshot and leaves the book waiting for another. If a buffered
+// update doesn't follow on, a *feed.GapError is returned and the book waits
+// for another snapshot.
+func (s *DepthSync) Snapshot(snap DepthSnapshot) error {
+	if len(s.buffered) > 0 && snap.LastUpdateID < s.buffered[0].FirstUpdateID-1 {
+		return ErrStaleSnapshot
+	}
+	s.bids.Clear()
+	s.asks.Clear()
+	if err := applyLevels(s.bids, snap.Bids, time.Time{}); err != nil {
+		return err
+	}
+	if err := applyLevels(s.asks, snap.Asks, time.Time{}); err != nil {
+		return err
+	}
+	s.lastID, s.synced = snap.LastUpdateID, true
+
+	buffered := s.buffered
+	s.buffered = nil
+	for i, u := range buffered {
+		if err := s.apply(u); err != nil {
+			// Keep buffering from the update that didn't follow on.
+			var gap *feed.GapError
+			if errors.As(err, &gap) {
+				s.buffered = append(s.buffered, buffered[i+1:]...)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// Reset empties the book, to be synced again with a new snapshot, e.g. after
+// reconnecting to the stream.
+func (s *DepthSync) Reset() {
+	s.bids.Clear()
+	s.asks.Clear()
+	s.lastID, s.synced = 0, false
+	s.buffered = nil
+}
+
+// OrderBook returns a copy of the book for the clob component, with prices
+// and volumes shown exactly as Binance gave them. It's empty until synced.
+func (s *DepthSync) OrderBook() clob.OrderBook {
+	if !s.synced {
+		return clob.OrderBook{}
+	}
+	return clob.OrderBook{
+		Bids: s.bids.AppendOrders(nil, 0),
+		Asks: s.asks.AppendOrders(nil, 0),
+	}
+}
+
+// applyLevels inserts, replaces or removes levels on one side of the book,
+// changed at time t.
+func applyLevels(side *clob.SortedLevels, levels [][2]string, t time.Time) error {
+	for _, l := range levels {
+		o, err := clob.NewOrder(l[0], l[1])
+		if err != nil {
+			return err
+		}
+		o.Time = t
+		side.Set(o)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/allank/chartea/feed"
+)
+
+var (
+	// ErrRateLimited is returned when an exchange rejects a request for going
+	// over its rate limit.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrUnknownPair is returned when an exchange doesn't list a pair.
+	ErrUnknownPair = errors.New("unknown pair")
+	// ErrUnavailable is returned when an exchange is down or too busy to
+	// answer, e.g. during maintenance.
+	ErrUnavailable = errors.New("exchange unavailable")
+)
+
+// StatusError is returned when an exchange responds with an HTTP error status.
+type StatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return "bad status: " + e.Status
+}
+
+// Is makes a 429 match ErrRateLimited, and a 5xx ErrUnavailable.
+func (e *StatusError) Is(target error) bool {
+	switch target {
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrUnavailable:
+		return e.StatusCode >= 500
+	}
+	return false
+}
+
+// Retryable reports whether a request that failed with err may succeed if
+// tried again later.
+func Retryable(err error) bool {
+	return errors.Is(err, ErrRateLimited) || errors.Is(err, ErrUnavailable)
+}
+
+// Retry calls fn until it succeeds, fails with an error that isn't Retryable,
+// or has been retried retries times, waiting between attempts according to
+// the backoff. It returns early if the context is cancelled.
+func Retry(ctx context.Context, backoff feed.Backoff, retries int, fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || !Retryable(err) || attempt >= retries {
+			return err
+		}
+		timer := time.NewTimer(backoff.Delay(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w (gave up retrying: %w)", err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
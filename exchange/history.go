@@ -0,0 +1,49 @@
+package exchange
+
+import (
+	"context"
+	"time"
+
+	"github.com/allank/chartea/trades"
+)
+
+// TradePageFunc fetches a page of a market's trades since a time, oldest
+// first, along with the time to fetch the next page from.
+type TradePageFunc func(ctx context.Context, since time.Time) (page []trades.Trade, next time.Time, err error)
+
+// TradeHistory fetches a market's trades from since until until, a page at a
+// time, oldest first, e.g. to fill a tape or CVD chart with the trades before
+// a session starts. A zero until fetches up to the latest trade. Trades with
+// an ID no later than one already fetched are dropped, so pages can overlap.
+//
+// If fetching a page fails, the trades fetched so far are returned with the
+// error.
+func TradeHistory(ctx context.Context, fetch TradePageFunc, since, until time.Time) ([]trades.Trade, error) {
+	var history []trades.Trade
+	var lastID int64
+	for {
+		page, next, err := fetch(ctx, since)
+		if err != nil {
+			return history, err
+		}
+		// Stop once the pages run out, or stop moving forward.
+		done := len(page) == 0 || !next.After(since)
+		for _, t := range page {
+			if !until.IsZero() && !t.Time.Before(until) {
+				done = true
+				break
+			}
+			if t.ID != 0 {
+				if t.ID <= lastID {
+					continue
+				}
+				lastID = t.ID
+			}
+			history = append(history, t)
+		}
+		if done {
+			return history, nil
+		}
+		since = next
+	}
+}
@@ -0,0 +1,116 @@
+package kraken
+
+import (
+	"hash/crc32"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/allank/chartea/clob"
+)
+
+// checksumLevels is the number of levels on each side of the book covered by
+// Kraken's checksum.
+const checksumLevels = 10
+
+// bookData is a snapshot or update of a market's book on the book channel.
+type bookData struct {
+	Symbol    string    `json:"symbol"`
+	Bids      []level   `json:"bids"`
+	Asks      []level   `json:"asks"`
+	Checksum  uint32    `json:"checksum"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// level is a price level of the book. A volume of zero removes the level.
+type level struct {
+	Price  float64 `json:"price"`
+	Volume float64 `json:"qty"`
+	// Time is when the level last changed.
+	Time time.Time `json:"-"`
+}
+
+// book is a market's book, kept up to date from the book channel. Bids are
+// sorted from the highest price and asks from the lowest, so the best of each
+// comes first.
+type book struct {
+	bids, asks []level
+}
+
+// apply applies a snapshot or update made at time t, then truncates the book to depth
+// levels as levels beyond the subscribed depth are no longer updated.
+func (b *book) apply(d bookData, t time.Time, depth int) {
+	for _, l := range d.Bids {
+		l.Time = t
+		b.bids = applyLevel(b.bids, l, func(p float64) bool { return p <= l.Price })
+	}
+	for _, l := range d.Asks {
+		l.Time = t
+		b.asks = applyLevel(b.asks, l, func(p float64) bool { return p >= l.Price })
+	}
+	if depth > 0 {
+		b.bids = b.bids[:min(len(b.bids), depth)]
+		b.asks = b.asks[:min(len(b.asks), depth)]
+	}
+}
+
+// applyLevel inserts, replaces or removes a level on one side of the book.
+// atOrAfter reports whether a price sorts at or after the level's price.
+func applyLevel(levels []level, l level, atOrAfter func(price float64) bool) []level {
+	i := sort.Search(len(levels), func(i int) bool { return atOrAfter(levels[i].Price) })
+	found := i < len(levels) && levels[i].Price == l.Price
+	switch {
+	case l.Volume == 0 && found:
+		return append(levels[:i], levels[i+1:]...)
+	case l.Volume == 0:
+		return levels
+	case found:
+		levels[i] = l
+		return levels
+	}
+	levels = append(levels, level{})
+	copy(levels[i+1:], levels[i:])
+	levels[i] = l
+	return levels
+}
+
+// checksum returns Kraken's CRC32 checksum of the top of the book: the price
+// and volume of the best asks then the best bids, at the market's precision
+// without the decimal point or leading zeros.
+func (b *book) checksum(prec precision) uint32 {
+	var sb strings.Builder
+	for _, side := range [][]level{b.asks, b.bids} {
+		for _, l := range side[:min(len(side), checksumLevels)] {
+			sb.WriteString(checksumNumber(l.Price, prec.price))
+			sb.WriteString(checksumNumber(l.Volume, prec.volume))
+		}
+	}
+	return crc32.ChecksumIEEE([]byte(sb.String()))
+}
+
+// checksumNumber formats a number for the checksum.
+func checksumNumber(v float64, decimals int) string {
+	s := strconv.FormatFloat(v, 'f', decimals, 64)
+	return strings.TrimLeft(strings.Replace(s, ".", "", 1), "0")
+}
+
+// orderBook returns a copy of the book for the clob component. When the
+// market's precision is known, prices and volumes are also given as text with
+// Kraken's decimals, so they are shown exactly.
+func (b *book) orderBook(prec precision, exact bool) clob.OrderBook {
+	return clob.OrderBook{Bids: orders(b.bids, prec, exact), Asks: orders(b.asks, prec, exact)}
+}
+
+// orders converts levels to orders.
+func orders(levels []level, prec precision, exact bool) []clob.Order {
+	orders := make([]clob.Order, len(levels))
+	for i, l := range levels {
+		orders[i] = clob.Order{Price: l.Price, Volume: l.Volume, Time: l.Time}
+		if exact {
+			orders[i].PriceText = strconv.FormatFloat(l.Price, 'f', prec.price, 64)
+			orders[i].VolumeText = strconv.FormatFloat(l.Volume, 'f', prec.volume, 64)
+		}
+	}
+	return orders
+}
@@ -0,0 +1,92 @@
+package kraken
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/allank/chartea/candles"
+	"github.com/allank/chartea/exchange"
+	"github.com/allank/chartea/feed"
+	"github.com/allank/chartea/symbols"
+	"github.com/allank/chartea/trades"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func init() {
+	feed.Register("kraken", Open)
+}
+
+// channels maps the channels of a feed.Request onto Kraken's.
+var channels = map[string]string{
+	feed.ChannelBook:    ChannelBook,
+	feed.ChannelTrades:  ChannelTrade,
+	feed.ChannelCandles: ChannelOHLC,
+	feed.ChannelTicker:  ChannelTicker,
+}
+
+// Open opens a stream of a market from Kraken's websocket API, registered with
+// feed as "kraken". The book's checksums are verified. When candles or trades
+// are requested, the stream starts with the market's recent candles and latest
+// trades from the REST API, stitched onto the live stream with feed.Backfill.
+func Open(ctx context.Context, req feed.Request) (feed.Stream, error) {
+	s, err := symbols.Parse(req.Market)
+	if err != nil {
+		return feed.Stream{}, err
+	}
+	client := NewClient()
+	client.Logger = req.Logger
+	client.TracerProvider = req.TracerProvider
+	market, err := exchange.NewMetadata(time.Hour, client.Markets).Market(ctx, s)
+	if err != nil {
+		return feed.Stream{}, fmt.Errorf("could not load market %s: %w", req.Market, err)
+	}
+
+	manager, protocol := NewManager()
+	manager.Client().Metrics = req.Metrics
+	manager.Client().Logger = req.Logger
+	manager.Client().TracerProvider = req.TracerProvider
+	protocol.Logger = req.Logger
+	if req.Interval > 0 {
+		if !validInterval(req.Interval) {
+			return feed.Stream{}, fmt.Errorf("kraken has no %s candles", req.Interval)
+		}
+		protocol.Interval = req.Interval
+	}
+	symbol := market.Symbol.String()
+	protocol.SetPrecision(symbol, market.PriceDecimals, market.VolumeDecimals)
+
+	subscribe := make([]string, 0, len(req.Channels))
+	live := feed.SourceFunc(func(ctx context.Context, send func(tea.Msg)) error {
+		for _, channel := range subscribe {
+			manager.Subscribe(channel, symbol, send)
+		}
+		return manager.Run(ctx, send)
+	})
+	source := &feed.Backfill{Market: symbol, Live: live}
+	for _, c := range req.Channels {
+		channel, ok := channels[c]
+		if !ok {
+			return feed.Stream{}, fmt.Errorf("kraken has no %s channel", c)
+		}
+		subscribe = append(subscribe, channel)
+		switch channel {
+		case ChannelOHLC:
+			source.Candles = func(ctx context.Context) ([]candles.Candle, error) {
+				return client.OHLC(ctx, market.Name, protocol.Interval, time.Time{})
+			}
+		case ChannelTrade:
+			source.Trades = func(ctx context.Context) ([]trades.Trade, error) {
+				ts, _, err := client.Trades(ctx, market.Name, time.Time{})
+				return ts, err
+			}
+		}
+	}
+	return feed.Stream{
+		Source:         source,
+		Market:         symbol,
+		PriceDecimals:  market.PriceDecimals,
+		VolumeDecimals: market.VolumeDecimals,
+	}, nil
+}
@@ -0,0 +1,451 @@
+package kraken
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/allank/chartea/candles"
+	"github.com/allank/chartea/clob"
+	"github.com/allank/chartea/exchange"
+	"github.com/allank/chartea/feed"
+	"github.com/allank/chartea/symbols"
+	"github.com/allank/chartea/trades"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// RESTURL is the base URL of Kraken's public REST API.
+const RESTURL = "https://api.kraken.com/0/public"
+
+// AssetPair describes a market listed on Kraken.
+type AssetPair struct {
+	// WSName is the name used by the websocket API, e.g. XBT/USD.
+	WSName string `json:"wsname"`
+	Base   string `json:"base"`
+	Quote  string `json:"quote"`
+	// PairDecimals and LotDecimals are the decimals of the pair's prices and
+	// volumes.
+	PairDecimals int `json:"pair_decimals"`
+	LotDecimals  int `json:"lot_decimals"`
+	// TickSize is the smallest change in price.
+	TickSize float64 `json:"tick_size,string"`
+	// AssetClass is the class the pair was listed under, e.g. currency or tokenized_asset.
+	AssetClass string `json:"-"`
+}
+
+// Symbol returns the pair's symbol in the websocket API, version 2, which
+// uses the canonical names of markets, e.g. BTC/USD rather than XBT/USD.
+func (p AssetPair) Symbol() string {
+	s, err := symbols.Kraken.Parse(p.WSName)
+	if err != nil {
+		return p.WSName
+	}
+	return s.String()
+}
+
+// Client is a client for Kraken's public REST API.
+type Client struct {
+	// BaseURL is the API's base URL, RESTURL by default.
+	BaseURL string
+
+	// HTTPClient makes the requests, http.DefaultClient by default.
+	HTTPClient *http.Client
+
+	// Limiter paces requests to stay within Kraken's limits. It can be shared
+	// between clients, and a nil limiter doesn't limit.
+	Limiter *exchange.Limiter
+
+	// Retries is how many times a request is retried when Kraken is rate
+	// limiting or unavailable, waiting according to Backoff in between.
+	Retries int
+	Backoff feed.Backoff
+
+	// Logger, if set, logs failed requests.
+	Logger *slog.Logger
+
+	// TracerProvider, if set, traces every request, including retries.
+	TracerProvider trace.TracerProvider
+}
+
+// NewClient creates a client limited to Kraken's documented rate for public
+// endpoints of one request per second.
+func NewClient() *Client {
+	return &Client{
+		BaseURL:    RESTURL,
+		HTTPClient: http.DefaultClient,
+		Limiter:    exchange.NewLimiter(1, 1),
+		Retries:    3,
+		Backoff:    feed.DefaultBackoff(),
+	}
+}
+
+// AssetPairs returns the pairs listed under an asset class, keyed by their
+// REST name, e.g. XXBTZUSD. An empty class returns the currency pairs.
+func (c *Client) AssetPairs(ctx context.Context, assetClass string) (map[string]AssetPair, error) {
+	query := url.Values{}
+	if assetClass != "" {
+		query.Set("aclass_base", assetClass)
+	}
+	var pairs map[string]AssetPair
+	if err := c.get(ctx, "AssetPairs", query, &pairs); err != nil {
+		return nil, fmt.Errorf("failed to get asset pairs: %w", err)
+	}
+	for key, pair := range pairs {
+		pair.AssetClass = assetClass
+		pairs[key] = pair
+	}
+	return pairs, nil
+}
+
+// AssetClasses are the asset classes Markets loads the pairs of.
+var AssetClasses = []string{"currency", "tokenized_asset"}
+
+// Markets returns every pair listed under the AssetClasses, e.g. to cache
+// with exchange.NewMetadata.
+func (c *Client) Markets(ctx context.Context) ([]exchange.Market, error) {
+	var markets []exchange.Market
+	for _, class := range AssetClasses {
+		pairs, err := c.AssetPairs(ctx, class)
+		if err != nil {
+			return nil, err
+		}
+		for name, pair := range pairs {
+			s, err := symbols.Kraken.Parse(pair.WSName)
+			if err != nil {
+				// Pairs without a websocket name, e.g. dark pools.
+				continue
+			}
+			markets = append(markets, exchange.Market{
+				Symbol:         s,
+				Name:           name,
+				DisplayName:    pair.WSName,
+				Class:          class,
+				TickSize:       pair.TickSize,
+				LotSize:        math.Pow10(-pair.LotDecimals),
+				PriceDecimals:  pair.PairDecimals,
+				VolumeDecimals: pair.LotDecimals,
+			})
+		}
+	}
+	return markets, nil
+}
+
+// Depth returns the order book of a pair, given its REST name.
+func (c *Client) Depth(ctx context.Context, pair string, tokenized bool) (clob.OrderBook, error) {
+	query := url.Values{"pair": {pair}}
+	if tokenized {
+		query.Set("asset_class", "tokenized_asset")
+	}
+	var result map[string]struct {
+		Asks [][]any `json:"asks"`
+		Bids [][]any `json:"bids"`
+	}
+	if err := c.get(ctx, "Depth", query, &result); err != nil {
+		return clob.OrderBook{}, fmt.Errorf("failed to get order book: %w", err)
+	}
+	// The result has a single key, the pair's name.
+	for _, book := range result {
+		asks, err := parseLevels(book.Asks)
+		if err != nil {
+			return clob.OrderBook{}, err
+		}
+		bids, err := parseLevels(book.Bids)
+		if err != nil {
+			return clob.OrderBook{}, err
+		}
+		return clob.OrderBook{Bids: bids, Asks: asks}, nil
+	}
+	return clob.OrderBook{}, fmt.Errorf("order book not found in response for pair %s: %w", pair, exchange.ErrUnknownPair)
+}
+
+// OHLC returns the candles of a pair since a time, given its REST name,
+// oldest first, e.g. to fill a candlestick chart before streaming the OHLC
+// channel. The interval is one of Intervals. Kraken returns at most the last
+// 720 candles of an interval, however far back since is, and a zero since
+// returns all of them.
+func (c *Client) OHLC(ctx context.Context, pair string, interval time.Duration, since time.Time) ([]candles.Candle, error) {
+	// Kraken only answers an interval it doesn't offer with a general error.
+	if !validInterval(interval) {
+		return nil, fmt.Errorf("kraken has no %s candles", interval)
+	}
+	query := url.Values{
+		"pair":     {pair},
+		"interval": {strconv.Itoa(int(interval / time.Minute))},
+	}
+	if !since.IsZero() {
+		query.Set("since", strconv.FormatInt(since.Unix(), 10))
+	}
+	// The result holds the pair's candles under its name, and the time of the
+	// last candle under "last".
+	var result map[string]json.RawMessage
+	if err := c.get(ctx, "OHLC", query, &result); err != nil {
+		return nil, fmt.Errorf("failed to get candles: %w", err)
+	}
+	for key, raw := range result {
+		if key == "last" {
+			continue
+		}
+		// Each candle is [time, open, high, low, close, vwap, volume, count].
+		var rows [][]any
+		if err := json.Unmarshal(raw, &rows); err != nil {
+			return nil, fmt.Errorf("failed to decode candles: %w", err)
+		}
+		return parseCandles(rows)
+	}
+	return nil, fmt.Errorf("candles not found in response for pair %s: %w", pair, exchange.ErrUnknownPair)
+}
+
+// Trades returns up to 1000 trades of a pair since a time, given its REST
+// name, oldest first, along with the time to fetch the next page from. A zero
+// since returns the latest trades.
+func (c *Client) Trades(ctx context.Context, pair string, since time.Time) ([]trades.Trade, time.Time, error) {
+	query := url.Values{"pair": {pair}}
+	if !since.IsZero() {
+		// Kraken takes the time in nanoseconds, as it gives it under "last".
+		query.Set("since", strconv.FormatInt(since.UnixNano(), 10))
+	}
+	// The result holds the pair's trades under its name, and the time to
+	// fetch the next page from under "last".
+	var result map[string]json.RawMessage
+	if err := c.get(ctx, "Trades", query, &result); err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to get trades: %w", err)
+	}
+	var last string
+	if err := json.Unmarshal(result["last"], &last); err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to decode trades: %w", err)
+	}
+	ns, err := strconv.ParseInt(last, 10, 64)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("invalid trades cursor %q", last)
+	}
+	for key, raw := range result {
+		if key == "last" {
+			continue
+		}
+		// Each trade is [price, volume, time, side, type, misc, id].
+		var rows [][]any
+		if err := json.Unmarshal(raw, &rows); err != nil {
+			return nil, time.Time{}, fmt.Errorf("failed to decode trades: %w", err)
+		}
+		ts, err := parseTrades(rows)
+		return ts, time.Unix(0, ns), err
+	}
+	return nil, time.Time{}, fmt.Errorf("trades not found in response for pair %s: %w", pair, exchange.ErrUnknownPair)
+}
+
+// TradeHistory returns the trades of a pair from since until until, given its
+// REST name, oldest first. A zero until returns them up to the latest trade.
+// Each 1000 trades takes a request, paced by the client's Limiter, so a busy
+// pair's history can take a while.
+func (c *Client) TradeHistory(ctx context.Context, pair string, since, until time.Time) ([]trades.Trade, error) {
+	return exchange.TradeHistory(ctx, func(ctx context.Context, since time.Time) ([]trades.Trade, time.Time, error) {
+		return c.Trades(ctx, pair, since)
+	}, since, until)
+}
+
+// get calls a public endpoint, decoding its result into v, and retries when
+// Kraken is rate limiting or unavailable.
+func (c *Client) get(ctx context.Context, endpoint string, query url.Values, v any) error {
+	return exchange.Retry(ctx, c.Backoff, c.Retries, func() error {
+		err := c.getOnce(ctx, endpoint, query, v)
+		if err != nil && c.Logger != nil {
+			c.Logger.Warn("kraken request failed", "endpoint", endpoint, "err", err, "retryable", exchange.Retryable(err))
+		}
+		return err
+	})
+}
+
+// tracer returns the client's tracer.
+func (c *Client) tracer() trace.Tracer {
+	if c.TracerProvider == nil {
+		return noop.Tracer{}
+	}
+	return c.TracerProvider.Tracer("github.com/allank/chartea/exchange/kraken")
+}
+
+// getOnce makes a single call to a public endpoint, in a span when tracing.
+// The span covers the wait for the limiter, so it shows the full latency.
+func (c *Client) getOnce(ctx context.Context, endpoint string, query url.Values, v any) (err error) {
+	u := c.BaseURL + "/" + endpoint
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	ctx, span := c.tracer().Start(ctx, "kraken "+endpoint, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("http.request.method", http.MethodGet),
+		attribute.String("url.full", u),
+	))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	if err := c.Limiter.Wait(ctx); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+
+	if resp.StatusCode != http.StatusOK {
+		return &exchange.StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+	var body struct {
+		Error  []string        `json:"error"`
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(body.Error) > 0 {
+		return apiError(body.Error)
+	}
+	return json.Unmarshal(body.Result, v)
+}
+
+// apiError converts the errors Kraken returns into an error, matching the
+// typed errors of the exchange package where possible.
+func apiError(messages []string) error {
+	err := fmt.Errorf("kraken API error: %s", strings.Join(messages, ", "))
+	for _, msg := range messages {
+		switch {
+		case strings.HasPrefix(msg, "EQuery:Unknown asset pair"):
+			return fmt.Errorf("%w: %w", err, exchange.ErrUnknownPair)
+		case strings.HasPrefix(msg, "EAPI:Rate limit exceeded"), strings.HasPrefix(msg, "EGeneral:Too many requests"):
+			return fmt.Errorf("%w: %w", err, exchange.ErrRateLimited)
+		case strings.HasPrefix(msg, "EService:"):
+			return fmt.Errorf("%w: %w", err, exchange.ErrUnavailable)
+		}
+	}
+	return err
+}
+
+// parseLevels converts the [price, volume, timestamp] levels of a book. Prices
+// and volumes sent as text are kept exactly as Kraken formatted them.
+func parseLevels(levels [][]any) ([]clob.Order, error) {
+	orders := make([]clob.Order, 0, len(levels))
+	for _, level := range levels {
+		if len(level) < 2 {
+			return nil, fmt.Errorf("invalid order book level %v", level)
+		}
+		price, priceOK := level[0].(string)
+		volume, volumeOK := level[1].(string)
+		if priceOK && volumeOK {
+			o, err := clob.NewOrder(price, volume)
+			if err != nil {
+				return nil, err
+			}
+			orders = append(orders, o)
+			continue
+		}
+		p, err := parseNumber(level[0])
+		if err != nil {
+			return nil, err
+		}
+		v, err := parseNumber(level[1])
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, clob.Order{Price: p, Volume: v})
+	}
+	return orders, nil
+}
+
+// parseCandles converts the rows of an OHLC response.
+func parseCandles(rows [][]any) ([]candles.Candle, error) {
+	cs := make([]candles.Candle, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < 7 {
+			return nil, fmt.Errorf("invalid candle %v", row)
+		}
+		var v [7]float64
+		for i := range v {
+			n, err := parseNumber(row[i])
+			if err != nil {
+				return nil, err
+			}
+			v[i] = n
+		}
+		cs = append(cs, candles.Candle{
+			Time:   time.Unix(int64(v[0]), 0),
+			Open:   v[1],
+			High:   v[2],
+			Low:    v[3],
+			Close:  v[4],
+			Volume: v[6],
+		})
+	}
+	return cs, nil
+}
+
+// parseTrades converts the rows of a Trades response.
+func parseTrades(rows [][]any) ([]trades.Trade, error) {
+	ts := make([]trades.Trade, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < 7 {
+			return nil, fmt.Errorf("invalid trade %v", row)
+		}
+		var v [3]float64
+		for i := range v {
+			n, err := parseNumber(row[i])
+			if err != nil {
+				return nil, err
+			}
+			v[i] = n
+		}
+		id, err := parseNumber(row[6])
+		if err != nil {
+			return nil, err
+		}
+		sec, frac := math.Modf(v[2])
+		t := trades.Trade{
+			Time:   time.Unix(int64(sec), int64(frac*1e9)),
+			Price:  v[0],
+			Volume: v[1],
+			ID:     int64(id),
+		}
+		switch row[3] {
+		case "b":
+			t.Side = trades.Buy
+		case "s":
+			t.Side = trades.Sell
+		}
+		ts = append(ts, t)
+	}
+	return ts, nil
+}
+
+// parseNumber parses a number Kraken sends as a string.
+func parseNumber(v any) (float64, error) {
+	switch v := v.(type) {
+	case string:
+		return strconv.ParseFloat(v, 64)
+	case float64:
+		return v, nil
+	}
+	return 0, fmt.Errorf("invalid number %v", v)
+}
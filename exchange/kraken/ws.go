@@ -0,0 +1,408 @@
+package kraken
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/allank/chartea/candles"
+	"github.com/allank/chartea/feed"
+	"github.com/allank/chartea/feed/ws"
+	"github.com/allank/chartea/trades"
+)
+
+// WSURL is the endpoint of Kraken's public websocket API, version 2.
+const WSURL = "wss://ws.kraken.com/v2"
+
+// Channels of Kraken's websocket API.
+const (
+	ChannelBook   = "book"
+	ChannelTrade  = "trade"
+	ChannelTicker = "ticker"
+	ChannelOHLC   = "ohlc"
+)
+
+var _ ws.Protocol = (*Protocol)(nil)
+
+// Protocol speaks Kraken's websocket API for a ws.Manager. It keeps the order
+// book of each market subscribed to on the book channel, and the ID of the
+// last trade sent for each market on the trade channel.
+type Protocol struct {
+	// Depth is the number of levels on each side of the book to subscribe
+	// to: 10, 25, 100, 500 or 1000.
+	Depth int
+
+	// Interval is the interval of the candles to subscribe to on the OHLC
+	// channel, one of Intervals.
+	Interval time.Duration
+
+	// Logger, if set, logs subscriptions Kraken rejects. Messages that can't
+	// be decoded and books failing their checksum drop the connection, and
+	// are logged by the ws.Client.
+	Logger *slog.Logger
+
+	mu        sync.Mutex
+	books     map[string]*book
+	precision map[string]precision
+	// trades tracks the IDs of the trades sent for each market, which Kraken
+	// numbers in sequence.
+	trades feed.Sequences
+}
+
+// Intervals are the intervals of the candles Kraken offers, over both the
+// websocket and REST APIs: 1, 5, 15 or 30 minutes, 1 or 4 hours, 1 day,
+// 1 week or 15 days.
+var Intervals = []time.Duration{
+	time.Minute, 5 * time.Minute, 15 * time.Minute, 30 * time.Minute,
+	time.Hour, 4 * time.Hour, 24 * time.Hour, 7 * 24 * time.Hour, 15 * 24 * time.Hour,
+}
+
+// validInterval reports whether Kraken offers candles of an interval.
+func validInterval(interval time.Duration) bool {
+	return slices.Contains(Intervals, interval)
+}
+
+// precision is the number of decimals of a market's prices and volumes.
+type precision struct {
+	price, volume int
+}
+
+// NewProtocol creates a protocol subscribing to 25 levels of each book and
+// one minute candles.
+func NewProtocol() *Protocol {
+	return &Protocol{
+		Depth:     25,
+		Interval:  time.Minute,
+		books:     map[string]*book{},
+		precision: map[string]precision{},
+	}
+}
+
+// NewManager creates a manager for Kraken's websocket API, e.g.
+//
+//	manager, protocol := kraken.NewManager()
+//	manager.Subscribe(kraken.ChannelBook, "BTC/USD", program.Send)
+func NewManager() (*ws.Manager, *Protocol) {
+	p := NewProtocol()
+	return ws.NewManager("kraken", WSURL, p), p
+}
+
+// SetPrecision sets the decimals of a market's prices and volumes, given by
+// the PairDecimals and LotDecimals of its AssetPair. Kraken computes book
+// checksums from the levels at this precision, so checksums are only
+// verified for markets with a precision set.
+func (p *Protocol) SetPrecision(market string, priceDecimals, volumeDecimals int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.precision[market] = precision{priceDecimals, volumeDecimals}
+}
+
+// SubscribeMsg returns the message subscribing to the markets on a channel.
+func (p *Protocol) SubscribeMsg(channel string, markets []string) any {
+	params := map[string]any{
+		"channel": channel,
+		"symbol":  markets,
+	}
+	p.addParams(channel, params)
+	return map[string]any{
+		"method": "subscribe",
+		"params": params,
+	}
+}
+
+// UnsubscribeMsg returns the message ending the subscriptions, and forgets
+// the state kept for the markets.
+func (p *Protocol) UnsubscribeMsg(channel string, markets []string) any {
+	params := map[string]any{
+		"channel": channel,
+		"symbol":  markets,
+	}
+	p.mu.Lock()
+	for _, market := range markets {
+		switch channel {
+		case ChannelBook:
+			delete(p.books, market)
+		case ChannelTrade:
+			p.trades.Reset(ChannelTrade, market)
+		}
+	}
+	p.mu.Unlock()
+	p.addParams(channel, params)
+	return map[string]any{
+		"method": "unsubscribe",
+		"params": params,
+	}
+}
+
+// addParams adds the parameters specific to a channel.
+func (p *Protocol) addParams(channel string, params map[string]any) {
+	switch channel {
+	case ChannelBook:
+		params["depth"] = p.Depth
+	case ChannelOHLC:
+		params["interval"] = int(p.Interval / time.Minute)
+	}
+}
+
+// message is a message from the server, either the response to a request or
+// data on a channel.
+type message struct {
+	// Responses to requests.
+	Method  string `json:"method"`
+	Success bool   `json:"success"`
+	Error   string `json:"error"`
+	Symbol  string `json:"symbol"`
+	Result  struct {
+		Channel string `json:"channel"`
+		Symbol  string `json:"symbol"`
+	} `json:"result"`
+
+	// Channel data.
+	Channel string          `json:"channel"`
+	Type    string          `json:"type"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// Route decodes a message from the server into updates. A book whose checksum
+// doesn't match returns an error, so the connection is dropped and the books
+// are fetched afresh on reconnect.
+func (p *Protocol) Route(data []byte) ([]ws.Update, error) {
+	var msg message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, fmt.Errorf("failed to decode message: %w", err)
+	}
+	if msg.Method != "" {
+		return p.routeResponse(msg), nil
+	}
+	switch msg.Channel {
+	case ChannelBook:
+		return p.routeBook(msg)
+	case ChannelTrade:
+		return p.routeTrades(msg)
+	case ChannelTicker:
+		return p.routeTicker(msg)
+	case ChannelOHLC:
+		return p.routeOHLC(msg)
+	}
+	// Heartbeats and status messages.
+	return nil, nil
+}
+
+// routeResponse reports a failed subscription to its consumers.
+func (p *Protocol) routeResponse(msg message) []ws.Update {
+	if msg.Success || msg.Error == "" {
+		return nil
+	}
+	market := msg.Symbol
+	if market == "" {
+		market = msg.Result.Symbol
+	}
+	if p.Logger != nil {
+		p.Logger.Warn("kraken subscription failed", "method", msg.Method, "channel", msg.Result.Channel, "market", market, "err", msg.Error)
+	}
+	return []ws.Update{{
+		Channel: msg.Result.Channel,
+		Market:  market,
+		Msg:     feed.ErrMsg{Market: market, Err: fmt.Errorf("kraken %s failed: %s", msg.Method, msg.Error)},
+	}}
+}
+
+// routeBook applies a snapshot or update of the book channel.
+func (p *Protocol) routeBook(msg message) ([]ws.Update, error) {
+	var data []bookData
+	if err := json.Unmarshal(msg.Data, &data); err != nil {
+		return nil, fmt.Errorf("failed to decode book: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	updates := make([]ws.Update, 0, len(data))
+	for _, d := range data {
+		b := p.books[d.Symbol]
+		if msg.Type == "snapshot" || b == nil {
+			b = &book{}
+			p.books[d.Symbol] = b
+		}
+		t := d.Timestamp
+		if t.IsZero() {
+			t = time.Now()
+		}
+		b.apply(d, t, p.Depth)
+
+		prec, exact := p.precision[d.Symbol]
+		if exact {
+			if sum := b.checksum(prec); sum != d.Checksum {
+				delete(p.books, d.Symbol)
+				return nil, fmt.Errorf("%w for %s book: got %d, want %d", feed.ErrChecksum, d.Symbol, sum, d.Checksum)
+			}
+		}
+
+		updates = append(updates, ws.Update{
+			Channel: ChannelBook,
+			Market:  d.Symbol,
+			Msg:     feed.BookUpdateMsg{Market: d.Symbol, Time: t, Book: b.orderBook(prec, exact)},
+		})
+	}
+	return updates, nil
+}
+
+// tradeData is a trade on the trade channel.
+type tradeData struct {
+	Symbol    string    `json:"symbol"`
+	Side      string    `json:"side"`
+	Price     float64   `json:"price"`
+	Volume    float64   `json:"qty"`
+	ID        int64     `json:"trade_id"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// routeTrades sends the trades of each market. Subscribing sends the most
+// recent trades first, so trades already sent before a reconnect are skipped.
+// Trades are numbered in sequence, so trades missed, e.g. while reconnecting,
+// are reported with a feed.GapDetectedMsg ahead of the market's batch.
+// Kraken only resends the latest trades, so they can't be recovered.
+func (p *Protocol) routeTrades(msg message) ([]ws.Update, error) {
+	var data []tradeData
+	if err := json.Unmarshal(msg.Data, &data); err != nil {
+		return nil, fmt.Errorf("failed to decode trades: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var markets []string
+	var gaps []ws.Update
+	batches := map[string][]trades.Trade{}
+	for _, d := range data {
+		fresh, err := p.trades.Check(ChannelTrade, d.Symbol, d.ID)
+		if !fresh {
+			continue
+		}
+		var gap *feed.GapError
+		if errors.As(err, &gap) {
+			gaps = append(gaps, ws.Update{
+				Channel: ChannelTrade,
+				Market:  d.Symbol,
+				Msg:     feed.GapDetectedMsg{Source: "kraken", Channel: ChannelTrade, Market: d.Symbol, Expected: gap.Expected, Got: gap.Got},
+			})
+		}
+
+		side := trades.Unknown
+		switch d.Side {
+		case "buy":
+			side = trades.Buy
+		case "sell":
+			side = trades.Sell
+		}
+		if _, ok := batches[d.Symbol]; !ok {
+			markets = append(markets, d.Symbol)
+		}
+		batches[d.Symbol] = append(batches[d.Symbol], trades.Trade{Time: d.Timestamp, Price: d.Price, Volume: d.Volume, Side: side, ID: d.ID})
+	}
+
+	updates := make([]ws.Update, len(markets))
+	for i, market := range markets {
+		updates[i] = ws.Update{
+			Channel: ChannelTrade,
+			Market:  market,
+			Msg:     feed.TradeMsg{Market: market, Trades: batches[market]},
+		}
+	}
+	return append(gaps, updates...), nil
+}
+
+// tickerData is a market's ticker on the ticker channel.
+type tickerData struct {
+	Symbol    string  `json:"symbol"`
+	Bid       float64 `json:"bid"`
+	BidQty    float64 `json:"bid_qty"`
+	Ask       float64 `json:"ask"`
+	AskQty    float64 `json:"ask_qty"`
+	Last      float64 `json:"last"`
+	Volume    float64 `json:"volume"`
+	VWAP      float64 `json:"vwap"`
+	Low       float64 `json:"low"`
+	High      float64 `json:"high"`
+	Change    float64 `json:"change"`
+	ChangePct float64 `json:"change_pct"`
+}
+
+// routeTicker sends the ticker of each market.
+func (p *Protocol) routeTicker(msg message) ([]ws.Update, error) {
+	var data []tickerData
+	if err := json.Unmarshal(msg.Data, &data); err != nil {
+		return nil, fmt.Errorf("failed to decode ticker: %w", err)
+	}
+	// Tickers don't carry a timestamp, so use the time they arrived.
+	now := time.Now()
+	updates := make([]ws.Update, len(data))
+	for i, d := range data {
+		updates[i] = ws.Update{
+			Channel: ChannelTicker,
+			Market:  d.Symbol,
+			Msg: feed.TickerMsg{
+				Market:        d.Symbol,
+				Time:          now,
+				Bid:           d.Bid,
+				BidVolume:     d.BidQty,
+				Ask:           d.Ask,
+				AskVolume:     d.AskQty,
+				Last:          d.Last,
+				Volume:        d.Volume,
+				VWAP:          d.VWAP,
+				Low:           d.Low,
+				High:          d.High,
+				Change:        d.Change,
+				ChangePercent: d.ChangePct,
+			},
+		}
+	}
+	return updates, nil
+}
+
+// ohlcData is a candle on the OHLC channel.
+type ohlcData struct {
+	Symbol        string    `json:"symbol"`
+	Open          float64   `json:"open"`
+	High          float64   `json:"high"`
+	Low           float64   `json:"low"`
+	Close         float64   `json:"close"`
+	Volume        float64   `json:"volume"`
+	IntervalBegin time.Time `json:"interval_begin"`
+}
+
+// routeOHLC sends the candles of each market.
+func (p *Protocol) routeOHLC(msg message) ([]ws.Update, error) {
+	var data []ohlcData
+	if err := json.Unmarshal(msg.Data, &data); err != nil {
+		return nil, fmt.Errorf("failed to decode candles: %w", err)
+	}
+	var markets []string
+	batches := map[string][]candles.Candle{}
+	for _, d := range data {
+		if _, ok := batches[d.Symbol]; !ok {
+			markets = append(markets, d.Symbol)
+		}
+		batches[d.Symbol] = append(batches[d.Symbol], candles.Candle{
+			Time:   d.IntervalBegin,
+			Open:   d.Open,
+			High:   d.High,
+			Low:    d.Low,
+			Close:  d.Close,
+			Volume: d.Volume,
+		})
+	}
+
+	updates := make([]ws.Update, len(markets))
+	for i, market := range markets {
+		updates[i] = ws.Update{
+			Channel: ChannelOHLC,
+			Market:  market,
+			Msg:     feed.CandleMsg{Market: market, Candles: batches[market]},
+		}
+	}
+	return updates, nil
+}
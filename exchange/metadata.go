@@ -0,0 +1,114 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/allank/chartea/symbols"
+)
+
+// Market describes a market listed on an exchange.
+type Market struct {
+	// Symbol is the market's canonical symbol.
+	Symbol symbols.Symbol
+	// Name is the exchange's name for the market in its REST API, and
+	// DisplayName the name to show, e.g. XXBTZUSD and XBT/USD on Kraken.
+	Name        string
+	DisplayName string
+	// Class is the exchange's class of the market, e.g. currency.
+	Class string
+
+	// TickSize is the smallest change in price, and LotSize in volume.
+	TickSize float64
+	LotSize  float64
+	// PriceDecimals and VolumeDecimals are the decimals prices and volumes
+	// are quoted with.
+	PriceDecimals  int
+	VolumeDecimals int
+}
+
+// LoadFunc loads every market listed on an exchange.
+type LoadFunc func(ctx context.Context) ([]Market, error)
+
+// Metadata caches the markets listed on an exchange, loading them again once
+// they are older than the TTL, so looking up a market doesn't make a request
+// every time. It is safe for concurrent use.
+type Metadata struct {
+	// Load loads the markets.
+	Load LoadFunc
+	// TTL is how long the markets are cached for. When zero they are loaded
+	// only once, until Refresh is called.
+	TTL time.Duration
+
+	mu      sync.Mutex
+	markets map[symbols.Symbol]Market
+	loaded  time.Time
+}
+
+// NewMetadata creates a cache of the markets returned by load.
+func NewMetadata(ttl time.Duration, load LoadFunc) *Metadata {
+	return &Metadata{Load: load, TTL: ttl}
+}
+
+// Market returns a market, loading the markets if they haven't been loaded or
+// have expired. A market that isn't listed returns ErrUnknownPair.
+func (m *Metadata) Market(ctx context.Context, s symbols.Symbol) (Market, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.load(ctx, false); err != nil {
+		return Market{}, err
+	}
+	market, ok := m.markets[s]
+	if !ok {
+		return Market{}, fmt.Errorf("%s: %w", s, ErrUnknownPair)
+	}
+	return market, nil
+}
+
+// Markets returns every market, loading them if they haven't been loaded or
+// have expired.
+func (m *Metadata) Markets(ctx context.Context) ([]Market, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.load(ctx, false); err != nil {
+		return nil, err
+	}
+	markets := make([]Market, 0, len(m.markets))
+	for _, market := range m.markets {
+		markets = append(markets, market)
+	}
+	return markets, nil
+}
+
+// Refresh loads the markets again, e.g. on a schedule or when a new listing is
+// expected.
+func (m *Metadata) Refresh(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.load(ctx, true)
+}
+
+// load loads the markets when forced, or when they haven't been loaded or
+// have expired. If loading fails the markets already loaded are kept, and
+// loaded again on the next call.
+func (m *Metadata) load(ctx context.Context, force bool) error {
+	fresh := m.markets != nil && (m.TTL <= 0 || time.Since(m.loaded) < m.TTL)
+	if fresh && !force {
+		return nil
+	}
+	list, err := m.Load(ctx)
+	if err != nil {
+		if m.markets != nil && !force {
+			return nil
+		}
+		return fmt.Errorf("failed to load markets: %w", err)
+	}
+	m.markets = make(map[symbols.Symbol]Market, len(list))
+	for _, market := range list {
+		m.markets[market.Symbol] = market
+	}
+	m.loaded = time.Now()
+	return nil
+}
@@ -0,0 +1,187 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/allank/chartea/candles"
+	"github.com/allank/chartea/trades"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Backfill is a source sending a market's history, e.g. fetched over REST,
+// ahead of the messages of a live source, stitched together so charts get
+// one stream without gaps or duplicates at the boundary.
+//
+// The live source is started first, and its messages are held back while the
+// history is fetched, so nothing that happens in between is missed. Then the
+// history is sent, followed by the live messages, dropping what the history
+// already had:
+//
+//   - Candles older than the last candle of the history are dropped. Later
+//     candles, and the last one updated, are sent.
+//   - Trades with an ID no later than the last trade of the history are
+//     dropped. If the first trade sent skips IDs, a GapDetectedMsg reports
+//     the trades missed. Trades without IDs older than the last of the
+//     history are dropped, as are those at the same time equal to one in it.
+//
+// Only messages of the Market are stitched, and other messages, like books,
+// are sent as they come. If the history can't be fetched, an ErrMsg reports
+// it and the live messages are sent on their own.
+type Backfill struct {
+	// Market is the market in the messages of the live source.
+	Market string
+	// Live is the live source, e.g. an exchange's websocket stream.
+	Live Source
+
+	// Candles, if set, fetches the market's candles before the live ones,
+	// oldest first.
+	Candles func(ctx context.Context) ([]candles.Candle, error)
+	// Trades, if set, fetches the market's trades before the live ones,
+	// oldest first.
+	Trades func(ctx context.Context) ([]trades.Trade, error)
+}
+
+// Run runs the live source, sending the history ahead of its messages, until
+// the context is cancelled or the live source fails.
+func (b *Backfill) Run(ctx context.Context, send func(tea.Msg)) error {
+	var (
+		mu       sync.Mutex
+		s        *stitch
+		buffered []tea.Msg
+	)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- b.Live.Run(ctx, func(msg tea.Msg) {
+			mu.Lock()
+			defer mu.Unlock()
+			if s == nil {
+				buffered = append(buffered, msg)
+				return
+			}
+			s.send(msg, send)
+		})
+	}()
+
+	history := &stitch{market: b.Market}
+	msgs, err := b.history(ctx, history)
+	if err != nil && ctx.Err() == nil {
+		send(ErrMsg{Market: b.Market, Err: fmt.Errorf("could not load history: %w", err)})
+	}
+
+	mu.Lock()
+	for _, msg := range msgs {
+		send(msg)
+	}
+	for _, msg := range buffered {
+		history.send(msg, send)
+	}
+	s, buffered = history, nil
+	mu.Unlock()
+
+	return <-errc
+}
+
+// history fetches the history, returning the messages to send it in and
+// recording where it ends.
+func (b *Backfill) history(ctx context.Context, s *stitch) ([]tea.Msg, error) {
+	var msgs []tea.Msg
+	if b.Candles != nil {
+		cs, err := b.Candles(ctx)
+		if err != nil {
+			return msgs, err
+		}
+		if len(cs) > 0 {
+			s.candles = cs[len(cs)-1].Time
+			msgs = append(msgs, CandleMsg{Market: b.Market, Candles: cs})
+		}
+	}
+	if b.Trades != nil {
+		ts, err := b.Trades(ctx)
+		if err != nil {
+			return msgs, err
+		}
+		if len(ts) > 0 {
+			last := ts[len(ts)-1]
+			s.lastTrade = last
+			for i := len(ts) - 1; i >= 0 && ts[i].Time.Equal(last.Time); i-- {
+				s.tail = append(s.tail, ts[i])
+			}
+			msgs = append(msgs, TradeMsg{Market: b.Market, Trades: ts})
+		}
+	}
+	return msgs, nil
+}
+
+// stitch drops the live messages of a market that the history already had,
+// until the live messages pass its end.
+type stitch struct {
+	market string
+
+	// candles is the time of the last candle of the history, or zero once
+	// the live candles have passed it.
+	candles time.Time
+
+	// lastTrade is the last trade of the history, and tail the trades at the
+	// same time. joined is set once the live trades have passed it.
+	lastTrade trades.Trade
+	tail      []trades.Trade
+	joined    bool
+}
+
+// send sends a live message, less what the history already had.
+func (s *stitch) send(msg tea.Msg, send func(tea.Msg)) {
+	switch m := msg.(type) {
+	case CandleMsg:
+		if m.Market != s.market || s.candles.IsZero() {
+			break
+		}
+		i := 0
+		for i < len(m.Candles) && m.Candles[i].Time.Before(s.candles) {
+			i++
+		}
+		if i == len(m.Candles) {
+			return
+		}
+		s.candles = time.Time{}
+		m.Candles = m.Candles[i:]
+		msg = m
+	case TradeMsg:
+		if m.Market != s.market || s.joined || s.lastTrade.Time.IsZero() {
+			break
+		}
+		i := 0
+		for i < len(m.Trades) && s.seen(m.Trades[i]) {
+			i++
+		}
+		if i == len(m.Trades) {
+			return
+		}
+		s.joined = true
+		m.Trades = m.Trades[i:]
+		if first := m.Trades[0]; first.ID != 0 && s.lastTrade.ID != 0 && first.ID > s.lastTrade.ID+1 {
+			send(GapDetectedMsg{Channel: ChannelTrades, Market: s.market, Expected: s.lastTrade.ID + 1, Got: first.ID})
+		}
+		msg = m
+	}
+	send(msg)
+}
+
+// seen reports whether the history had a live trade.
+func (s *stitch) seen(t trades.Trade) bool {
+	if t.ID != 0 && s.lastTrade.ID != 0 {
+		return t.ID <= s.lastTrade.ID
+	}
+	if t.Time.Before(s.lastTrade.Time) {
+		return true
+	}
+	for _, h := range s.tail {
+		if t.Time.Equal(h.Time) && t.Price == h.Price && t.Volume == h.Volume && t.Side == h.Side {
+			return true
+		}
+	}
+	return false
+}
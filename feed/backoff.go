@@ -0,0 +1,41 @@
+package feed
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff defines how long to wait between attempts to reconnect or retry,
+// growing exponentially from Initial up to Max.
+type Backoff struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+
+	// Jitter randomly varies each delay by up to this fraction of it, e.g. 0.2
+	// is ±20%, so many clients dropped together don't all retry at once.
+	Jitter float64
+}
+
+// DefaultBackoff starts at half a second and doubles up to 30 seconds, with 20% jitter.
+func DefaultBackoff() Backoff {
+	return Backoff{
+		Initial:    500 * time.Millisecond,
+		Max:        30 * time.Second,
+		Multiplier: 2,
+		Jitter:     0.2,
+	}
+}
+
+// Delay returns the time to wait before the given attempt, counting from zero.
+func (b Backoff) Delay(attempt int) time.Duration {
+	d := float64(b.Initial) * math.Pow(math.Max(b.Multiplier, 1), float64(max(attempt, 0)))
+	if b.Max > 0 {
+		d = math.Min(d, float64(b.Max))
+	}
+	if b.Jitter > 0 {
+		d += d * b.Jitter * (rand.Float64()*2 - 1)
+	}
+	return max(time.Duration(d), 0)
+}
@@ -0,0 +1,150 @@
+package feed
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Coalesce returns a source running another, holding its messages back and
+// sending them once an interval, e.g. a frame of the UI, with each burst of
+// updates to a market collapsed into one message. A program redrawing on each
+// message then redraws at most once an interval however fast the updates
+// come, and a terminal too slow to keep up holds up one message per market
+// rather than every update in between.
+//
+// Messages held back in an interval are merged by market and channel:
+//
+//   - A BookUpdateMsg or TickerMsg replaces the one held for its market, as
+//     each is a snapshot, so the updates in between are dropped.
+//   - A TradeMsg's trades are appended to those held for its market. Trades
+//     are never dropped.
+//   - A CandleMsg's candles are appended to those held for its market, a
+//     candle replacing the last held when it has the same Time, so only the
+//     latest of each candle is sent.
+//
+// Other messages, like errors and state changes, are held back too, but never
+// merged or dropped. Messages are sent in the order they were first held, a
+// merged message taking the place of the first it was merged with.
+//
+// The source's messages are held without waiting, so a program slow to take
+// them can't hold up the source. When the source returns, the messages held
+// are sent before Run returns. An interval of zero or less returns the source
+// as it is.
+func Coalesce(source Source, interval time.Duration) Source {
+	if interval <= 0 {
+		return source
+	}
+	return SourceFunc(func(ctx context.Context, send func(tea.Msg)) error {
+		var c coalescer
+		errc := make(chan error, 1)
+		go func() {
+			errc <- source.Run(ctx, c.hold)
+		}()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				c.flush(send)
+			case err := <-errc:
+				c.flush(send)
+				return err
+			}
+		}
+	})
+}
+
+// coalesced identifies the message held for a market on a channel.
+type coalesced struct {
+	channel string
+	market  string
+}
+
+// coalescer holds messages back between flushes, merging them by market and
+// channel.
+type coalescer struct {
+	mu   sync.Mutex
+	held []tea.Msg
+	// index is where the message of each market and channel is held.
+	index map[coalesced]int
+
+	// sending is the messages being sent by flush, kept to hold the next.
+	sending []tea.Msg
+}
+
+// hold holds a message back until the next flush, merging it with the
+// message held for its market and channel.
+func (c *coalescer) hold(msg tea.Msg) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var key coalesced
+	switch m := msg.(type) {
+	case BookUpdateMsg:
+		key = coalesced{ChannelBook, m.Market}
+	case TickerMsg:
+		key = coalesced{ChannelTicker, m.Market}
+	case TradeMsg:
+		key = coalesced{ChannelTrades, m.Market}
+		if i, ok := c.index[key]; ok {
+			held := c.held[i].(TradeMsg)
+			held.Trades = append(held.Trades, m.Trades...)
+			msg = held
+		} else {
+			// Copy the trades, so appending to them can't write to the
+			// source's.
+			m.Trades = append(m.Trades[:0:0], m.Trades...)
+			msg = m
+		}
+	case CandleMsg:
+		key = coalesced{ChannelCandles, m.Market}
+		if i, ok := c.index[key]; ok {
+			held := c.held[i].(CandleMsg)
+			for _, candle := range m.Candles {
+				if n := len(held.Candles); n > 0 && held.Candles[n-1].Time.Equal(candle.Time) {
+					held.Candles[n-1] = candle
+				} else {
+					held.Candles = append(held.Candles, candle)
+				}
+			}
+			msg = held
+		} else {
+			m.Candles = append(m.Candles[:0:0], m.Candles...)
+			msg = m
+		}
+	default:
+		c.held = append(c.held, msg)
+		return
+	}
+
+	if i, ok := c.index[key]; ok {
+		c.held[i] = msg
+		return
+	}
+	if c.index == nil {
+		c.index = map[coalesced]int{}
+	}
+	c.index[key] = len(c.held)
+	c.held = append(c.held, msg)
+}
+
+// flush sends the messages held.
+func (c *coalescer) flush(send func(tea.Msg)) {
+	c.mu.Lock()
+	msgs := c.held
+	c.held, c.sending = c.sending[:0], nil
+	clear(c.index)
+	c.mu.Unlock()
+
+	for _, msg := range msgs {
+		send(msg)
+	}
+	clear(msgs)
+
+	c.mu.Lock()
+	c.sending = msgs
+	c.mu.Unlock()
+}
@@ -0,0 +1,72 @@
+package feed
+
+import (
+	"time"
+
+	"github.com/allank/chartea/candles"
+	"github.com/allank/chartea/clob"
+	"github.com/allank/chartea/trades"
+)
+
+// OpenInterestMsg carries the open interest of a derivatives market.
+type OpenInterestMsg struct {
+	Market string
+	Time   time.Time
+	Value  float64
+}
+
+// BookUpdateMsg carries a snapshot of a market's order book.
+type BookUpdateMsg struct {
+	Market string
+	Time   time.Time
+	Book   clob.OrderBook
+}
+
+// TradeMsg carries trades executed in a market, oldest first, e.g. for a
+// tape, a cvd.Model or to aggregate into candles.
+type TradeMsg struct {
+	Market string
+	Trades []trades.Trade
+}
+
+// CandleMsg carries candles of a market, oldest first. The latest candle is
+// updated until its interval ends, so a candle replaces any already received
+// with the same Time.
+type CandleMsg struct {
+	Market  string
+	Candles []candles.Candle
+}
+
+// TickerMsg carries a market's top of book, last price and statistics over
+// the last 24 hours, e.g. for watchlist rows that don't need the whole book.
+type TickerMsg struct {
+	Market string
+	Time   time.Time
+
+	Bid, BidVolume float64
+	Ask, AskVolume float64
+	Last           float64
+
+	// Volume is the volume traded over the last 24 hours, at a VWAP, between
+	// Low and High. Change is the change in price, and ChangePercent the
+	// change as a percentage.
+	Volume        float64
+	VWAP          float64
+	Low, High     float64
+	Change        float64
+	ChangePercent float64
+}
+
+// ErrMsg reports an error from a market's feed.
+type ErrMsg struct {
+	Market string
+	Err    error
+}
+
+func (e ErrMsg) Error() string {
+	return e.Market + ": " + e.Err.Error()
+}
+
+func (e ErrMsg) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,96 @@
+package feed
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrGap is wrapped by the errors of adapters that missed messages of a
+// sequenced stream, so it can be told apart from other failures.
+var ErrGap = errors.New("sequence gap")
+
+// GapError reports messages missed from a market's stream on a channel,
+// found by a jump in their sequence numbers. It wraps ErrGap.
+type GapError struct {
+	Channel string
+	Market  string
+	// Expected is the sequence number that should have come next, and Got
+	// the one that came instead.
+	Expected, Got int64
+}
+
+func (e *GapError) Error() string {
+	stream := e.Channel
+	if e.Market != "" {
+		stream = e.Market + " " + e.Channel
+	}
+	return fmt.Sprintf("missed %s messages %d to %d", stream, e.Expected, e.Got-1)
+}
+
+func (e *GapError) Unwrap() error {
+	return ErrGap
+}
+
+// GapDetectedMsg reports messages missed from a market's stream, so the UI
+// can flag what it shows as possibly inconsistent. Sources resync what they
+// can, e.g. a book from a fresh snapshot, but missed trades are lost.
+type GapDetectedMsg struct {
+	// Source names the source, e.g. the exchange.
+	Source  string
+	Channel string
+	Market  string
+	// Expected is the sequence number that should have come next, and Got
+	// the one that came instead.
+	Expected, Got int64
+}
+
+// Missed returns the number of messages missed.
+func (m GapDetectedMsg) Missed() int64 {
+	return m.Got - m.Expected
+}
+
+// sequence identifies a sequenced stream.
+type sequence struct {
+	channel string
+	market  string
+}
+
+// Sequences tracks the sequence numbers of each market's stream on each
+// channel of a venue, to find messages missed in between. It's safe for
+// concurrent use.
+type Sequences struct {
+	mu   sync.Mutex
+	last map[sequence]int64
+}
+
+// Check records the sequence number of a message of a market's stream on a
+// channel. It reports false for a message already seen, which should be
+// skipped, e.g. one sent again after a reconnect. A message that doesn't
+// follow on from the last returns a *GapError, and is recorded as the last
+// so the gap is only reported once. The first message of a stream starts it.
+func (s *Sequences) Check(channel, market string, seq int64) (fresh bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.last == nil {
+		s.last = map[sequence]int64{}
+	}
+	k := sequence{channel, market}
+	last, ok := s.last[k]
+	if ok && seq <= last {
+		return false, nil
+	}
+	s.last[k] = seq
+	if ok && seq > last+1 {
+		return true, &GapError{Channel: channel, Market: market, Expected: last + 1, Got: seq}
+	}
+	return true, nil
+}
+
+// Reset forgets the sequence number of a market's stream on a channel, e.g.
+// when a snapshot restarts it or the market is unsubscribed from.
+func (s *Sequences) Reset(channel, market string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.last, sequence{channel, market})
+}
@@ -0,0 +1,264 @@
+// Package grpc streams market data from any service implementing the
+// MarketData service in marketdatapb/marketdata.proto, so internal market
+// data services can drive the components without exchange specific code.
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/allank/chartea/clob"
+	"github.com/allank/chartea/feed"
+	"github.com/allank/chartea/feed/grpc/marketdatapb"
+	"github.com/allank/chartea/trades"
+
+	tea "github.com/charmbracelet/bubbletea"
+	gogrpc "google.golang.org/grpc"
+)
+
+var _ feed.Source = (*Source)(nil)
+
+// discard is the logger used when a source has none.
+var discard = slog.New(slog.DiscardHandler)
+
+// errEnded is returned when the server ends the stream.
+var errEnded = errors.New("stream ended by the server")
+
+// Source subscribes to markets on a MarketData service, keeping the book of
+// each market from its snapshots and deltas. It sends a feed.BookUpdateMsg
+// for every change to a book and a feed.TradeMsg for every batch of trades,
+// and resubscribes, backing off, when the stream fails. It implements
+// feed.Source.
+type Source struct {
+	// Name identifies the source in feed.StateMsgs.
+	Name string
+
+	// Client calls the service.
+	Client marketdatapb.MarketDataClient
+
+	// Markets are the markets to subscribe to.
+	Markets []string
+
+	// Channels are the data to stream. When empty every channel is streamed.
+	Channels []marketdatapb.Channel
+
+	// Backoff is the wait between attempts to resubscribe.
+	Backoff feed.Backoff
+
+	// Metrics, if set, records the updates and resubscriptions of the source.
+	Metrics feed.Metrics
+
+	// Logger, if set, logs subscriptions, failures and updates that can't be
+	// decoded.
+	Logger *slog.Logger
+}
+
+// New creates a source for the markets on a connection to a MarketData
+// service, e.g. from grpc.NewClient.
+func New(conn gogrpc.ClientConnInterface, markets ...string) *Source {
+	return &Source{
+		Name:    "grpc",
+		Client:  marketdatapb.NewMarketDataClient(conn),
+		Markets: markets,
+		Backoff: feed.DefaultBackoff(),
+	}
+}
+
+// Run subscribes and sends updates until the context is cancelled. Changes of
+// state are sent as feed.StateMsgs, and updates that can't be decoded as
+// feed.ErrMsgs.
+func (s *Source) Run(ctx context.Context, send func(tea.Msg)) error {
+	send(feed.StateMsg{Source: s.Name, State: feed.Connecting})
+	s.log().Info("subscribing", "markets", s.Markets)
+	attempt := 0
+	for {
+		err := s.session(ctx, send, func() { attempt = 0 })
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		delay := s.Backoff.Delay(attempt)
+		attempt++
+		if s.Metrics != nil {
+			s.Metrics.Reconnect(s.Name)
+		}
+		s.log().Warn("stream failed, resubscribing", "err", err, "attempt", attempt, "delay", delay)
+		send(feed.StateMsg{Source: s.Name, State: feed.Reconnecting, Err: err, Attempt: attempt, Delay: delay})
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// log returns the source's logger, tagged with its name.
+func (s *Source) log() *slog.Logger {
+	if s.Logger == nil {
+		return discard
+	}
+	return s.Logger.With("source", s.Name)
+}
+
+// session runs a single subscription until it fails, calling live once the
+// first update arrives. Books start afresh with each subscription.
+func (s *Source) session(ctx context.Context, send func(tea.Msg), live func()) error {
+	stream, err := s.Client.Subscribe(ctx, &marketdatapb.SubscribeRequest{Markets: s.Markets, Channels: s.Channels})
+	if err != nil {
+		return err
+	}
+	books := map[string]*book{}
+	for first := true; ; first = false {
+		u, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return errEnded
+		}
+		if err != nil {
+			return err
+		}
+		if s.Metrics != nil {
+			s.Metrics.Message(s.Name)
+		}
+		if first {
+			live()
+			s.log().Info("subscribed")
+			send(feed.StateMsg{Source: s.Name, State: feed.Live})
+		}
+		msg, err := route(books, u)
+		if err != nil {
+			s.log().Warn("invalid update", "market", u.GetMarket(), "err", err)
+			send(feed.ErrMsg{Market: u.GetMarket(), Err: err})
+			continue
+		}
+		if msg != nil {
+			send(msg)
+		}
+	}
+}
+
+// route applies an update, returning the message to send for it.
+func route(books map[string]*book, u *marketdatapb.Update) (tea.Msg, error) {
+	t := time.Now()
+	if u.GetTime() != nil {
+		t = u.GetTime().AsTime()
+	}
+	switch p := u.GetPayload().(type) {
+	case *marketdatapb.Update_Snapshot:
+		b := &book{bids: map[string]clob.Order{}, asks: map[string]clob.Order{}}
+		if err := b.apply(p.Snapshot.GetBids(), p.Snapshot.GetAsks(), t); err != nil {
+			return nil, err
+		}
+		books[u.GetMarket()] = b
+		return feed.BookUpdateMsg{Market: u.GetMarket(), Time: t, Book: b.orderBook()}, nil
+	case *marketdatapb.Update_Delta:
+		b, ok := books[u.GetMarket()]
+		if !ok {
+			return nil, fmt.Errorf("delta for %s before its snapshot", u.GetMarket())
+		}
+		if err := b.apply(p.Delta.GetBids(), p.Delta.GetAsks(), t); err != nil {
+			return nil, err
+		}
+		return feed.BookUpdateMsg{Market: u.GetMarket(), Time: t, Book: b.orderBook()}, nil
+	case *marketdatapb.Update_Trades:
+		ts, err := decodeTrades(p.Trades.GetTrades(), t)
+		if err != nil {
+			return nil, err
+		}
+		return feed.TradeMsg{Market: u.GetMarket(), Trades: ts}, nil
+	}
+	return nil, nil
+}
+
+// book is a market's book, by the exact price of each level.
+type book struct {
+	bids, asks map[string]clob.Order
+}
+
+// apply sets levels changed at time t, removing levels with no volume. The
+// levels are checked before any are applied, so a bad update leaves the book
+// as it was.
+func (b *book) apply(bids, asks []*marketdatapb.Level, t time.Time) error {
+	type change struct {
+		side  map[string]clob.Order
+		key   string
+		order clob.Order
+	}
+	var changes []change
+	for _, side := range []struct {
+		orders map[string]clob.Order
+		levels []*marketdatapb.Level
+	}{{b.bids, bids}, {b.asks, asks}} {
+		for _, l := range side.levels {
+			o, err := clob.NewOrder(l.GetPrice(), l.GetVolume())
+			if err != nil {
+				return err
+			}
+			o.Count = int(l.GetCount())
+			o.Time = t
+			changes = append(changes, change{side.orders, o.BigPrice().Text('g', -1), o})
+		}
+	}
+	for _, c := range changes {
+		if c.order.BigVolume().Sign() == 0 {
+			delete(c.side, c.key)
+			continue
+		}
+		c.side[c.key] = c.order
+	}
+	return nil
+}
+
+// orderBook returns a copy of the book, best levels first.
+func (b *book) orderBook() clob.OrderBook {
+	return clob.OrderBook{
+		Bids: sorted(b.bids, func(a, b clob.Order) bool { return a.Price > b.Price }),
+		Asks: sorted(b.asks, func(a, b clob.Order) bool { return a.Price < b.Price }),
+	}
+}
+
+// sorted returns the orders of a side in order.
+func sorted(side map[string]clob.Order, less func(a, b clob.Order) bool) []clob.Order {
+	orders := make([]clob.Order, 0, len(side))
+	for _, o := range side {
+		orders = append(orders, o)
+	}
+	sort.Slice(orders, func(i, j int) bool { return less(orders[i], orders[j]) })
+	return orders
+}
+
+// decodeTrades converts trades, timing those without a time at t.
+func decodeTrades(in []*marketdatapb.Trade, t time.Time) ([]trades.Trade, error) {
+	out := make([]trades.Trade, len(in))
+	for i, tr := range in {
+		price, err := strconv.ParseFloat(tr.GetPrice(), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trade price %q: %w", tr.GetPrice(), err)
+		}
+		volume, err := strconv.ParseFloat(tr.GetVolume(), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trade volume %q: %w", tr.GetVolume(), err)
+		}
+		side := trades.Unknown
+		switch tr.GetSide() {
+		case marketdatapb.Side_SIDE_BUY:
+			side = trades.Buy
+		case marketdatapb.Side_SIDE_SELL:
+			side = trades.Sell
+		}
+		at := t
+		if tr.GetTime() != nil {
+			at = tr.GetTime().AsTime()
+		}
+		out[i] = trades.Trade{Time: at, Price: price, Volume: volume, Side: side}
+	}
+	return out, nil
+}
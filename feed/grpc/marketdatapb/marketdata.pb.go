@@ -0,0 +1,700 @@
+// Market data for chartea widgets. A service implementing MarketData can
+// drive the components through the feed/grpc adapter, without any exchange
+// specific code.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.29.3
+// source: marketdata.proto
+
+package marketdatapb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Channel is a kind of data to stream.
+type Channel int32
+
+const (
+	Channel_CHANNEL_UNSPECIFIED Channel = 0
+	Channel_CHANNEL_BOOK        Channel = 1
+	Channel_CHANNEL_TRADES      Channel = 2
+)
+
+// Enum value maps for Channel.
+var (
+	Channel_name = map[int32]string{
+		0: "CHANNEL_UNSPECIFIED",
+		1: "CHANNEL_BOOK",
+		2: "CHANNEL_TRADES",
+	}
+	Channel_value = map[string]int32{
+		"CHANNEL_UNSPECIFIED": 0,
+		"CHANNEL_BOOK":        1,
+		"CHANNEL_TRADES":      2,
+	}
+)
+
+func (x Channel) Enum() *Channel {
+	p := new(Channel)
+	*p = x
+	return p
+}
+
+func (x Channel) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Channel) Descriptor() protoreflect.EnumDescriptor {
+	return file_marketdata_proto_enumTypes[0].Descriptor()
+}
+
+func (Channel) Type() protoreflect.EnumType {
+	return &file_marketdata_proto_enumTypes[0]
+}
+
+func (x Channel) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Channel.Descriptor instead.
+func (Channel) EnumDescriptor() ([]byte, []int) {
+	return file_marketdata_proto_rawDescGZIP(), []int{0}
+}
+
+// Side is the side of the aggressor in a trade.
+type Side int32
+
+const (
+	Side_SIDE_UNSPECIFIED Side = 0
+	Side_SIDE_BUY         Side = 1
+	Side_SIDE_SELL        Side = 2
+)
+
+// Enum value maps for Side.
+var (
+	Side_name = map[int32]string{
+		0: "SIDE_UNSPECIFIED",
+		1: "SIDE_BUY",
+		2: "SIDE_SELL",
+	}
+	Side_value = map[string]int32{
+		"SIDE_UNSPECIFIED": 0,
+		"SIDE_BUY":         1,
+		"SIDE_SELL":        2,
+	}
+)
+
+func (x Side) Enum() *Side {
+	p := new(Side)
+	*p = x
+	return p
+}
+
+func (x Side) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Side) Descriptor() protoreflect.EnumDescriptor {
+	return file_marketdata_proto_enumTypes[1].Descriptor()
+}
+
+func (Side) Type() protoreflect.EnumType {
+	return &file_marketdata_proto_enumTypes[1]
+}
+
+func (x Side) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Side.Descriptor instead.
+func (Side) EnumDescriptor() ([]byte, []int) {
+	return file_marketdata_proto_rawDescGZIP(), []int{1}
+}
+
+type SubscribeRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Markets []string               `protobuf:"bytes,1,rep,name=markets,proto3" json:"markets,omitempty"`
+	// Channels to stream. When empty every channel is streamed.
+	Channels      []Channel `protobuf:"varint,2,rep,packed,name=channels,proto3,enum=chartea.marketdata.v1.Channel" json:"channels,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeRequest) Reset() {
+	*x = SubscribeRequest{}
+	mi := &file_marketdata_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeRequest) ProtoMessage() {}
+
+func (x *SubscribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_marketdata_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeRequest) Descriptor() ([]byte, []int) {
+	return file_marketdata_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SubscribeRequest) GetMarkets() []string {
+	if x != nil {
+		return x.Markets
+	}
+	return nil
+}
+
+func (x *SubscribeRequest) GetChannels() []Channel {
+	if x != nil {
+		return x.Channels
+	}
+	return nil
+}
+
+// Update is a message about one market.
+type Update struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Market string                 `protobuf:"bytes,1,opt,name=market,proto3" json:"market,omitempty"`
+	Time   *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=time,proto3" json:"time,omitempty"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*Update_Snapshot
+	//	*Update_Delta
+	//	*Update_Trades
+	Payload       isUpdate_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Update) Reset() {
+	*x = Update{}
+	mi := &file_marketdata_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Update) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Update) ProtoMessage() {}
+
+func (x *Update) ProtoReflect() protoreflect.Message {
+	mi := &file_marketdata_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Update.ProtoReflect.Descriptor instead.
+func (*Update) Descriptor() ([]byte, []int) {
+	return file_marketdata_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Update) GetMarket() string {
+	if x != nil {
+		return x.Market
+	}
+	return ""
+}
+
+func (x *Update) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *Update) GetPayload() isUpdate_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *Update) GetSnapshot() *BookSnapshot {
+	if x != nil {
+		if x, ok := x.Payload.(*Update_Snapshot); ok {
+			return x.Snapshot
+		}
+	}
+	return nil
+}
+
+func (x *Update) GetDelta() *BookDelta {
+	if x != nil {
+		if x, ok := x.Payload.(*Update_Delta); ok {
+			return x.Delta
+		}
+	}
+	return nil
+}
+
+func (x *Update) GetTrades() *Trades {
+	if x != nil {
+		if x, ok := x.Payload.(*Update_Trades); ok {
+			return x.Trades
+		}
+	}
+	return nil
+}
+
+type isUpdate_Payload interface {
+	isUpdate_Payload()
+}
+
+type Update_Snapshot struct {
+	Snapshot *BookSnapshot `protobuf:"bytes,3,opt,name=snapshot,proto3,oneof"`
+}
+
+type Update_Delta struct {
+	Delta *BookDelta `protobuf:"bytes,4,opt,name=delta,proto3,oneof"`
+}
+
+type Update_Trades struct {
+	Trades *Trades `protobuf:"bytes,5,opt,name=trades,proto3,oneof"`
+}
+
+func (*Update_Snapshot) isUpdate_Payload() {}
+
+func (*Update_Delta) isUpdate_Payload() {}
+
+func (*Update_Trades) isUpdate_Payload() {}
+
+// Level is a price level of a book. Prices and volumes are decimal text, so
+// they are shown exactly, e.g. "0.00000001234".
+type Level struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Price  string                 `protobuf:"bytes,1,opt,name=price,proto3" json:"price,omitempty"`
+	Volume string                 `protobuf:"bytes,2,opt,name=volume,proto3" json:"volume,omitempty"`
+	// Count is the number of orders at the level, or zero when unknown.
+	Count         int32 `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Level) Reset() {
+	*x = Level{}
+	mi := &file_marketdata_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Level) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Level) ProtoMessage() {}
+
+func (x *Level) ProtoReflect() protoreflect.Message {
+	mi := &file_marketdata_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Level.ProtoReflect.Descriptor instead.
+func (*Level) Descriptor() ([]byte, []int) {
+	return file_marketdata_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Level) GetPrice() string {
+	if x != nil {
+		return x.Price
+	}
+	return ""
+}
+
+func (x *Level) GetVolume() string {
+	if x != nil {
+		return x.Volume
+	}
+	return ""
+}
+
+func (x *Level) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+// BookSnapshot replaces the whole book.
+type BookSnapshot struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bids          []*Level               `protobuf:"bytes,1,rep,name=bids,proto3" json:"bids,omitempty"`
+	Asks          []*Level               `protobuf:"bytes,2,rep,name=asks,proto3" json:"asks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BookSnapshot) Reset() {
+	*x = BookSnapshot{}
+	mi := &file_marketdata_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BookSnapshot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BookSnapshot) ProtoMessage() {}
+
+func (x *BookSnapshot) ProtoReflect() protoreflect.Message {
+	mi := &file_marketdata_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BookSnapshot.ProtoReflect.Descriptor instead.
+func (*BookSnapshot) Descriptor() ([]byte, []int) {
+	return file_marketdata_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BookSnapshot) GetBids() []*Level {
+	if x != nil {
+		return x.Bids
+	}
+	return nil
+}
+
+func (x *BookSnapshot) GetAsks() []*Level {
+	if x != nil {
+		return x.Asks
+	}
+	return nil
+}
+
+// BookDelta changes levels of the book. A level with a volume of zero
+// removes the level.
+type BookDelta struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bids          []*Level               `protobuf:"bytes,1,rep,name=bids,proto3" json:"bids,omitempty"`
+	Asks          []*Level               `protobuf:"bytes,2,rep,name=asks,proto3" json:"asks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BookDelta) Reset() {
+	*x = BookDelta{}
+	mi := &file_marketdata_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BookDelta) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BookDelta) ProtoMessage() {}
+
+func (x *BookDelta) ProtoReflect() protoreflect.Message {
+	mi := &file_marketdata_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BookDelta.ProtoReflect.Descriptor instead.
+func (*BookDelta) Descriptor() ([]byte, []int) {
+	return file_marketdata_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BookDelta) GetBids() []*Level {
+	if x != nil {
+		return x.Bids
+	}
+	return nil
+}
+
+func (x *BookDelta) GetAsks() []*Level {
+	if x != nil {
+		return x.Asks
+	}
+	return nil
+}
+
+type Trade struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Price         string                 `protobuf:"bytes,1,opt,name=price,proto3" json:"price,omitempty"`
+	Volume        string                 `protobuf:"bytes,2,opt,name=volume,proto3" json:"volume,omitempty"`
+	Side          Side                   `protobuf:"varint,3,opt,name=side,proto3,enum=chartea.marketdata.v1.Side" json:"side,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=time,proto3" json:"time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Trade) Reset() {
+	*x = Trade{}
+	mi := &file_marketdata_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Trade) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Trade) ProtoMessage() {}
+
+func (x *Trade) ProtoReflect() protoreflect.Message {
+	mi := &file_marketdata_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Trade.ProtoReflect.Descriptor instead.
+func (*Trade) Descriptor() ([]byte, []int) {
+	return file_marketdata_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Trade) GetPrice() string {
+	if x != nil {
+		return x.Price
+	}
+	return ""
+}
+
+func (x *Trade) GetVolume() string {
+	if x != nil {
+		return x.Volume
+	}
+	return ""
+}
+
+func (x *Trade) GetSide() Side {
+	if x != nil {
+		return x.Side
+	}
+	return Side_SIDE_UNSPECIFIED
+}
+
+func (x *Trade) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+// Trades are trades executed in a market, oldest first.
+type Trades struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Trades        []*Trade               `protobuf:"bytes,1,rep,name=trades,proto3" json:"trades,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Trades) Reset() {
+	*x = Trades{}
+	mi := &file_marketdata_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Trades) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Trades) ProtoMessage() {}
+
+func (x *Trades) ProtoReflect() protoreflect.Message {
+	mi := &file_marketdata_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Trades.ProtoReflect.Descriptor instead.
+func (*Trades) Descriptor() ([]byte, []int) {
+	return file_marketdata_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Trades) GetTrades() []*Trade {
+	if x != nil {
+		return x.Trades
+	}
+	return nil
+}
+
+var File_marketdata_proto protoreflect.FileDescriptor
+
+const file_marketdata_proto_rawDesc = "" +
+	"\n" +
+	"\x10marketdata.proto\x12\x15chartea.marketdata.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"h\n" +
+	"\x10SubscribeRequest\x12\x18\n" +
+	"\amarkets\x18\x01 \x03(\tR\amarkets\x12:\n" +
+	"\bchannels\x18\x02 \x03(\x0e2\x1e.chartea.marketdata.v1.ChannelR\bchannels\"\x91\x02\n" +
+	"\x06Update\x12\x16\n" +
+	"\x06market\x18\x01 \x01(\tR\x06market\x12.\n" +
+	"\x04time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12A\n" +
+	"\bsnapshot\x18\x03 \x01(\v2#.chartea.marketdata.v1.BookSnapshotH\x00R\bsnapshot\x128\n" +
+	"\x05delta\x18\x04 \x01(\v2 .chartea.marketdata.v1.BookDeltaH\x00R\x05delta\x127\n" +
+	"\x06trades\x18\x05 \x01(\v2\x1d.chartea.marketdata.v1.TradesH\x00R\x06tradesB\t\n" +
+	"\apayload\"K\n" +
+	"\x05Level\x12\x14\n" +
+	"\x05price\x18\x01 \x01(\tR\x05price\x12\x16\n" +
+	"\x06volume\x18\x02 \x01(\tR\x06volume\x12\x14\n" +
+	"\x05count\x18\x03 \x01(\x05R\x05count\"r\n" +
+	"\fBookSnapshot\x120\n" +
+	"\x04bids\x18\x01 \x03(\v2\x1c.chartea.marketdata.v1.LevelR\x04bids\x120\n" +
+	"\x04asks\x18\x02 \x03(\v2\x1c.chartea.marketdata.v1.LevelR\x04asks\"o\n" +
+	"\tBookDelta\x120\n" +
+	"\x04bids\x18\x01 \x03(\v2\x1c.chartea.marketdata.v1.LevelR\x04bids\x120\n" +
+	"\x04asks\x18\x02 \x03(\v2\x1c.chartea.marketdata.v1.LevelR\x04asks\"\x96\x01\n" +
+	"\x05Trade\x12\x14\n" +
+	"\x05price\x18\x01 \x01(\tR\x05price\x12\x16\n" +
+	"\x06volume\x18\x02 \x01(\tR\x06volume\x12/\n" +
+	"\x04side\x18\x03 \x01(\x0e2\x1b.chartea.marketdata.v1.SideR\x04side\x12.\n" +
+	"\x04time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\">\n" +
+	"\x06Trades\x124\n" +
+	"\x06trades\x18\x01 \x03(\v2\x1c.chartea.marketdata.v1.TradeR\x06trades*H\n" +
+	"\aChannel\x12\x17\n" +
+	"\x13CHANNEL_UNSPECIFIED\x10\x00\x12\x10\n" +
+	"\fCHANNEL_BOOK\x10\x01\x12\x12\n" +
+	"\x0eCHANNEL_TRADES\x10\x02*9\n" +
+	"\x04Side\x12\x14\n" +
+	"\x10SIDE_UNSPECIFIED\x10\x00\x12\f\n" +
+	"\bSIDE_BUY\x10\x01\x12\r\n" +
+	"\tSIDE_SELL\x10\x022c\n" +
+	"\n" +
+	"MarketData\x12U\n" +
+	"\tSubscribe\x12'.chartea.marketdata.v1.SubscribeRequest\x1a\x1d.chartea.marketdata.v1.Update0\x01B2Z0github.com/allank/chartea/feed/grpc/marketdatapbb\x06proto3"
+
+var (
+	file_marketdata_proto_rawDescOnce sync.Once
+	file_marketdata_proto_rawDescData []byte
+)
+
+func file_marketdata_proto_rawDescGZIP() []byte {
+	file_marketdata_proto_rawDescOnce.Do(func() {
+		file_marketdata_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_marketdata_proto_rawDesc), len(file_marketdata_proto_rawDesc)))
+	})
+	return file_marketdata_proto_rawDescData
+}
+
+var file_marketdata_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_marketdata_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_marketdata_proto_goTypes = []any{
+	(Channel)(0),                  // 0: chartea.marketdata.v1.Channel
+	(Side)(0),                     // 1: chartea.marketdata.v1.Side
+	(*SubscribeRequest)(nil),      // 2: chartea.marketdata.v1.SubscribeRequest
+	(*Update)(nil),                // 3: chartea.marketdata.v1.Update
+	(*Level)(nil),                 // 4: chartea.marketdata.v1.Level
+	(*BookSnapshot)(nil),          // 5: chartea.marketdata.v1.BookSnapshot
+	(*BookDelta)(nil),             // 6: chartea.marketdata.v1.BookDelta
+	(*Trade)(nil),                 // 7: chartea.marketdata.v1.Trade
+	(*Trades)(nil),                // 8: chartea.marketdata.v1.Trades
+	(*timestamppb.Timestamp)(nil), // 9: google.protobuf.Timestamp
+}
+var file_marketdata_proto_depIdxs = []int32{
+	0,  // 0: chartea.marketdata.v1.SubscribeRequest.channels:type_name -> chartea.marketdata.v1.Channel
+	9,  // 1: chartea.marketdata.v1.Update.time:type_name -> google.protobuf.Timestamp
+	5,  // 2: chartea.marketdata.v1.Update.snapshot:type_name -> chartea.marketdata.v1.BookSnapshot
+	6,  // 3: chartea.marketdata.v1.Update.delta:type_name -> chartea.marketdata.v1.BookDelta
+	8,  // 4: chartea.marketdata.v1.Update.trades:type_name -> chartea.marketdata.v1.Trades
+	4,  // 5: chartea.marketdata.v1.BookSnapshot.bids:type_name -> chartea.marketdata.v1.Level
+	4,  // 6: chartea.marketdata.v1.BookSnapshot.asks:type_name -> chartea.marketdata.v1.Level
+	4,  // 7: chartea.marketdata.v1.BookDelta.bids:type_name -> chartea.marketdata.v1.Level
+	4,  // 8: chartea.marketdata.v1.BookDelta.asks:type_name -> chartea.marketdata.v1.Level
+	1,  // 9: chartea.marketdata.v1.Trade.side:type_name -> chartea.marketdata.v1.Side
+	9,  // 10: chartea.marketdata.v1.Trade.time:type_name -> google.protobuf.Timestamp
+	7,  // 11: chartea.marketdata.v1.Trades.trades:type_name -> chartea.marketdata.v1.Trade
+	2,  // 12: chartea.marketdata.v1.MarketData.Subscribe:input_type -> chartea.marketdata.v1.SubscribeRequest
+	3,  // 13: chartea.marketdata.v1.MarketData.Subscribe:output_type -> chartea.marketdata.v1.Update
+	13, // [13:14] is the sub-list for method output_type
+	12, // [12:13] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
+}
+
+func init() { file_marketdata_proto_init() }
+func file_marketdata_proto_init() {
+	if File_marketdata_proto != nil {
+		return
+	}
+	file_marketdata_proto_msgTypes[1].OneofWrappers = []any{
+		(*Update_Snapshot)(nil),
+		(*Update_Delta)(nil),
+		(*Update_Trades)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_marketdata_proto_rawDesc), len(file_marketdata_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_marketdata_proto_goTypes,
+		DependencyIndexes: file_marketdata_proto_depIdxs,
+		EnumInfos:         file_marketdata_proto_enumTypes,
+		MessageInfos:      file_marketdata_proto_msgTypes,
+	}.Build()
+	File_marketdata_proto = out.File
+	file_marketdata_proto_goTypes = nil
+	file_marketdata_proto_depIdxs = nil
+}
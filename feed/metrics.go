@@ -0,0 +1,20 @@
+package feed
+
+import "errors"
+
+// ErrChecksum is wrapped by the errors of adapters whose book failed to match
+// the exchange's checksum, so it can be told apart from other failures.
+var ErrChecksum = errors.New("checksum mismatch")
+
+// Metrics records the health of sources, e.g. for monitoring a long running
+// dashboard. Sources call it from their own goroutines, so implementations
+// must be safe for concurrent use. See the metrics package for a Prometheus
+// collector.
+type Metrics interface {
+	// Message records a message received by a source.
+	Message(source string)
+	// Reconnect records a source reconnecting after its connection dropped.
+	Reconnect(source string)
+	// ChecksumFailure records a book that failed its checksum.
+	ChecksumFailure(source string)
+}
@@ -0,0 +1,98 @@
+package poll
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"github.com/allank/chartea/clob"
+	"github.com/allank/chartea/feed"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var _ feed.Source = (*Poller)(nil)
+
+// FetchFunc fetches a snapshot of an order book, e.g. from a REST API.
+type FetchFunc func(ctx context.Context) (clob.OrderBook, error)
+
+// Poller fetches an order book on an interval, for exchanges and internal APIs
+// that don't offer a websocket feed. It implements feed.Source.
+type Poller struct {
+	// Market is set on the messages sent, to tell markets apart.
+	Market string
+
+	// Fetch is called to get each snapshot.
+	Fetch FetchFunc
+
+	// Interval is the time between fetches. When zero the book is only
+	// fetched once.
+	Interval time.Duration
+
+	// Jitter randomly varies each interval by up to this fraction of it, e.g.
+	// 0.1 is ±10%, so many pollers started together don't fetch in bursts.
+	Jitter float64
+
+	// Metrics, if set, records each snapshot fetched, by the market.
+	Metrics feed.Metrics
+
+	// Logger, if set, logs failed fetches.
+	Logger *slog.Logger
+}
+
+// New creates a poller for the market with 10% jitter.
+func New(market string, interval time.Duration, fetch FetchFunc) *Poller {
+	return &Poller{
+		Market:   market,
+		Fetch:    fetch,
+		Interval: interval,
+		Jitter:   0.1,
+	}
+}
+
+// Run fetches the book straight away and then on every interval until the
+// context is cancelled, passing a feed.BookUpdateMsg or feed.ErrMsg to send
+// for each fetch. Send is typically a tea.Program's Send method. Run blocks,
+// so it's usually started in its own goroutine or with feed.Start. Fetch
+// errors are sent rather than returned, so Run only returns, with nil, once
+// the context is cancelled.
+func (p *Poller) Run(ctx context.Context, send func(tea.Msg)) error {
+	for {
+		book, err := p.Fetch(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			if p.Logger != nil {
+				p.Logger.Warn("fetch failed", "market", p.Market, "err", err)
+			}
+			send(feed.ErrMsg{Market: p.Market, Err: err})
+		} else {
+			if p.Metrics != nil {
+				p.Metrics.Message(p.Market)
+			}
+			send(feed.BookUpdateMsg{Market: p.Market, Time: time.Now(), Book: book})
+		}
+
+		if p.Interval <= 0 {
+			return nil
+		}
+		timer := time.NewTimer(p.next())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// next returns the time to wait before the next fetch.
+func (p *Poller) next() time.Duration {
+	d := float64(p.Interval)
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (rand.Float64()*2 - 1)
+	}
+	return max(time.Duration(d), 0)
+}
@@ -0,0 +1,117 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Channels of data a Request can ask an exchange for. Adapters map them onto
+// their exchange's own channels.
+const (
+	ChannelBook    = "book"
+	ChannelTrades  = "trades"
+	ChannelCandles = "candles"
+	ChannelTicker  = "ticker"
+)
+
+// Request is the data a consumer, such as the chartea dashboard, wants from
+// an exchange.
+type Request struct {
+	// Market is the market as the user named it, e.g. BTC/USD.
+	Market string
+	// Channels are the data to stream, from ChannelBook, ChannelTrades,
+	// ChannelCandles and ChannelTicker.
+	Channels []string
+	// Interval is the interval of the candles, or zero for the adapter's
+	// default.
+	Interval time.Duration
+	// Metrics, if set, is given to the stream's connections to record their
+	// health.
+	Metrics Metrics
+	// Logger, if set, is given to the stream's connections and clients to
+	// log connects, reconnects and errors.
+	Logger *slog.Logger
+	// TracerProvider, if set, is given to the stream's connections and
+	// clients to trace requests and the handling of messages.
+	TracerProvider trace.TracerProvider
+}
+
+// Stream is an exchange's data for a Request.
+type Stream struct {
+	// Source sends the data requested. Adapters may send recent history,
+	// such as candles, before the live data.
+	Source
+
+	// Market is the name of the market in the stream's messages.
+	Market string
+
+	// PriceDecimals and VolumeDecimals are the precision of the market.
+	PriceDecimals  int
+	VolumeDecimals int
+}
+
+// Factory opens a stream for a request, e.g. looking up the market and
+// preparing its subscriptions. It returns an error if the market or a
+// channel isn't available.
+type Factory func(ctx context.Context, req Request) (Stream, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{}
+)
+
+// Register makes an exchange adapter available by name, usually from the init
+// function of the adapter's package, so importing the package is enough to
+// use it, e.g.
+//
+//	func init() {
+//		feed.Register("kraken", kraken.Open)
+//	}
+//
+// Register panics if the name is already registered or the factory is nil.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if factory == nil {
+		panic("feed: Register factory is nil for " + name)
+	}
+	if _, dup := registry[name]; dup {
+		panic("feed: Register called twice for " + name)
+	}
+	registry[name] = factory
+}
+
+// Lookup returns the factory registered with a name.
+func Lookup(name string) (Factory, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	factory, ok := registry[name]
+	return factory, ok
+}
+
+// Exchanges returns the names of the registered adapters, sorted.
+func Exchanges() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Open opens a stream from the adapter registered with a name.
+func Open(ctx context.Context, name string, req Request) (Stream, error) {
+	factory, ok := Lookup(name)
+	if !ok {
+		return Stream{}, fmt.Errorf("unknown exchange %q, registered exchanges are %v", name, Exchanges())
+	}
+	return factory(ctx, req)
+}
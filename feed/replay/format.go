@@ -0,0 +1,200 @@
+package replay
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/allank/chartea/candles"
+	"github.com/allank/chartea/clob"
+	"github.com/allank/chartea/feed"
+	"github.com/allank/chartea/trades"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Types of event in a recording.
+const (
+	// TypeMarket describes the market recorded, with its precision. It's
+	// usually the first line.
+	TypeMarket = "market"
+	// TypeBook is a snapshot of the order book, as a feed.BookUpdateMsg.
+	TypeBook = "book"
+	// TypeTrades is a batch of trades, as a feed.TradeMsg.
+	TypeTrades = "trades"
+	// TypeCandles is a batch of candles, as a feed.CandleMsg.
+	TypeCandles = "candles"
+)
+
+// Event is a line of a recording: a JSON object with the time it was
+// received, its type, the market and the data for the type, e.g.
+//
+//	{"time":"2024-05-01T12:00:00Z","type":"market","market":"BTC/USD","price_decimals":1,"volume_decimals":8}
+//	{"time":"2024-05-01T12:00:00.25Z","type":"book","market":"BTC/USD","book":{"bids":[{"price":63000.1,"volume":0.5}],"asks":[{"price":63000.2,"volume":1.2}]}}
+//	{"time":"2024-05-01T12:00:00.31Z","type":"trades","market":"BTC/USD","trades":[{"time":"2024-05-01T12:00:00.3Z","price":63000.2,"volume":0.01,"side":"buy"}]}
+//
+// Levels of the book are clob.Orders. Trades have a side of "buy", "sell" or
+// none when unknown.
+type Event struct {
+	Time   time.Time `json:"time"`
+	Type   string    `json:"type"`
+	Market string    `json:"market,omitempty"`
+
+	// PriceDecimals and VolumeDecimals are the precision of a TypeMarket.
+	PriceDecimals  int `json:"price_decimals,omitempty"`
+	VolumeDecimals int `json:"volume_decimals,omitempty"`
+
+	Book    *Book    `json:"book,omitempty"`
+	Trades  []Trade  `json:"trades,omitempty"`
+	Candles []Candle `json:"candles,omitempty"`
+}
+
+// Book is an order book as recorded.
+type Book struct {
+	Bids []clob.Order `json:"bids"`
+	Asks []clob.Order `json:"asks"`
+}
+
+// Trade is a trade as recorded.
+type Trade struct {
+	Time   time.Time `json:"time"`
+	Price  float64   `json:"price"`
+	Volume float64   `json:"volume"`
+	Side   string    `json:"side,omitempty"`
+	ID     int64     `json:"id,omitempty"`
+}
+
+// Candle is a candle as recorded.
+type Candle struct {
+	Time   time.Time `json:"time"`
+	Open   float64   `json:"open"`
+	High   float64   `json:"high"`
+	Low    float64   `json:"low"`
+	Close  float64   `json:"close"`
+	Volume float64   `json:"volume"`
+}
+
+// sides names the sides of trades.
+var sides = map[trades.Side]string{trades.Buy: "buy", trades.Sell: "sell"}
+
+// NewEvent returns the event recording a message received at time t, or
+// false if the message isn't a feed.BookUpdateMsg, TradeMsg or CandleMsg.
+func NewEvent(t time.Time, msg tea.Msg) (Event, bool) {
+	switch msg := msg.(type) {
+	case feed.BookUpdateMsg:
+		return Event{Time: t, Type: TypeBook, Market: msg.Market, Book: &Book{Bids: msg.Book.Bids, Asks: msg.Book.Asks}}, true
+	case feed.TradeMsg:
+		e := Event{Time: t, Type: TypeTrades, Market: msg.Market, Trades: make([]Trade, len(msg.Trades))}
+		for i, tr := range msg.Trades {
+			e.Trades[i] = Trade{Time: tr.Time, Price: tr.Price, Volume: tr.Volume, Side: sides[tr.Side], ID: tr.ID}
+		}
+		return e, true
+	case feed.CandleMsg:
+		e := Event{Time: t, Type: TypeCandles, Market: msg.Market, Candles: make([]Candle, len(msg.Candles))}
+		for i, c := range msg.Candles {
+			e.Candles[i] = Candle(c)
+		}
+		return e, true
+	}
+	return Event{}, false
+}
+
+// Msg returns the message the event recorded, or nil for a TypeMarket or an
+// unknown type. Books are timed with the event, as they were received.
+func (e Event) Msg() tea.Msg {
+	switch e.Type {
+	case TypeBook:
+		msg := feed.BookUpdateMsg{Market: e.Market, Time: e.Time}
+		if e.Book != nil {
+			msg.Book = clob.OrderBook{Bids: e.Book.Bids, Asks: e.Book.Asks}
+		}
+		return msg
+	case TypeTrades:
+		msg := feed.TradeMsg{Market: e.Market, Trades: make([]trades.Trade, len(e.Trades))}
+		for i, t := range e.Trades {
+			msg.Trades[i] = trades.Trade{Time: t.Time, Price: t.Price, Volume: t.Volume, ID: t.ID}
+			switch t.Side {
+			case "buy":
+				msg.Trades[i].Side = trades.Buy
+			case "sell":
+				msg.Trades[i].Side = trades.Sell
+			}
+		}
+		return msg
+	case TypeCandles:
+		msg := feed.CandleMsg{Market: e.Market, Candles: make([]candles.Candle, len(e.Candles))}
+		for i, c := range e.Candles {
+			msg.Candles[i] = candles.Candle(c)
+		}
+		return msg
+	}
+	return nil
+}
+
+// Read reads the events of a recording, one JSON object per line, skipping
+// blank lines. Events are played in the order they're read, so their times
+// should only go forward.
+func Read(r io.Reader) ([]Event, error) {
+	var events []Event
+	scanner := bufio.NewScanner(r)
+	// Lines with full books can be long.
+	scanner.Buffer(make([]byte, 0, 64*1024), 16<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		events = append(events, e)
+	}
+	return events, scanner.Err()
+}
+
+// Load reads the events of a recording from a file.
+func Load(path string) ([]Event, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	events, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", path, err)
+	}
+	return events, nil
+}
+
+// Writer records events to a writer, one JSON object per line. It's safe
+// for concurrent use, so several sources can record to one file.
+type Writer struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewWriter creates a writer recording to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{enc: json.NewEncoder(w)}
+}
+
+// Write records an event.
+func (w *Writer) Write(e Event) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.enc.Encode(e)
+}
+
+// Record records a message received now, ignoring messages that can't be
+// recorded, e.g. to record a live feed by calling it from the send function
+// given to feed.Start.
+func (w *Writer) Record(msg tea.Msg) error {
+	if e, ok := NewEvent(time.Now(), msg); ok {
+		return w.Write(e)
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package replay
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/allank/chartea/feed"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var _ feed.Source = (*Player)(nil)
+
+// Speeds are the speeds a Player steps through with Faster and Slower, from
+// MinSpeed to MaxSpeed.
+var Speeds = []float64{0.5, 1, 2, 5, 10, 25, 50, 100}
+
+// The range of speeds a Player plays at.
+const (
+	MinSpeed = 0.5
+	MaxSpeed = 100
+)
+
+// Player plays a recording back, sending each event's message after the
+// time between it and the event before, divided by the speed. It can be
+// paused, stepped through an event at a time and sped up or slowed down
+// while playing. It implements feed.Source, and is safe for concurrent use.
+type Player struct {
+	events []Event
+
+	mu     sync.Mutex
+	next   int
+	speed  float64
+	paused bool
+	steps  int
+	// last is when the last event was sent.
+	last time.Time
+	// wake interrupts the wait for the next event when the player changes.
+	wake chan struct{}
+}
+
+// NewPlayer creates a player for the events, playing at normal speed.
+func NewPlayer(events []Event) *Player {
+	return &Player{events: events, speed: 1, wake: make(chan struct{}, 1)}
+}
+
+// Run sends the events' messages in time until they've all been sent, or
+// the context is cancelled. Events without a message, like TypeMarket, are
+// skipped.
+func (p *Player) Run(ctx context.Context, send func(tea.Msg)) error {
+	p.mu.Lock()
+	p.last = time.Now()
+	p.mu.Unlock()
+	for {
+		e, wait, done := p.due()
+		if done {
+			return nil
+		}
+		if wait == 0 {
+			if msg := e.Msg(); msg != nil {
+				send(msg)
+			}
+			continue
+		}
+		// While paused, wait until woken.
+		var timer *time.Timer
+		var fire <-chan time.Time
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			fire = timer.C
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-p.wake:
+		case <-fire:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// due returns the next event if it's due, advancing past it, or else how
+// long until it is: negative while paused. done reports the end of the
+// events.
+func (p *Player) due() (e Event, wait time.Duration, done bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.next >= len(p.events) {
+		return Event{}, 0, true
+	}
+	if p.paused && p.steps == 0 {
+		return Event{}, -1, false
+	}
+	e = p.events[p.next]
+	now := time.Now()
+	if p.paused {
+		p.steps--
+	} else if p.next > 0 {
+		gap := e.Time.Sub(p.events[p.next-1].Time)
+		if wait = time.Duration(float64(gap)/p.speed) - now.Sub(p.last); wait > 0 {
+			return Event{}, wait, false
+		}
+	}
+	p.next++
+	p.last = now
+	return e, 0, false
+}
+
+// changed wakes Run to look at the player again.
+func (p *Player) changed() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Speed returns the speed the events are played at.
+func (p *Player) Speed() float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.speed
+}
+
+// SetSpeed sets the speed the events are played at, e.g. 2 for twice as
+// fast, clamped from MinSpeed to MaxSpeed.
+func (p *Player) SetSpeed(speed float64) {
+	p.mu.Lock()
+	p.speed = min(max(speed, MinSpeed), MaxSpeed)
+	p.mu.Unlock()
+	p.changed()
+}
+
+// Faster plays at the next of the Speeds above the current speed.
+func (p *Player) Faster() {
+	speed := p.Speed()
+	for _, s := range Speeds {
+		if s > speed {
+			p.SetSpeed(s)
+			return
+		}
+	}
+}
+
+// Slower plays at the next of the Speeds below the current speed.
+func (p *Player) Slower() {
+	speed := p.Speed()
+	for i := len(Speeds) - 1; i >= 0; i-- {
+		if Speeds[i] < speed {
+			p.SetSpeed(Speeds[i])
+			return
+		}
+	}
+}
+
+// Pause pauses playback.
+func (p *Player) Pause() {
+	p.mu.Lock()
+	p.paused = true
+	p.mu.Unlock()
+	p.changed()
+}
+
+// Resume resumes playback, waiting the time to the next event from now.
+func (p *Player) Resume() {
+	p.mu.Lock()
+	p.paused, p.steps = false, 0
+	p.last = time.Now()
+	p.mu.Unlock()
+	p.changed()
+}
+
+// Paused reports whether playback is paused.
+func (p *Player) Paused() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.paused
+}
+
+// Step sends the next event straight away while paused.
+func (p *Player) Step() {
+	p.mu.Lock()
+	if p.paused {
+		p.steps++
+	}
+	p.mu.Unlock()
+	p.changed()
+}
+
+// Position returns how many events have been played, and how many there are.
+func (p *Player) Position() (played, total int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.next, len(p.events)
+}
+
+// Stream returns a stream playing the recording, for the market and precision
+// of its first TypeMarket event, or else the market of its first event.
+func (p *Player) Stream() feed.Stream {
+	s := feed.Stream{Source: p}
+	for _, e := range p.events {
+		if e.Type == TypeMarket {
+			s.Market, s.PriceDecimals, s.VolumeDecimals = e.Market, e.PriceDecimals, e.VolumeDecimals
+			return s
+		}
+	}
+	if len(p.events) > 0 {
+		s.Market = p.events[0].Market
+	}
+	return s
+}
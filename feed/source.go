@@ -0,0 +1,78 @@
+package feed
+
+import (
+	"context"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Source is a data source, such as an exchange connection, that sends messages
+// into a program. Run sends messages until the context is cancelled, then
+// releases everything it holds (closing connections, stopping timers and
+// draining channels) before returning nil. It returns an error if the source
+// can't continue.
+type Source interface {
+	Run(ctx context.Context, send func(tea.Msg)) error
+}
+
+// SourceFunc adapts a function to a Source.
+type SourceFunc func(ctx context.Context, send func(tea.Msg)) error
+
+// Run calls f.
+func (f SourceFunc) Run(ctx context.Context, send func(tea.Msg)) error {
+	return f(ctx, send)
+}
+
+// Group runs a set of sources until they are stopped.
+type Group struct {
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu  sync.Mutex
+	err error
+}
+
+// Start runs each source in its own goroutine, sending their messages to send,
+// e.g. a tea.Program's Send method. The sources run until the context is
+// cancelled or Stop is called. Messages sent after the sources are stopped are
+// dropped, so a source shutting down can't send to a program that has quit.
+func Start(ctx context.Context, send func(tea.Msg), sources ...Source) *Group {
+	ctx, cancel := context.WithCancel(ctx)
+	g := &Group{cancel: cancel}
+	guarded := func(msg tea.Msg) {
+		if ctx.Err() == nil {
+			send(msg)
+		}
+	}
+	for _, s := range sources {
+		g.wg.Add(1)
+		go func() {
+			defer g.wg.Done()
+			if err := s.Run(ctx, guarded); err != nil {
+				g.mu.Lock()
+				if g.err == nil {
+					g.err = err
+				}
+				g.mu.Unlock()
+			}
+		}()
+	}
+	return g
+}
+
+// Stop cancels the sources and waits for them to shut down, returning the
+// first error a source failed with.
+func (g *Group) Stop() error {
+	g.cancel()
+	return g.Wait()
+}
+
+// Wait waits for the sources to return, returning the first error a source
+// failed with.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.err
+}
@@ -0,0 +1,62 @@
+package feed
+
+import "time"
+
+// State is the state of a source's connection.
+type State int
+
+const (
+	// Connecting is the first attempt to connect.
+	Connecting State = iota
+	// Live is connected and receiving data.
+	Live
+	// Stale is connected, but no data has arrived for a while, so what is
+	// shown may be out of date.
+	Stale
+	// Reconnecting has lost the connection and is waiting to try again.
+	Reconnecting
+)
+
+func (s State) String() string {
+	switch s {
+	case Connecting:
+		return "connecting"
+	case Live:
+		return "live"
+	case Stale:
+		return "stale"
+	case Reconnecting:
+		return "reconnecting"
+	}
+	return "unknown"
+}
+
+// Healthy reports whether data is arriving, e.g. to show a green indicator.
+func (s State) Healthy() bool {
+	return s == Live
+}
+
+// Status is a snapshot of the health of a source's connection.
+type Status struct {
+	State State
+	// Since is when the source entered the state.
+	Since time.Time
+	// LastMessage is when data last arrived.
+	LastMessage time.Time
+	// Err is the error that last dropped the connection.
+	Err error
+}
+
+// StateMsg reports a change in the state of a source's connection.
+type StateMsg struct {
+	// Source names the source, e.g. the exchange.
+	Source string
+	State  State
+
+	// Err is the error that dropped the connection when Reconnecting.
+	Err error
+	// Attempt counts the reconnection attempts since the source was last
+	// live, and Delay is the wait before the next one.
+	Attempt int
+	Delay   time.Duration
+}
@@ -0,0 +1,179 @@
+package ws
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/allank/chartea/feed"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var _ feed.Source = (*Manager)(nil)
+
+// Protocol describes an exchange's subscriptions for a Manager.
+type Protocol interface {
+	// SubscribeMsg returns the message subscribing to the markets on a channel,
+	// e.g. "book" or "trade".
+	SubscribeMsg(channel string, markets []string) any
+
+	// UnsubscribeMsg returns the message ending the subscriptions.
+	UnsubscribeMsg(channel string, markets []string) any
+
+	// Route decodes a message from the server into updates for the
+	// subscriptions it belongs to. Messages that aren't for a subscription,
+	// like heartbeats, return no updates.
+	Route(data []byte) ([]Update, error)
+}
+
+// Update is a message for the consumers of a subscription.
+type Update struct {
+	Channel string
+	Market  string
+	Msg     tea.Msg
+}
+
+// key identifies a subscription.
+type key struct {
+	channel string
+	market  string
+}
+
+// consumer receives the updates for a subscription.
+type consumer struct {
+	send func(tea.Msg)
+}
+
+// Manager multiplexes many market subscriptions over a single websocket
+// connection to an exchange, routing each update to the consumers of its
+// subscription. It reconnects like a Client, subscribing to every market again.
+type Manager struct {
+	client   *Client
+	protocol Protocol
+
+	mu    sync.Mutex
+	subs  map[key][]*consumer
+	write func(v any) error
+}
+
+// NewManager creates a manager for the exchange's websocket endpoint.
+func NewManager(name, url string, protocol Protocol) *Manager {
+	m := &Manager{
+		protocol: protocol,
+		subs:     map[key][]*consumer{},
+	}
+	m.client = New(name, url, managerHandler{m})
+	return m
+}
+
+// Client returns the underlying client, e.g. to change its backoff.
+func (m *Manager) Client() *Client {
+	return m.client
+}
+
+// Run connects and runs the subscriptions until the context is cancelled.
+func (m *Manager) Run(ctx context.Context, send func(tea.Msg)) error {
+	return m.client.Run(ctx, send)
+}
+
+// Status returns the current health of the connection.
+func (m *Manager) Status() feed.Status {
+	return m.client.Status()
+}
+
+// Subscribe passes the updates for a market on a channel to send, e.g. a
+// tea.Program's Send method or a function forwarding to a single component.
+// Only the first consumer of a market subscribes with the exchange. The
+// returned function removes the consumer, unsubscribing when it was the last.
+func (m *Manager) Subscribe(channel, market string, send func(tea.Msg)) (unsubscribe func()) {
+	k := key{channel, market}
+	c := &consumer{send: send}
+
+	m.mu.Lock()
+	m.subs[k] = append(m.subs[k], c)
+	first := len(m.subs[k]) == 1
+	write := m.write
+	m.mu.Unlock()
+
+	// While disconnected the subscription is sent on the next connect.
+	if first && write != nil {
+		if err := write(m.protocol.SubscribeMsg(channel, []string{market})); err != nil {
+			m.client.log().Warn("subscribe failed", "channel", channel, "market", market, "err", err)
+		}
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { m.unsubscribe(k, c) })
+	}
+}
+
+// unsubscribe removes a consumer.
+func (m *Manager) unsubscribe(k key, c *consumer) {
+	m.mu.Lock()
+	consumers := m.subs[k]
+	for i, other := range consumers {
+		if other == c {
+			consumers = append(consumers[:i:i], consumers[i+1:]...)
+			break
+		}
+	}
+	last := len(consumers) == 0
+	if last {
+		delete(m.subs, k)
+	} else {
+		m.subs[k] = consumers
+	}
+	write := m.write
+	m.mu.Unlock()
+
+	if last && write != nil {
+		if err := write(m.protocol.UnsubscribeMsg(k.channel, []string{k.market})); err != nil {
+			m.client.log().Warn("unsubscribe failed", "channel", k.channel, "market", k.market, "err", err)
+		}
+	}
+}
+
+// managerHandler adapts a Manager to the Handler of its client.
+type managerHandler struct {
+	m *Manager
+}
+
+// Subscribe sends every subscription, grouping the markets of each channel
+// into one message.
+func (h managerHandler) Subscribe(ctx context.Context, write func(v any) error) error {
+	h.m.mu.Lock()
+	h.m.write = write
+	channels := map[string][]string{}
+	for k := range h.m.subs {
+		channels[k.channel] = append(channels[k.channel], k.market)
+	}
+	h.m.mu.Unlock()
+
+	for channel, markets := range channels {
+		sort.Strings(markets)
+		h.m.client.log().Debug("subscribing", "channel", channel, "markets", markets)
+		if err := write(h.m.protocol.SubscribeMsg(channel, markets)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Handle routes a message to the consumers of its subscriptions.
+func (h managerHandler) Handle(data []byte, send func(tea.Msg)) error {
+	updates, err := h.m.protocol.Route(data)
+	if err != nil {
+		return err
+	}
+	for _, u := range updates {
+		h.m.mu.Lock()
+		consumers := h.m.subs[key{u.Channel, u.Market}]
+		h.m.mu.Unlock()
+		for _, c := range consumers {
+			c.send(u.Msg)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,293 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/allank/chartea/feed"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/coder/websocket"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+var _ feed.Source = (*Client)(nil)
+
+// discard is the logger used when a client has none.
+var discard = slog.New(slog.DiscardHandler)
+
+// readLimit is the largest message accepted, enough for full book snapshots.
+const readLimit = 1 << 22
+
+// Handler speaks an exchange's protocol over a websocket connection.
+type Handler interface {
+	// Subscribe sends the subscriptions, and is called after every connect.
+	// Subscribing again returns fresh snapshots, so any state kept from before
+	// a drop should be discarded.
+	Subscribe(ctx context.Context, write func(v any) error) error
+
+	// Handle handles a message from the server, sending anything of interest
+	// to the program. An error drops the connection and reconnects. An error
+	// wrapping a *feed.GapError is reported with a feed.GapDetectedMsg first,
+	// and subscribing again resyncs from fresh snapshots.
+	Handle(data []byte, send func(tea.Msg)) error
+}
+
+// Client is a websocket feed that reconnects with backoff whenever the
+// connection drops. It implements feed.Source.
+type Client struct {
+	// Name identifies the client in feed.StateMsgs, e.g. the exchange.
+	Name string
+
+	// URL is the websocket endpoint.
+	URL string
+
+	// Handler speaks the exchange's protocol.
+	Handler Handler
+
+	// Backoff sets the wait between reconnection attempts.
+	Backoff feed.Backoff
+
+	// StaleAfter is how long without a message before the feed is reported as
+	// stale. When zero the feed is never stale.
+	StaleAfter time.Duration
+
+	// Metrics, if set, records the messages, reconnects and checksum failures
+	// of the connection.
+	Metrics feed.Metrics
+
+	// Logger, if set, logs connects, drops and failed subscriptions.
+	Logger *slog.Logger
+
+	// TracerProvider, if set, traces each connection and the handling of
+	// every message.
+	TracerProvider trace.TracerProvider
+
+	mu     sync.Mutex
+	status feed.Status
+}
+
+// New creates a client for the given endpoint using the default backoff.
+func New(name, url string, handler Handler) *Client {
+	return &Client{
+		Name:       name,
+		URL:        url,
+		Handler:    handler,
+		Backoff:    feed.DefaultBackoff(),
+		StaleAfter: 10 * time.Second,
+	}
+}
+
+// Status returns the current health of the connection. It is safe to call
+// while the client is running.
+func (c *Client) Status() feed.Status {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.status
+}
+
+// log returns the client's logger, tagged with its name.
+func (c *Client) log() *slog.Logger {
+	if c.Logger == nil {
+		return discard
+	}
+	return c.Logger.With("source", c.Name)
+}
+
+// tracer returns the client's tracer.
+func (c *Client) tracer() trace.Tracer {
+	if c.TracerProvider == nil {
+		return noop.Tracer{}
+	}
+	return c.TracerProvider.Tracer("github.com/allank/chartea/feed/ws")
+}
+
+// setState records a change of state and reports it to the program.
+func (c *Client) setState(send func(tea.Msg), msg feed.StateMsg) {
+	msg.Source = c.Name
+	c.mu.Lock()
+	c.status.State = msg.State
+	c.status.Since = time.Now()
+	if msg.Err != nil {
+		c.status.Err = msg.Err
+	}
+	c.mu.Unlock()
+	send(msg)
+}
+
+// received records that a message arrived, returning the feed to live if it
+// had gone stale.
+func (c *Client) received(send func(tea.Msg)) {
+	c.mu.Lock()
+	c.status.LastMessage = time.Now()
+	stale := c.status.State == feed.Stale
+	if stale {
+		c.status.State, c.status.Since = feed.Live, c.status.LastMessage
+	}
+	c.mu.Unlock()
+	if stale {
+		send(feed.StateMsg{Source: c.Name, State: feed.Live})
+	}
+}
+
+// watch reports the feed as stale when no message has arrived for StaleAfter,
+// until the context is cancelled.
+func (c *Client) watch(ctx context.Context, send func(tea.Msg)) {
+	if c.StaleAfter <= 0 {
+		return
+	}
+	ticker := time.NewTicker(c.StaleAfter / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Check and change the state together, so a drop can't be overwritten.
+			c.mu.Lock()
+			stale := c.status.State == feed.Live && time.Since(c.status.LastMessage) > c.StaleAfter
+			if stale {
+				c.status.State, c.status.Since = feed.Stale, time.Now()
+			}
+			c.mu.Unlock()
+			if stale {
+				send(feed.StateMsg{Source: c.Name, State: feed.Stale})
+			}
+		}
+	}
+}
+
+// Run connects and subscribes, then passes messages to the handler until the
+// context is cancelled. When the connection drops it waits, backing off
+// exponentially, then reconnects and subscribes again. Changes of state are
+// sent as feed.StateMsgs, and can be read at any time with Status.
+func (c *Client) Run(ctx context.Context, send func(tea.Msg)) error {
+	c.setState(send, feed.StateMsg{State: feed.Connecting})
+	c.log().Info("connecting", "url", c.URL)
+	attempt := 0
+	for {
+		err := c.session(ctx, send, func() { attempt = 0 })
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		delay := c.Backoff.Delay(attempt)
+		attempt++
+		if c.Metrics != nil {
+			c.Metrics.Reconnect(c.Name)
+		}
+		c.log().Warn("connection dropped, reconnecting", "err", err, "attempt", attempt, "delay", delay)
+		c.setState(send, feed.StateMsg{State: feed.Reconnecting, Err: err, Attempt: attempt, Delay: delay})
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// session runs a single connection until it drops or the context is
+// cancelled, calling live once it has subscribed.
+func (c *Client) session(ctx context.Context, send func(tea.Msg), live func()) error {
+	conn, err := c.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.CloseNow()
+	live()
+	c.log().Info("connected")
+	c.mu.Lock()
+	c.status.LastMessage = time.Now()
+	c.mu.Unlock()
+	c.setState(send, feed.StateMsg{State: feed.Live})
+
+	// Watch for the feed going quiet until the connection ends.
+	watchCtx, stopWatch := context.WithCancel(ctx)
+	defer stopWatch()
+	go c.watch(watchCtx, send)
+
+	for {
+		_, data, err := conn.Read(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				conn.Close(websocket.StatusNormalClosure, "")
+			}
+			return err
+		}
+		c.received(send)
+		if c.Metrics != nil {
+			c.Metrics.Message(c.Name)
+		}
+		if err := c.handle(ctx, data, send); err != nil {
+			if c.Metrics != nil && errors.Is(err, feed.ErrChecksum) {
+				c.Metrics.ChecksumFailure(c.Name)
+			}
+			var gap *feed.GapError
+			if errors.As(err, &gap) {
+				send(feed.GapDetectedMsg{Source: c.Name, Channel: gap.Channel, Market: gap.Market, Expected: gap.Expected, Got: gap.Got})
+			}
+			return err
+		}
+	}
+}
+
+// connect dials the endpoint and subscribes, in a span when tracing.
+func (c *Client) connect(ctx context.Context) (_ *websocket.Conn, err error) {
+	spanCtx, span := c.tracer().Start(ctx, "ws.connect", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("chartea.source", c.Name),
+		attribute.String("url.full", c.URL),
+	))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	conn, _, err := websocket.Dial(spanCtx, c.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetReadLimit(readLimit)
+
+	// Managers keep write to subscribe later, so it uses the session's
+	// context rather than the span's.
+	write := func(v any) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		return conn.Write(ctx, websocket.MessageText, data)
+	}
+	if err := c.Handler.Subscribe(spanCtx, write); err != nil {
+		conn.CloseNow()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// handle passes a message to the handler, in a span when tracing.
+func (c *Client) handle(ctx context.Context, data []byte, send func(tea.Msg)) error {
+	_, span := c.tracer().Start(ctx, "ws.handle", trace.WithSpanKind(trace.SpanKindConsumer), trace.WithAttributes(
+		attribute.String("chartea.source", c.Name),
+		attribute.Int("messaging.message.body.size", len(data)),
+	))
+	defer span.End()
+	err := c.Handler.Handle(data, send)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
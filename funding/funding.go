@@ -0,0 +1,146 @@
+package funding
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/allank/chartea/sparkline"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ViewOptions allows you to specify the dimensions of the funding view.
+type ViewOptions struct {
+	Width  int
+	Height int
+}
+
+// UpdateMsg carries new funding information for a perpetual market.
+// Rates are fractions per funding period, e.g. 0.0001 is 0.01%.
+type UpdateMsg struct {
+	Rate        float64
+	Predicted   float64
+	NextFunding time.Time
+}
+
+// Model represents the state of the funding rate component.
+type Model struct {
+	width  int
+	height int
+
+	// Market is the name shown alongside the rates.
+	Market string
+
+	// Rate is the current funding rate, as a fraction per funding period.
+	Rate float64
+	// Predicted is the predicted rate for the next funding period.
+	Predicted float64
+	// NextFunding is when the next funding payment is made. Not shown when zero.
+	NextFunding time.Time
+
+	// History holds previous rates, oldest first, shown as a sparkline.
+	History []float64
+	// Capacity is the number of rates kept in the history, older rates are dropped.
+	Capacity int
+
+	// Precision for the rates, which are shown as percentages.
+	Precision int
+
+	// Styles
+	StyleLabel     lipgloss.Style
+	StylePositive  lipgloss.Style
+	StyleNegative  lipgloss.Style
+	StyleSparkline lipgloss.Style
+}
+
+// New creates a new funding rate model with default styles.
+func New() Model {
+	return Model{
+		Capacity:  500,
+		Precision: 4,
+		StyleLabel: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "240", Dark: "245"}),
+		StylePositive: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("34")),
+		StyleNegative: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("124")),
+		StyleSparkline: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "232", Dark: "188"}),
+	}
+}
+
+// Init initializes the funding rate model.
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages for the funding rate model. Each UpdateMsg replaces
+// the current figures and adds the current rate to the history.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	case UpdateMsg:
+		m.Rate = msg.Rate
+		m.Predicted = msg.Predicted
+		m.NextFunding = msg.NextFunding
+		m.History = append(m.History, msg.Rate)
+		if m.Capacity > 0 && len(m.History) > m.Capacity {
+			m.History = m.History[len(m.History)-m.Capacity:]
+		}
+	}
+	return m, nil
+}
+
+// View renders the funding rates, taking up the full width and height of the model.
+func (m *Model) View() string {
+	if m.width <= 0 {
+		return "Initializing..."
+	}
+	return m.ViewWithOptions(ViewOptions{Width: m.width, Height: m.height})
+}
+
+// ViewWithOptions renders the funding rates with the given options. The first
+// line shows the current and predicted rates, and the time until the next
+// funding payment. If there is room, the history is shown as a sparkline below.
+func (m *Model) ViewWithOptions(opts ViewOptions) string {
+	if opts.Width <= 0 {
+		return "Initializing..."
+	}
+	if opts.Height <= 0 {
+		return ""
+	}
+
+	parts := make([]string, 0, 8)
+	if m.Market != "" {
+		parts = append(parts, m.Market)
+	}
+	parts = append(parts,
+		m.StyleLabel.Render("Funding"), m.renderRate(m.Rate),
+		m.StyleLabel.Render("Predicted"), m.renderRate(m.Predicted),
+	)
+	if !m.NextFunding.IsZero() {
+		next := max(time.Until(m.NextFunding), 0).Truncate(time.Second)
+		parts = append(parts, m.StyleLabel.Render("Next"), next.String())
+	}
+	summary := lipgloss.NewStyle().MaxWidth(opts.Width).Render(strings.Join(parts, " "))
+
+	if opts.Height < 2 || len(m.History) == 0 {
+		return summary
+	}
+	history := m.StyleSparkline.Render(sparkline.Render(m.History, opts.Width))
+	return lipgloss.JoinVertical(lipgloss.Left, summary, history)
+}
+
+// renderRate formats a rate as a signed percentage, coloured by sign.
+func (m *Model) renderRate(rate float64) string {
+	rateFormat := fmt.Sprintf("%%+.%df%%%%", m.Precision)
+	style := m.StylePositive
+	if rate < 0 {
+		style = m.StyleNegative
+	}
+	return style.Render(fmt.Sprintf(rateFormat, rate*100))
+}
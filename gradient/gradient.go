@@ -0,0 +1,77 @@
+package gradient
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Gradient is a color scale made of hex color stops, evenly spaced from the
+// start of the scale to the end.
+type Gradient []lipgloss.Color
+
+// At returns the color at position t along the gradient, where 0 is the first
+// stop and 1 the last. Positions outside of the range are clamped.
+func (g Gradient) At(t float64) lipgloss.Color {
+	switch {
+	case len(g) == 0:
+		return lipgloss.Color("")
+	case len(g) == 1 || math.IsNaN(t):
+		return g[0]
+	}
+	t = math.Max(0, math.Min(1, t))
+	pos := t * float64(len(g)-1)
+	i := min(int(pos), len(g)-2)
+	return Lerp(g[i], g[i+1], pos-float64(i))
+}
+
+// Map returns the color for a value on a scale from low to high. When the
+// scale is empty the middle of the gradient is used.
+func (g Gradient) Map(v, low, high float64) lipgloss.Color {
+	if high <= low {
+		return g.At(0.5)
+	}
+	return g.At((v - low) / (high - low))
+}
+
+// Sample returns n colors evenly spaced along the gradient, e.g. to style each
+// row of a chart.
+func (g Gradient) Sample(n int) []lipgloss.Color {
+	colors := make([]lipgloss.Color, max(n, 0))
+	for i := range colors {
+		t := 0.0
+		if n > 1 {
+			t = float64(i) / float64(n-1)
+		}
+		colors[i] = g.At(t)
+	}
+	return colors
+}
+
+// Lerp linearly interpolates between two hex colors.
+func Lerp(a, b lipgloss.Color, t float64) lipgloss.Color {
+	ar, ag, ab := RGB(a)
+	br, bg, bb := RGB(b)
+	mix := func(x, y int) int {
+		return int(math.Round(float64(x) + (float64(y)-float64(x))*t))
+	}
+	return lipgloss.Color(fmt.Sprintf("#%02x%02x%02x", mix(ar, br), mix(ag, bg), mix(ab, bb)))
+}
+
+// RGB returns the components of a hex color, or black if it can't be parsed.
+func RGB(c lipgloss.Color) (r, g, b int) {
+	if _, err := fmt.Sscanf(string(c), "#%02x%02x%02x", &r, &g, &b); err != nil {
+		return 0, 0, 0
+	}
+	return r, g, b
+}
+
+// Contrast returns a text color that can be read on the given background.
+func Contrast(bg lipgloss.Color) lipgloss.Color {
+	r, g, b := RGB(bg)
+	if 0.299*float64(r)+0.587*float64(g)+0.114*float64(b) > 140 {
+		return lipgloss.Color("#000000")
+	}
+	return lipgloss.Color("#ffffff")
+}
@@ -0,0 +1,155 @@
+package greeks
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ViewOptions allows you to specify the dimensions of the greeks view.
+type ViewOptions struct {
+	Width  int
+	Height int
+}
+
+// Greeks are the sensitivities of an option or position.
+type Greeks struct {
+	Delta float64
+	Gamma float64
+	Vega  float64
+	Theta float64
+}
+
+// UpdateMsg replaces the greeks shown, e.g. when a different option is selected
+// in the options chain or the position is repriced.
+type UpdateMsg struct {
+	Label string
+	Greeks
+}
+
+// Model represents the state of the greeks panel.
+type Model struct {
+	width  int
+	height int
+
+	// Label names the option or position the greeks belong to.
+	Label string
+
+	// Greeks are the values displayed.
+	Greeks
+
+	// Precision for the values.
+	Precision int
+
+	// Styles
+	StyleLabel    lipgloss.Style
+	StyleName     lipgloss.Style
+	StylePositive lipgloss.Style
+	StyleNegative lipgloss.Style
+}
+
+// New creates a new greeks panel with default styles.
+func New() Model {
+	return Model{
+		Precision: 3,
+		StyleLabel: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "232", Dark: "188"}).
+			Bold(true),
+		StyleName: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "240", Dark: "245"}),
+		StylePositive: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("34")),
+		StyleNegative: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("124")),
+	}
+}
+
+// Init initializes the greeks panel.
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages for the greeks panel.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	case UpdateMsg:
+		m.Label = msg.Label
+		m.Greeks = msg.Greeks
+	}
+	return m, nil
+}
+
+// View renders the panel, taking up the full width and height of the model.
+func (m *Model) View() string {
+	if m.width <= 0 {
+		return "Initializing..."
+	}
+	return m.ViewWithOptions(ViewOptions{Width: m.width, Height: m.height})
+}
+
+// ViewWithOptions renders the panel with the given options. The greeks are
+// laid out on as few lines as fit the width, using their full names if there
+// is room and their symbols if not. The label is shown above them when there
+// is a spare line.
+func (m *Model) ViewWithOptions(opts ViewOptions) string {
+	if opts.Width <= 0 {
+		return "Initializing..."
+	}
+	if opts.Height <= 0 {
+		return ""
+	}
+
+	valueFormat := fmt.Sprintf("%%+.%df", m.Precision)
+	values := []float64{m.Delta, m.Gamma, m.Vega, m.Theta}
+	names := []string{"Delta", "Gamma", "Vega", "Theta"}
+	symbols := []string{"Δ", "Γ", "ν", "Θ"}
+
+	// Prefer the full names, falling back to the symbols if the greeks don't fit on the available lines.
+	lines := m.wrap(names, values, valueFormat, opts.Width)
+	if len(lines) > opts.Height {
+		lines = m.wrap(symbols, values, valueFormat, opts.Width)
+	}
+	if m.Label != "" && len(lines) < opts.Height {
+		label := lipgloss.NewStyle().MaxWidth(opts.Width).Render(m.StyleLabel.Render(m.Label))
+		lines = append([]string{label}, lines...)
+	}
+	if len(lines) > opts.Height {
+		lines = lines[:opts.Height]
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// wrap lays out the greeks on as many lines as are needed to fit the width.
+func (m *Model) wrap(names []string, values []float64, valueFormat string, width int) []string {
+	var lines []string
+	var line strings.Builder
+	lineWidth := 0
+	for i, name := range names {
+		value := fmt.Sprintf(valueFormat, values[i])
+		itemWidth := lipgloss.Width(name) + 1 + len(value)
+
+		style := m.StylePositive
+		if values[i] < 0 {
+			style = m.StyleNegative
+		}
+		item := m.StyleName.Render(name) + " " + style.Render(value)
+
+		if lineWidth > 0 && lineWidth+2+itemWidth > width {
+			lines = append(lines, line.String())
+			line.Reset()
+			lineWidth = 0
+		}
+		if lineWidth > 0 {
+			line.WriteString("  ")
+			lineWidth += 2
+		}
+		line.WriteString(item)
+		lineWidth += itemWidth
+	}
+	return append(lines, line.String())
+}
@@ -0,0 +1,277 @@
+package heatmap
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/allank/chartea/canvas/block"
+	"github.com/allank/chartea/gradient"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxValueWidth is the widest a cell's value is shown. Longer values are cut
+// short with an ellipsis.
+const maxValueWidth = 12
+
+// ViewOptions allows you to specify the dimensions of the heatmap view.
+type ViewOptions struct {
+	Width  int
+	Height int
+}
+
+// Model represents the state of the heatmap component.
+type Model struct {
+	width  int
+	height int
+
+	// Labels name the rows and columns of the matrix.
+	Labels []string
+
+	// Values is the matrix of values, Values[row][column].
+	Values [][]float64
+
+	// Min and Max are the values mapped to the ends of the color scale.
+	// When they are equal, the range of the values is used.
+	Min float64
+	Max float64
+
+	// Precision for the cell values.
+	Precision int
+
+	// Compact draws each cell as a pixel of its color, two rows of the matrix
+	// to a line, without the values or labels, to fit large matrices.
+	Compact bool
+
+	// Colors for the low, middle and high ends of the scale, as hex colors.
+	ColorLow  lipgloss.Color
+	ColorMid  lipgloss.Color
+	ColorHigh lipgloss.Color
+
+	// Styles
+	StyleLabel lipgloss.Style
+}
+
+// New creates a new heatmap model with default styles, scaled for correlations between -1 and 1.
+func New() Model {
+	return Model{
+		Min:       -1,
+		Max:       1,
+		Precision: 2,
+		ColorLow:  lipgloss.Color("#af0000"),
+		ColorMid:  lipgloss.Color("#303030"),
+		ColorHigh: lipgloss.Color("#00af00"),
+		StyleLabel: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "240", Dark: "245"}),
+	}
+}
+
+// Init initializes the heatmap model.
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages for the heatmap model.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	}
+	return m, nil
+}
+
+// View renders the heatmap, taking up the full width and height of the model.
+func (m *Model) View() string {
+	if m.width <= 0 {
+		return "Initializing..."
+	}
+	return m.ViewWithOptions(ViewOptions{Width: m.width, Height: m.height})
+}
+
+// ViewWithOptions renders the heatmap with the given options. Row labels are
+// shown on the left and column labels along the top, with each cell colored by
+// its value and showing the value. Rows and columns that don't fit are dropped.
+func (m *Model) ViewWithOptions(opts ViewOptions) string {
+	if opts.Width <= 0 {
+		return "Initializing..."
+	}
+	if opts.Height <= 1 || len(m.Values) == 0 {
+		return ""
+	}
+
+	low, high := m.Min, m.Max
+	if low == high {
+		low, high = m.valueRange()
+	}
+	if m.Compact {
+		return m.renderCompact(opts, low, high)
+	}
+
+	// Size the cells on the ends of the scale and the values shown, up to
+	// maxValueWidth, as values can lie outside the scale.
+	rows := min(len(m.Values), opts.Height-1)
+	valueFormat := fmt.Sprintf("%%.%df", m.Precision)
+	valueWidth := max(len(fmt.Sprintf(valueFormat, low)), len(fmt.Sprintf(valueFormat, high)))
+	for _, row := range m.Values[:rows] {
+		for _, v := range row {
+			if !math.IsNaN(v) {
+				valueWidth = max(valueWidth, len(fmt.Sprintf(valueFormat, v)))
+			}
+		}
+	}
+	valueWidth = min(valueWidth, maxValueWidth)
+	cellWidth := valueWidth + 2
+	labelWidth := 0
+	for _, l := range m.Labels {
+		labelWidth = max(labelWidth, lipgloss.Width(l))
+	}
+	labelWidth++
+
+	columns := 0
+	for _, row := range m.Values {
+		columns = max(columns, len(row))
+	}
+	columns = min(columns, (opts.Width-labelWidth)/cellWidth)
+	if columns <= 0 {
+		return ""
+	}
+
+	lines := make([]string, 0, rows+1)
+
+	// Column labels, truncated to the cell width.
+	var header strings.Builder
+	header.WriteString(strings.Repeat(" ", labelWidth))
+	for c := 0; c < columns; c++ {
+		header.WriteString(lipgloss.PlaceHorizontal(cellWidth, lipgloss.Center, truncate(m.label(c), cellWidth-1)))
+	}
+	lines = append(lines, m.StyleLabel.Render(header.String()))
+
+	for r := 0; r < rows; r++ {
+		var sb strings.Builder
+		sb.WriteString(m.StyleLabel.Render(fmt.Sprintf("%-*s", labelWidth, truncate(m.label(r), labelWidth-1))))
+		for c := 0; c < columns; c++ {
+			if c >= len(m.Values[r]) || math.IsNaN(m.Values[r][c]) {
+				sb.WriteString(strings.Repeat(" ", cellWidth))
+				continue
+			}
+			v := m.Values[r][c]
+			bg := m.color(v, low, high)
+			style := lipgloss.NewStyle().
+				Background(bg).
+				Foreground(gradient.Contrast(bg)).
+				Width(cellWidth).
+				Align(lipgloss.Center)
+			sb.WriteString(style.Render(shorten(fmt.Sprintf(valueFormat, v), valueWidth)))
+		}
+		lines = append(lines, sb.String())
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// renderCompact renders the matrix on a block canvas, each cell a pixel of
+// its color. Rows and columns that don't fit are dropped.
+func (m *Model) renderCompact(opts ViewOptions, low, high float64) string {
+	columns := 0
+	for _, row := range m.Values {
+		columns = max(columns, len(row))
+	}
+	c := block.New(min(columns, opts.Width), min((len(m.Values)+1)/2, opts.Height), block.Half)
+	for r, row := range m.Values {
+		for col, v := range row {
+			if !math.IsNaN(v) {
+				c.SetPixel(col, r, m.color(v, low, high))
+			}
+		}
+	}
+	return c.Render()
+}
+
+// label returns the label for a row or column, or its index if there isn't one.
+func (m *Model) label(i int) string {
+	if i < len(m.Labels) {
+		return m.Labels[i]
+	}
+	return fmt.Sprint(i + 1)
+}
+
+// valueRange returns the smallest and largest values in the matrix.
+func (m *Model) valueRange() (float64, float64) {
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, row := range m.Values {
+		for _, v := range row {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				continue
+			}
+			low = math.Min(low, v)
+			high = math.Max(high, v)
+		}
+	}
+	if low > high {
+		return 0, 0
+	}
+	return low, high
+}
+
+// color maps a value onto the color scale, through the middle color.
+func (m *Model) color(v, low, high float64) lipgloss.Color {
+	return gradient.Gradient{m.ColorLow, m.ColorMid, m.ColorHigh}.Map(v, low, high)
+}
+
+// shorten shortens a value to fit the given width, ending it with an ellipsis
+// when it doesn't.
+func shorten(s string, width int) string {
+	if len(s) <= width {
+		return s
+	}
+	return s[:max(width-1, 0)] + "…"
+}
+
+// truncate shortens a label to fit the given width.
+func truncate(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	runes := []rune(s)
+	if len(runes) > width {
+		return string(runes[:width])
+	}
+	return s
+}
+
+// Correlate returns the Pearson correlation matrix of the given series, e.g.
+// the returns of a set of symbols. Series are compared over the length of the
+// shorter one, and pairs that can't be correlated are NaN.
+func Correlate(series [][]float64) [][]float64 {
+	matrix := make([][]float64, len(series))
+	for i := range series {
+		matrix[i] = make([]float64, len(series))
+		for j := range series {
+			matrix[i][j] = pearson(series[i], series[j])
+		}
+	}
+	return matrix
+}
+
+// pearson returns the correlation of two series, skipping pairs containing NaN.
+func pearson(a, b []float64) float64 {
+	var n, sumA, sumB, sumAB, sumAA, sumBB float64
+	for i := 0; i < min(len(a), len(b)); i++ {
+		if math.IsNaN(a[i]) || math.IsNaN(b[i]) {
+			continue
+		}
+		n++
+		sumA += a[i]
+		sumB += b[i]
+		sumAB += a[i] * b[i]
+		sumAA += a[i] * a[i]
+		sumBB += b[i] * b[i]
+	}
+	denominator := math.Sqrt(n*sumAA-sumA*sumA) * math.Sqrt(n*sumBB-sumB*sumB)
+	if n < 2 || denominator == 0 {
+		return math.NaN()
+	}
+	return (n*sumAB - sumA*sumB) / denominator
+}
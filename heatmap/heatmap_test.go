@@ -0,0 +1,31 @@
+package heatmap
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// TestViewValuesOutsideScale checks that values outside Min and Max, which
+// are wider than the ends of the scale, keep to their cells rather than
+// wrapping onto a line of their own.
+func TestViewValuesOutsideScale(t *testing.T) {
+	for _, v := range []float64{123456, -1e300, math.Inf(1)} {
+		m := New()
+		m.Min, m.Max = 0, 1
+		m.Labels = []string{"a", "b"}
+		m.Values = [][]float64{{0.5, v}, {v, 0.25}}
+		out := m.ViewWithOptions(ViewOptions{Width: 60, Height: 10})
+		lines := strings.Split(out, "\n")
+		if len(lines) != 3 {
+			t.Fatalf("%v: got %d lines, want 3:\n%s", v, len(lines), ansi.Strip(out))
+		}
+		for i, line := range lines {
+			if w := ansi.StringWidth(line); w != ansi.StringWidth(lines[0]) {
+				t.Errorf("%v: line %d is %d wide, want %d:\n%s", v, i, w, ansi.StringWidth(lines[0]), ansi.Strip(out))
+			}
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package histogram
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/allank/chartea/axis"
+	"github.com/allank/chartea/canvas/block"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// ViewOptions allows you to specify the dimensions of the histogram view.
+type ViewOptions struct {
+	Width  int
+	Height int
+}
+
+// Model represents the state of the histogram component.
+type Model struct {
+	width  int
+	height int
+
+	// Values are the observations to be binned.
+	Values []float64
+
+	// Bins is the number of bins. When zero, one bin is used per column.
+	Bins int
+
+	// Precision for the x-axis labels.
+	Precision int
+
+	// Styles
+	StyleBar  lipgloss.Style
+	StyleAxis lipgloss.Style
+}
+
+// New creates a new histogram model with default styles.
+func New() Model {
+	return Model{
+		Precision: 2,
+		StyleBar: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("34")),
+		StyleAxis: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "240", Dark: "245"}),
+	}
+}
+
+// Init initializes the histogram model.
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages for the histogram model.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	}
+	return m, nil
+}
+
+// View renders the histogram, taking up the full width and height of the model.
+func (m *Model) View() string {
+	if m.width <= 0 {
+		return "Initializing..."
+	}
+	return m.ViewWithOptions(ViewOptions{Width: m.width, Height: m.height})
+}
+
+// ViewWithOptions renders the histogram with the given options. Counts are
+// labelled on the left, and the range of the values along the bottom.
+func (m *Model) ViewWithOptions(opts ViewOptions) string {
+	if opts.Width <= 0 {
+		return "Initializing..."
+	}
+	// Leave a row for the x-axis labels.
+	plotHeight := opts.Height - 1
+	if plotHeight <= 0 || len(m.Values) == 0 {
+		return ""
+	}
+
+	// The count labels depend on the bins, which depend on the plot width, so
+	// size the gutter for the largest possible count.
+	gutter := len(fmt.Sprint(len(m.Values))) + 1
+	plotWidth := opts.Width - gutter
+	if plotWidth <= 0 {
+		return ""
+	}
+
+	bins := m.Bins
+	if bins <= 0 || bins > plotWidth {
+		bins = plotWidth
+	}
+	counts, low, high := Bin(m.Values, bins)
+	maxCount := 0
+	for _, c := range counts {
+		maxCount = max(maxCount, c)
+	}
+	barWidth := plotWidth / bins
+
+	rows := make([]string, 0, opts.Height)
+	for y := 0; y < plotHeight; y++ {
+		floor := block.Floor(y, plotHeight)
+		var sb strings.Builder
+		for _, c := range counts {
+			eighths := 0
+			if maxCount > 0 {
+				eighths = int(math.Round(float64(c) / float64(maxCount) * float64(plotHeight*8)))
+			}
+			sb.WriteString(strings.Repeat(string(block.Eighth(eighths-floor)), barWidth))
+		}
+		sb.WriteString(strings.Repeat(" ", plotWidth-barWidth*bins))
+
+		label := ""
+		switch y {
+		case 0:
+			label = fmt.Sprint(maxCount)
+		case plotHeight - 1:
+			label = "0"
+		}
+		rows = append(rows, m.StyleAxis.Render(fmt.Sprintf("%*s ", gutter-1, label))+m.StyleBar.Render(sb.String()))
+	}
+
+	// Label the ends of the x-axis.
+	labelFormat := fmt.Sprintf("%%.%df", m.Precision)
+	xLeft, xRight := fmt.Sprintf(labelFormat, low), fmt.Sprintf(labelFormat, high)
+	xLabels := xLeft
+	if padding := plotWidth - len(xLeft) - len(xRight); padding > 0 {
+		xLabels += strings.Repeat(" ", padding) + xRight
+	}
+	rows = append(rows, m.StyleAxis.Render(strings.Repeat(" ", gutter)+ansi.Truncate(xLabels, plotWidth, "…")))
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+// Bin counts the values into the given number of equal width bins spanning
+// their range, ignoring NaN and Inf. It returns the counts along with the low
+// and high edges of the range.
+func Bin(values []float64, bins int) (counts []int, low, high float64) {
+	if bins <= 0 {
+		return nil, 0, 0
+	}
+	low, high = math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		low = math.Min(low, v)
+		high = math.Max(high, v)
+	}
+	counts = make([]int, bins)
+	if low > high {
+		return counts, 0, 0
+	}
+
+	for _, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		i := 0
+		if high > low {
+			i = int(axis.Scale{Min: low, Max: high}.Normalize(v) * float64(bins))
+		}
+		// The highest value belongs in the last bin.
+		counts[min(max(i, 0), bins-1)]++
+	}
+	return counts, low, high
+}
@@ -0,0 +1,197 @@
+package imbalance
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/allank/chartea/clob"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ViewOptions allows you to specify the dimensions of the imbalance view.
+type ViewOptions struct {
+	Width  int
+	Height int
+}
+
+// Model represents the state of the order flow imbalance chart.
+type Model struct {
+	width  int
+	height int
+
+	// Values are the imbalance readings, oldest first, each between -1 (all asks) and 1 (all bids).
+	Values []float64
+
+	// Levels is the number of levels from the top of each side used to calculate the imbalance.
+	Levels int
+
+	// Capacity is the number of readings kept, older readings are dropped.
+	Capacity int
+
+	// Precision for the axis labels.
+	Precision int
+
+	// Styles
+	StyleBid  lipgloss.Style
+	StyleAsk  lipgloss.Style
+	StyleAxis lipgloss.Style
+}
+
+// New creates a new imbalance chart model with default styles, using the top 5 levels.
+func New() Model {
+	return Model{
+		Levels:    5,
+		Capacity:  500,
+		Precision: 2,
+		StyleBid: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("34")),
+		StyleAsk: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("124")),
+		StyleAxis: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "240", Dark: "245"}),
+	}
+}
+
+// Push calculates the imbalance of the given book snapshot and adds it to the chart.
+func (m *Model) Push(book clob.OrderBook) {
+	m.Values = append(m.Values, Imbalance(book, m.Levels))
+	if m.Capacity > 0 && len(m.Values) > m.Capacity {
+		m.Values = m.Values[len(m.Values)-m.Capacity:]
+	}
+}
+
+// Init initializes the imbalance chart model.
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages for the imbalance chart model.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	}
+	return m, nil
+}
+
+// View renders the chart, taking up the full width and height of the model.
+func (m *Model) View() string {
+	if m.width <= 0 {
+		return "Initializing..."
+	}
+	return m.ViewWithOptions(ViewOptions{Width: m.width, Height: m.height})
+}
+
+// ViewWithOptions renders the chart with the given options. Each reading is a
+// column, with bid-heavy readings drawn up from the centre line and ask-heavy
+// readings drawn down from it.
+func (m *Model) ViewWithOptions(opts ViewOptions) string {
+	if opts.Width <= 0 {
+		return "Initializing..."
+	}
+	if opts.Height <= 0 {
+		return ""
+	}
+
+	labelFormat := fmt.Sprintf("%%+.%df", m.Precision)
+	labelWidth := len(fmt.Sprintf(labelFormat, -1.0))
+	plotWidth := opts.Width - labelWidth - 1
+	if plotWidth <= 0 {
+		return ""
+	}
+
+	// Show the most recent readings that fit.
+	values := m.Values
+	if len(values) > plotWidth {
+		values = values[len(values)-plotWidth:]
+	}
+
+	// Each half of the chart is measured in half cells.
+	half := float64(opts.Height) / 2
+	centre := opts.Height / 2
+
+	rows := make([]string, 0, opts.Height)
+	for y := 0; y < opts.Height; y++ {
+		var sb strings.Builder
+		sb.WriteString(strings.Repeat(" ", plotWidth-len(values)))
+		for _, v := range values {
+			sb.WriteString(m.cell(v, y, centre, half))
+		}
+		label := ""
+		switch y {
+		case 0:
+			label = fmt.Sprintf(labelFormat, 1.0)
+		case centre:
+			label = fmt.Sprintf(labelFormat, 0.0)
+		case opts.Height - 1:
+			label = fmt.Sprintf(labelFormat, -1.0)
+		}
+		sb.WriteString(m.StyleAxis.Render(fmt.Sprintf(" %*s", labelWidth, label)))
+		rows = append(rows, sb.String())
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+// cell renders row y of the column for a single reading.
+func (m *Model) cell(v float64, y, centre int, half float64) string {
+	if math.IsNaN(v) {
+		return " "
+	}
+	// Length of the bar in half cells.
+	length := int(math.Round(math.Abs(v) * half * 2))
+	if v >= 0 {
+		// Bars grow up from the bottom of the row above the centre.
+		filled := (centre-1-y)*2 + 2
+		switch {
+		case y >= centre || length <= filled-2:
+			return " "
+		case length == filled-1:
+			return m.StyleBid.Render("▄")
+		default:
+			return m.StyleBid.Render("█")
+		}
+	}
+	// Bars grow down from the top of the centre row.
+	filled := (y-centre)*2 + 2
+	switch {
+	case y < centre || length <= filled-2:
+		return " "
+	case length == filled-1:
+		return m.StyleAsk.Render("▀")
+	default:
+		return m.StyleAsk.Render("█")
+	}
+}
+
+// Imbalance returns the volume imbalance over the top levels of the book,
+// (bid volume - ask volume) / (bid volume + ask volume). Returns 0 for an empty book.
+func Imbalance(book clob.OrderBook, levels int) float64 {
+	bids := topOfBook(book.Bids, levels, func(a, b float64) bool { return a > b })
+	asks := topOfBook(book.Asks, levels, func(a, b float64) bool { return a < b })
+	total := bids + asks
+	if total == 0 {
+		return 0
+	}
+	return (bids - asks) / total
+}
+
+// topOfBook returns the total volume of the best levels, without reordering the book.
+func topOfBook(orders []clob.Order, levels int, better func(a, b float64) bool) float64 {
+	sorted := append([]clob.Order(nil), orders...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return better(sorted[i].Price, sorted[j].Price)
+	})
+	if levels > 0 && len(sorted) > levels {
+		sorted = sorted[:levels]
+	}
+	volume := 0.0
+	for _, o := range sorted {
+		volume += o.Volume
+	}
+	return volume
+}
@@ -0,0 +1,113 @@
+package indicator
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Indicator is a study computed from a chart's values, such as a moving
+// average of the closes of a candlestick chart, drawn over the chart while
+// enabled.
+type Indicator struct {
+	// Name identifies the indicator, e.g. "SMA 20".
+	Name string
+
+	// Compute returns the value of the indicator at each of the values, NaN
+	// where it has none.
+	Compute func(values []float64) []float64
+
+	// Series is the name of the series a line chart computes the indicator
+	// from, its first series when empty. Candlestick charts compute
+	// indicators from the closes of their candles.
+	Series string
+
+	// Enabled draws the indicator. It's toggled by the chart's key map.
+	Enabled bool
+
+	// Style is used to draw the indicator.
+	Style lipgloss.Style
+}
+
+// SMA returns an enabled simple moving average of the given period.
+func SMA(period int) Indicator {
+	return Indicator{
+		Name:    fmt.Sprintf("SMA %d", period),
+		Compute: func(values []float64) []float64 { return MovingAverage(values, period) },
+		Enabled: true,
+		Style: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("33")),
+	}
+}
+
+// MovingAverage returns the mean of each value and the period-1 values
+// before it, NaN for the first values and wherever the window holds a value
+// that isn't a finite number.
+func MovingAverage(values []float64, period int) []float64 {
+	out := make([]float64, len(values))
+	sum, bad := 0.0, 0
+	for i, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			bad++
+		} else {
+			sum += v
+		}
+		if j := i - period; j >= 0 {
+			if old := values[j]; math.IsNaN(old) || math.IsInf(old, 0) {
+				bad--
+			} else {
+				sum -= old
+			}
+		}
+		out[i] = math.NaN()
+		if period > 0 && i >= period-1 && bad == 0 {
+			out[i] = sum / float64(period)
+		}
+	}
+	return out
+}
+
+// ToggleMsg is sent when an indicator or pane of a chart is switched on or
+// off from the keyboard, so the host app can save the change to its config.
+type ToggleMsg struct {
+	// Name is the name of the indicator, or of the pane, e.g. "Volume".
+	Name    string
+	Enabled bool
+}
+
+// DefaultKeys returns bindings for the keys 1 to 9, toggling the first nine
+// indicators of a chart in order.
+func DefaultKeys() []key.Binding {
+	keys := make([]key.Binding, 9)
+	for i := range keys {
+		k := strconv.Itoa(i + 1)
+		keys[i] = key.NewBinding(key.WithKeys(k), key.WithHelp(k, "toggle indicator "+k))
+	}
+	return keys
+}
+
+// Toggle switches the indicator whose binding in keys matches a key press,
+// returning a copy of the indicators with the change and the command
+// reporting it. If no binding matches, the indicators are returned as they
+// are with a nil command.
+func Toggle(indicators []Indicator, keys []key.Binding, msg tea.KeyMsg) ([]Indicator, tea.Cmd) {
+	for i, k := range keys {
+		if i < len(indicators) && key.Matches(msg, k) {
+			// Copy the indicators, as models are updated by value.
+			indicators = slices.Clone(indicators)
+			indicators[i].Enabled = !indicators[i].Enabled
+			return indicators, Toggled(indicators[i].Name, indicators[i].Enabled)
+		}
+	}
+	return indicators, nil
+}
+
+// Toggled returns the command sending a ToggleMsg.
+func Toggled(name string, enabled bool) tea.Cmd {
+	return func() tea.Msg { return ToggleMsg{Name: name, Enabled: enabled} }
+}
@@ -0,0 +1,248 @@
+package kagi
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/allank/chartea/axis"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ViewOptions allows you to specify the dimensions of the Kagi view.
+type ViewOptions struct {
+	Width  int
+	Height int
+}
+
+// Line is a single vertical Kagi line, running from Start to End.
+// Thick is the thickness at Start. If the line crosses the previous shoulder
+// or waist it switches thickness at Switch, otherwise Switch is NaN.
+type Line struct {
+	Start  float64
+	End    float64
+	Thick  bool
+	Switch float64
+}
+
+// Model represents the state of the Kagi chart component.
+type Model struct {
+	width  int
+	height int
+
+	// Prices are the closing prices the chart is built from, oldest first.
+	Prices []float64
+
+	// Reversal is how far price must move against the current line to start a new one.
+	Reversal float64
+	// ReversalPercent treats Reversal as a percentage of price rather than an absolute amount.
+	ReversalPercent bool
+
+	// PricePrecision for the price labels.
+	PricePrecision int
+
+	// Styles
+	StyleThick lipgloss.Style
+	StyleThin  lipgloss.Style
+	StyleAxis  lipgloss.Style
+}
+
+// New creates a new Kagi model with default styles and a 4% reversal.
+func New() Model {
+	return Model{
+		Reversal:        4,
+		ReversalPercent: true,
+		PricePrecision:  2,
+		StyleThick: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("34")),
+		StyleThin: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("124")),
+		StyleAxis: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "240", Dark: "245"}),
+	}
+}
+
+// Init initializes the Kagi model.
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages for the Kagi model.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	}
+	return m, nil
+}
+
+// View renders the chart, taking up the full width and height of the model.
+func (m *Model) View() string {
+	if m.width <= 0 {
+		return "Initializing..."
+	}
+	return m.ViewWithOptions(ViewOptions{Width: m.width, Height: m.height})
+}
+
+// ViewWithOptions renders the chart with the given options.
+func (m *Model) ViewWithOptions(opts ViewOptions) string {
+	if opts.Width <= 0 {
+		return "Initializing..."
+	}
+	lines := Lines(m.Prices, m.Reversal, m.ReversalPercent)
+	if len(lines) == 0 || opts.Height <= 0 {
+		return ""
+	}
+
+	// Size the price labels on the full range of the chart.
+	var prices []float64
+	for _, l := range lines {
+		prices = append(prices, l.Start, l.End)
+	}
+	scale := axis.Fit(axis.Linear, prices)
+	priceFormat := fmt.Sprintf("%%.%df", m.PricePrecision)
+	labelWidth := max(len(fmt.Sprintf(priceFormat, scale.Min)), len(fmt.Sprintf(priceFormat, scale.Max)))
+
+	// Each line takes a column, with a column between lines for the horizontal joins.
+	// Show the most recent lines that fit alongside the labels.
+	plotWidth := opts.Width - labelWidth - 1
+	if plotWidth <= 0 {
+		return ""
+	}
+	if visible := (plotWidth + 1) / 2; len(lines) > visible {
+		lines = lines[len(lines)-visible:]
+	}
+	prices = prices[:0]
+	for _, l := range lines {
+		prices = append(prices, l.Start, l.End)
+	}
+	scale = axis.Fit(axis.Linear, prices)
+	row := func(p float64) int {
+		return int(math.Round((1 - scale.Normalize(p)) * float64(opts.Height-1)))
+	}
+
+	// Draw the lines into a grid of cells.
+	grid := make([][]string, opts.Height)
+	for y := range grid {
+		grid[y] = make([]string, plotWidth)
+		for x := range grid[y] {
+			grid[y][x] = " "
+		}
+	}
+	for i, l := range lines {
+		x := i * 2
+		top, bottom := row(math.Max(l.Start, l.End)), row(math.Min(l.Start, l.End))
+		for y := top; y <= bottom; y++ {
+			thick := l.Thick
+			if !math.IsNaN(l.Switch) && m.pastSwitch(l, scale, y, opts.Height) {
+				thick = !thick
+			}
+			grid[y][x] = m.glyph("┃", "│", thick)
+		}
+		// Join the end of this line to the start of the next.
+		if i+1 < len(lines) && x+1 < plotWidth {
+			grid[row(l.End)][x+1] = m.glyph("━", "─", lines[i+1].Thick)
+		}
+	}
+
+	rows := make([]string, 0, opts.Height)
+	for y, cells := range grid {
+		label := ""
+		switch y {
+		case 0:
+			label = fmt.Sprintf(priceFormat, scale.Max)
+		case opts.Height - 1:
+			label = fmt.Sprintf(priceFormat, scale.Min)
+		}
+		rows = append(rows, strings.Join(cells, "")+m.StyleAxis.Render(fmt.Sprintf(" %*s", labelWidth, label)))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+// pastSwitch reports whether the given row of a line lies beyond its thickness switch.
+func (m *Model) pastSwitch(l Line, scale axis.Scale, y int, height int) bool {
+	switchRow := int(math.Round((1 - scale.Normalize(l.Switch)) * float64(height-1)))
+	if l.End > l.Start {
+		return y < switchRow
+	}
+	return y > switchRow
+}
+
+// glyph renders the thick or thin variant of a line segment.
+func (m *Model) glyph(thick, thin string, isThick bool) string {
+	if isThick {
+		return m.StyleThick.Render(thick)
+	}
+	return m.StyleThin.Render(thin)
+}
+
+// Lines builds the Kagi lines for the given closing prices. A line is extended
+// while price keeps moving in its direction, and a new line is started once
+// price reverses by at least the reversal amount. Lines turn thick when price
+// rises above the previous shoulder, and thin when it falls below the previous waist.
+func Lines(prices []float64, reversal float64, percent bool) []Line {
+	if reversal <= 0 {
+		return nil
+	}
+
+	var lines []Line
+	start := math.NaN()
+	shoulder, waist := math.NaN(), math.NaN()
+	for _, p := range prices {
+		if math.IsNaN(p) || math.IsInf(p, 0) {
+			continue
+		}
+		if math.IsNaN(start) {
+			start = p
+			continue
+		}
+		threshold := reversal
+		if percent {
+			threshold = math.Abs(p) * reversal / 100
+		}
+
+		if len(lines) == 0 {
+			// The first line starts once price has moved by the reversal amount.
+			if math.Abs(p-start) >= threshold {
+				lines = append(lines, Line{Start: start, End: p, Thick: p > start, Switch: math.NaN()})
+			}
+			continue
+		}
+
+		l := &lines[len(lines)-1]
+		up := l.End > l.Start
+		switch {
+		case up && p > l.End, !up && p < l.End:
+			l.End = p
+		case math.Abs(p-l.End) >= threshold:
+			// Reverse, remembering the turning point as a shoulder or waist.
+			if up {
+				shoulder = l.End
+			} else {
+				waist = l.End
+			}
+			thick := l.Thick
+			if !math.IsNaN(l.Switch) {
+				thick = !thick
+			}
+			lines = append(lines, Line{Start: l.End, End: p, Thick: thick, Switch: math.NaN()})
+		default:
+			continue
+		}
+
+		// Switch thickness if the line has crossed the previous shoulder or waist.
+		l = &lines[len(lines)-1]
+		if math.IsNaN(l.Switch) {
+			switch {
+			case l.End > l.Start && !l.Thick && !math.IsNaN(shoulder) && l.End > shoulder:
+				l.Switch = shoulder
+			case l.End < l.Start && l.Thick && !math.IsNaN(waist) && l.End < waist:
+				l.Switch = waist
+			}
+		}
+	}
+	return lines
+}
@@ -0,0 +1,144 @@
+package legend
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Corner defines which corner of a chart the legend is anchored to.
+type Corner int
+
+const (
+	// TopLeft anchors the legend to the top left corner of the chart.
+	TopLeft Corner = iota
+	// TopRight anchors the legend to the top right corner of the chart.
+	TopRight
+	// BottomLeft anchors the legend to the bottom left corner of the chart.
+	BottomLeft
+	// BottomRight anchors the legend to the bottom right corner of the chart.
+	BottomRight
+)
+
+// Entry represents a single plotted series or indicator in the legend.
+type Entry struct {
+	// Label is the name of the series.
+	Label string
+	// Style is the style used to draw the series, the marker is rendered with it.
+	Style lipgloss.Style
+	// Value is the latest value of the series.
+	Value float64
+	// HideValue omits the value for this entry, e.g. for series with no data yet.
+	HideValue bool
+}
+
+// Model represents the state of the legend.
+type Model struct {
+	// Entries are the series listed in the legend, in display order.
+	Entries []Entry
+
+	// Corner determines which corner of the chart the legend is placed in.
+	Corner Corner
+
+	// Marker is the glyph drawn in the series style before each label.
+	Marker string
+
+	// Precision for the latest values.
+	Precision int
+
+	// Suffix is appended to each value, e.g. "%".
+	Suffix string
+
+	// Styles
+	StyleLabel lipgloss.Style
+	StyleValue lipgloss.Style
+	StyleBox   lipgloss.Style
+}
+
+// New creates a new legend with default styles.
+func New() Model {
+	return Model{
+		Corner:    TopLeft,
+		Marker:    "■",
+		Precision: 2,
+		StyleLabel: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "232", Dark: "188"}),
+		StyleValue: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "236", Dark: "250"}),
+		StyleBox: lipgloss.NewStyle().
+			Padding(0, 1),
+	}
+}
+
+// View renders the legend as a standalone block.
+func (m Model) View() string {
+	if len(m.Entries) == 0 {
+		return ""
+	}
+	valueFormat := fmt.Sprintf("%%.%df", m.Precision) + strings.ReplaceAll(m.Suffix, "%", "%%")
+
+	// Find the widest label and value so the values line up in a column.
+	labelWidth, valueWidth := 0, 0
+	for _, e := range m.Entries {
+		labelWidth = max(labelWidth, lipgloss.Width(e.Label))
+		if !e.HideValue {
+			valueWidth = max(valueWidth, len(fmt.Sprintf(valueFormat, e.Value)))
+		}
+	}
+
+	rows := make([]string, 0, len(m.Entries))
+	for _, e := range m.Entries {
+		label := e.Label + strings.Repeat(" ", labelWidth-lipgloss.Width(e.Label))
+		row := e.Style.Render(m.Marker) + " " + m.StyleLabel.Render(label)
+		if valueWidth > 0 {
+			value := ""
+			if !e.HideValue {
+				value = fmt.Sprintf(valueFormat, e.Value)
+			}
+			row += " " + m.StyleValue.Render(fmt.Sprintf("%*s", valueWidth, value))
+		}
+		rows = append(rows, row)
+	}
+	return m.StyleBox.Render(lipgloss.JoinVertical(lipgloss.Left, rows...))
+}
+
+// Overlay renders the legend on top of the given chart, in the configured corner.
+// The chart is left untouched where the legend does not cover it.
+func (m Model) Overlay(chart string) string {
+	box := m.View()
+	if box == "" {
+		return chart
+	}
+
+	chartLines := strings.Split(chart, "\n")
+	boxLines := strings.Split(box, "\n")
+	chartWidth := lipgloss.Width(chart)
+	boxWidth := lipgloss.Width(box)
+
+	// The legend can't be drawn if the chart is smaller than it.
+	if boxWidth > chartWidth || len(boxLines) > len(chartLines) {
+		return chart
+	}
+
+	top := 0
+	if m.Corner == BottomLeft || m.Corner == BottomRight {
+		top = len(chartLines) - len(boxLines)
+	}
+	left := 0
+	if m.Corner == TopRight || m.Corner == BottomRight {
+		left = chartWidth - boxWidth
+	}
+
+	for i, boxLine := range boxLines {
+		line := chartLines[top+i]
+		// Pad short lines so the legend lands in the right column.
+		if w := lipgloss.Width(line); w < chartWidth {
+			line += strings.Repeat(" ", chartWidth-w)
+		}
+		boxLine += strings.Repeat(" ", boxWidth-lipgloss.Width(boxLine))
+		chartLines[top+i] = ansi.Truncate(line, left, "") + boxLine + ansi.TruncateLeft(line, left+boxWidth, "")
+	}
+	return strings.Join(chartLines, "\n")
+}
@@ -0,0 +1,879 @@
+package linechart
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/allank/chartea/axis"
+	"github.com/allank/chartea/canvas/braille"
+	"github.com/allank/chartea/indicator"
+	"github.com/allank/chartea/legend"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// palette is used for series added without a color of their own.
+var palette = []lipgloss.Color{"34", "214", "33", "124", "129", "37", "178", "160"}
+
+// ViewOptions allows you to specify the dimensions of the chart view.
+type ViewOptions struct {
+	Width  int
+	Height int
+}
+
+// CompareMode defines how a comparison series is overlaid on the chart.
+type CompareMode int
+
+const (
+	// CompareNormalized shows every series on the right axis as % change,
+	// so the relative performance of the symbols can be compared directly.
+	CompareNormalized CompareMode = iota
+	// CompareDualAxis shows the comparison series against its own left axis.
+	CompareDualAxis
+)
+
+// Interpolation defines how consecutive points of a series are joined.
+type Interpolation int
+
+const (
+	// Linear joins points with a straight diagonal line.
+	Linear Interpolation = iota
+	// Step holds each value until the next point, then jumps vertically,
+	// which suits discrete series like position size or funding rate.
+	Step
+	// Points draws each value as a dot without joining them, which suits
+	// sparse series where a line would suggest values that were never seen.
+	Points
+)
+
+// Series is a named sequence of values plotted on the chart.
+type Series struct {
+	// Name identifies the series.
+	Name string
+	// Data holds the values, oldest first. NaN values leave a gap in the line.
+	Data []float64
+	// Style is used to draw the line.
+	Style lipgloss.Style
+	// Axis is the y-axis the series is scaled against.
+	Axis axis.Side
+	// Interpolation determines how the points are joined.
+	Interpolation Interpolation
+}
+
+// KeyMap is the keys the chart handles in Update.
+type KeyMap struct {
+	// Indicators toggle the chart's indicators, the first binding the first
+	// indicator and so on.
+	Indicators []key.Binding
+	// Left and Right move the crosshair a point, showing it if hidden.
+	Left  key.Binding
+	Right key.Binding
+	// HideCrosshair hides the crosshair, ending any measurement.
+	HideCrosshair key.Binding
+	// Measure anchors a measurement at the crosshair, or ends it.
+	Measure key.Binding
+}
+
+// DefaultKeyMap returns the default keys, 1 to 9 for the indicators, the
+// arrow keys or h and l to move the crosshair, m to measure from it and esc
+// to hide it.
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Indicators:    indicator.DefaultKeys(),
+		Left:          key.NewBinding(key.WithKeys("left", "h"), key.WithHelp("←/h", "crosshair left")),
+		Right:         key.NewBinding(key.WithKeys("right", "l"), key.WithHelp("→/l", "crosshair right")),
+		HideCrosshair: key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "hide crosshair")),
+		Measure:       key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "measure")),
+	}
+}
+
+// Model represents the state of the line chart component.
+type Model struct {
+	width  int
+	height int
+
+	// Series are the lines plotted on the chart, drawn in order.
+	Series []Series
+
+	// Capacity is the number of values kept in each series, and of Times,
+	// by Append. Older values are dropped. When zero every value is kept.
+	Capacity int
+
+	// LeftMode and RightMode determine how values are mapped onto each y-axis.
+	LeftMode  axis.Mode
+	RightMode axis.Mode
+
+	// Padding is the percentage of the range of the data added above and
+	// below it on each axis, so the highest and lowest values aren't drawn
+	// against the edges. It's ignored on an axis with a range set with
+	// SetYRange.
+	Padding float64
+
+	// yRanges are the ranges set by SetYRange for each side, where fixed.
+	yRanges [2]axis.Scale
+	fixed   [2]bool
+
+	// Times are the times of the points of the series, oldest first, which
+	// are labelled along the bottom row when the chart is at least three rows
+	// high. The nth time is the time of the nth value of every series.
+	Times []time.Time
+
+	// ShowPriceLine draws a dashed line across the chart at the latest value
+	// of each series, in its style, with the value labelled on its axis, so
+	// the current price can be read off as values stream in.
+	ShowPriceLine bool
+
+	// ShowMinMax marks the highest and lowest values of each series with a
+	// glyph and their value, in its style, so the range of a series can be
+	// read off at a glance.
+	ShowMinMax bool
+
+	// Indicators are drawn over the series while enabled, each computed from
+	// its Series and scaled against its axis.
+	Indicators []indicator.Indicator
+
+	// KeyMap is the keys that toggle the indicators, each toggle sending an
+	// indicator.ToggleMsg, and move the crosshair.
+	KeyMap KeyMap
+
+	// crosshair is how many points before the latest the crosshair is on,
+	// so it stays in place on screen as values are appended, if crosshairOn.
+	crosshair   int
+	crosshairOn bool
+
+	// anchor is how many points before the latest a measurement starts, if
+	// measuring.
+	anchor    int
+	measuring bool
+
+	// Labels sets the gutter width, number and precision of the labels on
+	// each axis.
+	Labels axis.LabelOptions
+
+	// ShowLegend overlays a legend listing each series and its latest value.
+	ShowLegend bool
+	// Legend holds the position and styles of the legend, its entries are
+	// filled in from the series when rendering.
+	Legend legend.Model
+
+	// Styles
+	StyleAxis      lipgloss.Style
+	StyleCrosshair lipgloss.Style
+	StyleReadout   lipgloss.Style
+}
+
+// New creates a new line chart model with default styles.
+func New() Model {
+	return Model{
+		Labels: axis.LabelOptions{Precision: -1},
+		KeyMap: DefaultKeyMap(),
+		Legend: legend.New(),
+		StyleAxis: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "240", Dark: "245"}),
+		StyleCrosshair: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "250", Dark: "240"}),
+		StyleReadout: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "240", Dark: "245"}),
+	}
+}
+
+// AddComparison overlays another symbol's series on the chart, either normalized
+// alongside the existing series or against its own axis, and turns on the legend.
+func (m *Model) AddComparison(s Series, mode CompareMode) {
+	switch mode {
+	case CompareNormalized:
+		s.Axis = axis.Right
+		m.RightMode = axis.Percent
+		for i := range m.Series {
+			m.Series[i].Axis = axis.Right
+		}
+	case CompareDualAxis:
+		s.Axis = axis.Left
+	}
+	m.Series = append(m.Series, s)
+	m.ShowLegend = true
+}
+
+// AddSeries adds a series to the chart, replacing any existing series with the
+// same name. If the series style has no foreground color, one is picked from
+// the default palette.
+func (m *Model) AddSeries(s Series) {
+	i := 0
+	for i < len(m.Series) && m.Series[i].Name != s.Name {
+		i++
+	}
+	if _, ok := s.Style.GetForeground().(lipgloss.NoColor); ok {
+		s.Style = s.Style.Foreground(palette[i%len(palette)])
+	}
+	if i < len(m.Series) {
+		m.Series[i] = s
+		return
+	}
+	m.Series = append(m.Series, s)
+}
+
+// RemoveSeries removes the named series from the chart.
+func (m *Model) RemoveSeries(name string) {
+	for i := range m.Series {
+		if m.Series[i].Name == name {
+			m.Series = append(m.Series[:i], m.Series[i+1:]...)
+			return
+		}
+	}
+}
+
+// Append adds values to the end of the named series. It does nothing if there
+// is no series with that name. Only the latest Capacity values are kept, so a
+// chart left streaming for days doesn't grow.
+func (m *Model) Append(name string, values ...float64) {
+	for i := range m.Series {
+		if m.Series[i].Name == name {
+			m.Series[i].Data = m.trim(append(m.Series[i].Data, values...))
+			return
+		}
+	}
+}
+
+// AppendTime adds the times of the next points to Times, keeping only the
+// latest Capacity times, to match the series appended to.
+func (m *Model) AppendTime(times ...time.Time) {
+	m.Times = append(m.Times, times...)
+	if m.Capacity > 0 && len(m.Times) > m.Capacity {
+		m.Times = m.Times[len(m.Times)-m.Capacity:]
+	}
+}
+
+// trim drops the oldest values beyond the capacity.
+func (m *Model) trim(data []float64) []float64 {
+	if m.Capacity > 0 && len(data) > m.Capacity {
+		return data[len(data)-m.Capacity:]
+	}
+	return data
+}
+
+// SetYRange fixes the range of an axis, in the units it shows: % change on a
+// percent axis. This suits monitoring views where the scale shouldn't move
+// as data arrives. Lines outside the range are cut off at the edges. A range
+// with a bound that isn't a finite number returns to fitting the axis to the
+// data.
+func (m *Model) SetYRange(side axis.Side, low, high float64) {
+	if side != axis.Left && side != axis.Right {
+		return
+	}
+	m.yRanges[side] = axis.Scale{Min: min(low, high), Max: max(low, high)}
+	m.fixed[side] = !math.IsNaN(low) && !math.IsInf(low, 0) && !math.IsNaN(high) && !math.IsInf(high, 0)
+}
+
+// AutoYRange returns an axis to fitting its range to the data.
+func (m *Model) AutoYRange(side axis.Side) {
+	if side != axis.Left && side != axis.Right {
+		return
+	}
+	m.yRanges[side], m.fixed[side] = axis.Scale{}, false
+}
+
+// SetCrosshair shows the crosshair on the point at index i of the series,
+// e.g. the point under the mouse.
+func (m *Model) SetCrosshair(i int) {
+	m.crosshair = min(max(m.points()-1-i, 0), max(m.points()-1, 0))
+	m.crosshairOn = true
+}
+
+// HideCrosshair hides the crosshair, ending any measurement.
+func (m *Model) HideCrosshair() {
+	m.crosshairOn, m.measuring = false, false
+}
+
+// Crosshair returns the index in the series of the point under the
+// crosshair, and whether the crosshair is shown.
+func (m *Model) Crosshair() (int, bool) {
+	if !m.crosshairOn || m.points() == 0 {
+		return 0, false
+	}
+	return max(m.points()-1-m.crosshair, 0), true
+}
+
+// StartMeasure anchors a measurement at the crosshair, showing it on the
+// latest point if hidden. As the crosshair moves, the readout shows the
+// change in each series, and the time if known, from the point at the anchor.
+func (m *Model) StartMeasure() {
+	if !m.crosshairOn {
+		m.crosshair, m.crosshairOn = 0, true
+	}
+	m.anchor, m.measuring = m.crosshair, true
+}
+
+// StopMeasure ends the measurement, leaving the crosshair shown.
+func (m *Model) StopMeasure() {
+	m.measuring = false
+}
+
+// Measurement returns the indices of the points at the anchor and the
+// crosshair, and whether a measurement is being made.
+func (m *Model) Measurement() (from, to int, ok bool) {
+	to, ok = m.Crosshair()
+	if !ok || !m.measuring {
+		return 0, 0, false
+	}
+	return max(m.points()-1-m.anchor, 0), to, true
+}
+
+// points returns the number of points of the longest series.
+func (m *Model) points() int {
+	n := 0
+	for _, s := range m.Series {
+		n = max(n, len(s.Data))
+	}
+	return n
+}
+
+// Init initializes the line chart model.
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages for the line chart model.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.KeyMap.Left):
+			// The crosshair first appears on the latest point.
+			if m.crosshairOn {
+				m.crosshair = min(m.crosshair+1, max(m.points()-1, 0))
+			}
+			m.crosshairOn = true
+			return m, nil
+		case key.Matches(msg, m.KeyMap.Right):
+			if m.crosshairOn {
+				m.crosshair = max(m.crosshair-1, 0)
+			}
+			m.crosshairOn = true
+			return m, nil
+		case key.Matches(msg, m.KeyMap.HideCrosshair):
+			m.HideCrosshair()
+			return m, nil
+		case key.Matches(msg, m.KeyMap.Measure):
+			if m.measuring {
+				m.StopMeasure()
+			} else {
+				m.StartMeasure()
+			}
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.Indicators, cmd = indicator.Toggle(m.Indicators, m.KeyMap.Indicators, msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// View renders the chart, taking up the full width and height of the model.
+func (m *Model) View() string {
+	if m.width <= 0 {
+		return "Initializing..."
+	}
+	return m.ViewWithOptions(ViewOptions{Width: m.width, Height: m.height})
+}
+
+// ViewWithOptions renders the chart with the given options.
+func (m *Model) ViewWithOptions(opts ViewOptions) string {
+	if opts.Width <= 0 {
+		return "Initializing..."
+	}
+	if opts.Height <= 0 {
+		return ""
+	}
+
+	// Transform the data for each series as required by its axis.
+	values := make([][]float64, len(m.Series))
+	for i, s := range m.Series {
+		values[i] = s.Data
+		if m.axisMode(s.Axis) == axis.Percent {
+			values[i] = axis.PercentChange(s.Data)
+		}
+	}
+
+	// Fit a scale to each axis that has series assigned to it, unless its
+	// range is fixed.
+	scales := map[axis.Side]axis.Scale{}
+	for _, side := range []axis.Side{axis.Left, axis.Right} {
+		var data [][]float64
+		for i, s := range m.Series {
+			if s.Axis == side {
+				data = append(data, values[i])
+			}
+		}
+		if len(data) == 0 {
+			continue
+		}
+		if m.fixed[side] {
+			scale := m.yRanges[side]
+			scale.Mode = m.axisMode(side)
+			scales[side] = scale
+			continue
+		}
+		scales[side] = axis.Fit(m.axisMode(side), data...).Pad(m.Padding)
+	}
+
+	// Leave a row for the readout, and for the time labels when there's room.
+	height := opts.Height
+	if m.crosshairOn && height >= 2 {
+		height--
+	}
+	plotHeight := height
+	if len(m.Times) > 0 && height >= 3 {
+		plotHeight--
+	}
+
+	// Work out the axis labels and the space they take up either side of the plot.
+	leftLabels := m.axisLabels(scales, axis.Left, plotHeight)
+	rightLabels := m.axisLabels(scales, axis.Right, plotHeight)
+	lines := m.priceLines(values, scales)
+	leftGutter := m.Labels.GutterWidth(withPriceLabels(leftLabels, lines, axis.Left))
+	rightGutter := m.Labels.GutterWidth(withPriceLabels(rightLabels, lines, axis.Right))
+
+	plotWidth := opts.Width - leftGutter - rightGutter
+	if plotWidth <= 0 {
+		return ""
+	}
+
+	// Plot each series onto the canvas.
+	c := braille.New(plotWidth, plotHeight)
+	points := 0
+	for _, data := range values {
+		points = max(points, len(data))
+	}
+	// Dot the crosshair and price lines first, so the series are drawn over
+	// them.
+	// The anchor of a measurement is drawn in the same way.
+	cross := -1
+	if m.crosshairOn && points > 0 {
+		cross = max(points-1-m.crosshair, 0)
+		columns := []int{cross}
+		if m.measuring {
+			columns = append(columns, max(points-1-m.anchor, 0))
+		}
+		c.SetStyle(m.StyleCrosshair)
+		for _, i := range columns {
+			x := 0
+			if points > 1 {
+				x = int(math.Round(float64(i) * float64(c.Width()*2-1) / float64(points-1)))
+			}
+			for y := 0; y < c.Height()*4; y += 2 {
+				c.SetPixel(x, y)
+			}
+		}
+	}
+	labelStyles := map[axis.Side]map[int]lipgloss.Style{axis.Left: {}, axis.Right: {}}
+	for _, l := range lines {
+		c.SetStyle(l.style)
+		y := priceLineRow(c, l.pos)
+		for x := 0; x < c.Width()*2; x++ {
+			// Dash the line two dots on, two off.
+			if x%4 < 2 {
+				c.SetPixel(x, y)
+			}
+		}
+		labels := leftLabels
+		if l.side == axis.Right {
+			labels = rightLabels
+		}
+		labels[y/4] = l.label
+		labelStyles[l.side][y/4] = l.style.Reverse(true)
+	}
+	for i, s := range m.Series {
+		c.SetStyle(s.Style)
+		m.plotSeries(c, values[i], s.Interpolation, scales[s.Axis], points)
+	}
+	for _, ind := range m.Indicators {
+		i := m.indicatorSeries(ind)
+		if !ind.Enabled || ind.Compute == nil || i < 0 {
+			continue
+		}
+		c.SetStyle(ind.Style)
+		m.plotSeries(c, ind.Compute(values[i]), Linear, scales[m.Series[i].Axis], points)
+	}
+
+	plotRows := c.Rows()
+	if m.ShowMinMax {
+		plotRows = withMarkers(plotRows, m.minMaxMarkers(values, scales, c, points), plotWidth)
+	}
+	if m.ShowLegend {
+		plotRows = strings.Split(m.renderLegend(values, strings.Join(plotRows, "\n")), "\n")
+	}
+	rows := make([]string, 0, opts.Height)
+	for y, row := range plotRows {
+		var sb strings.Builder
+		if leftGutter > 0 {
+			label := axis.Gutter(leftLabels[y], leftGutter, axis.Left)
+			if style, ok := labelStyles[axis.Left][y]; ok {
+				sb.WriteString(style.Render(label[:len(label)-1]) + " ")
+			} else {
+				sb.WriteString(m.StyleAxis.Render(label))
+			}
+		}
+		sb.WriteString(row)
+		if rightGutter > 0 {
+			label := axis.Gutter(rightLabels[y], rightGutter, axis.Right)
+			if style, ok := labelStyles[axis.Right][y]; ok {
+				sb.WriteString(" " + style.Render(label[1:]))
+			} else {
+				sb.WriteString(m.StyleAxis.Render(label))
+			}
+		}
+		rows = append(rows, sb.String())
+	}
+	if plotHeight < height {
+		labels := m.timeAxis(plotWidth, points)
+		rows = append(rows, m.StyleAxis.Render(strings.Repeat(" ", leftGutter)+labels+strings.Repeat(" ", rightGutter)))
+	}
+	if height < opts.Height {
+		if from, to, ok := m.Measurement(); ok {
+			rows = append(rows, m.measureReadout(from, to, opts.Width))
+		} else {
+			rows = append(rows, m.readout(values, cross, opts.Width))
+		}
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+// readout renders the row describing the point at index i: its time, if
+// known, and the value of each series and enabled indicator there, cut short
+// to fit the width. Series without a value there are left out.
+func (m *Model) readout(values [][]float64, i, width int) string {
+	var parts []string
+	if i >= 0 && i < len(m.Times) && !m.Times[i].IsZero() {
+		parts = append(parts, m.Times[i].Format("Jan 2 15:04:05"))
+	}
+	value := func(name string, data []float64, side axis.Side) {
+		if i >= 0 && i < len(data) && finite(data[i]) {
+			parts = append(parts, name+" "+m.valueLabel(data[i], side))
+		}
+	}
+	for j, s := range m.Series {
+		value(s.Name, values[j], s.Axis)
+	}
+	for _, ind := range m.Indicators {
+		if j := m.indicatorSeries(ind); ind.Enabled && ind.Compute != nil && j >= 0 {
+			value(ind.Name, ind.Compute(values[j]), m.Series[j].Axis)
+		}
+	}
+	text := ansi.Truncate(strings.Join(parts, "  "), width, "")
+	return m.StyleReadout.Render(text + strings.Repeat(" ", width-ansi.StringWidth(text)))
+}
+
+// measureReadout renders the row describing a measurement from the point at
+// index from to the point at index to: the times, if known, the change in
+// each series with a value at both, as a value and a percentage, the time
+// between them and the number of points, cut short to fit the width.
+func (m *Model) measureReadout(from, to, width int) string {
+	var parts []string
+	timed := from < len(m.Times) && to < len(m.Times) && !m.Times[from].IsZero() && !m.Times[to].IsZero()
+	if timed {
+		parts = append(parts, m.Times[from].Format("Jan 2 15:04:05")+" → "+m.Times[to].Format("Jan 2 15:04:05"))
+	}
+	for _, s := range m.Series {
+		if from >= len(s.Data) || to >= len(s.Data) || !finite(s.Data[from]) || !finite(s.Data[to]) {
+			continue
+		}
+		a, b := s.Data[from], s.Data[to]
+		change := m.Labels.Format(b - a)
+		if b > a {
+			change = "+" + change
+		}
+		part := s.Name + " Δ " + change
+		if a != 0 {
+			part += fmt.Sprintf(" %+.2f%%", (b-a)/a*100)
+		}
+		parts = append(parts, part)
+	}
+	if timed {
+		parts = append(parts, "Δt "+axis.FormatDuration(m.Times[to].Sub(m.Times[from])))
+	}
+	points := fmt.Sprintf("%d points", max(to-from, from-to))
+	if to-from == 1 || from-to == 1 {
+		points = "1 point"
+	}
+	parts = append(parts, points)
+	text := ansi.Truncate(strings.Join(parts, "  "), width, "")
+	return m.StyleReadout.Render(text + strings.Repeat(" ", width-ansi.StringWidth(text)))
+}
+
+// timeAxis renders the row of time labels under a plot of the given width,
+// each centred on the column its point is drawn in.
+func (m *Model) timeAxis(width, points int) string {
+	n := min(len(m.Times), points)
+	columns := make([]int, n)
+	for i := range columns {
+		if points > 1 {
+			columns[i] = int(math.Round(float64(i)*float64(width*2-1)/float64(points-1))) / 2
+		}
+	}
+	return axis.TimeAxis(m.Times[:n], columns, width)
+}
+
+// priceLine is the line at the latest value of a series.
+type priceLine struct {
+	// pos is the position of the value on its axis, from 0 to 1.
+	pos   float64
+	label string
+	side  axis.Side
+	style lipgloss.Style
+}
+
+// priceLines returns the price lines of the series, when shown. Series
+// without a value, or whose latest value is outside a fixed range, have none.
+func (m *Model) priceLines(values [][]float64, scales map[axis.Side]axis.Scale) []priceLine {
+	if !m.ShowPriceLine {
+		return nil
+	}
+	var lines []priceLine
+	for i, s := range m.Series {
+		for j := len(values[i]) - 1; j >= 0; j-- {
+			v := values[i][j]
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				continue
+			}
+			if pos := scales[s.Axis].Normalize(v); pos >= 0 && pos <= 1 {
+				lines = append(lines, priceLine{pos: pos, label: m.valueLabel(v, s.Axis), side: s.Axis, style: s.Style})
+			}
+			break
+		}
+	}
+	return lines
+}
+
+// valueLabel formats a value on an axis, such as the latest price, as a
+// signed percentage on a percent axis.
+func (m *Model) valueLabel(v float64, side axis.Side) string {
+	if m.axisMode(side) != axis.Percent {
+		return m.Labels.Format(v)
+	}
+	// Percentages don't need the precision of a price.
+	precision := 2
+	if m.Labels.Precision >= 0 {
+		precision = m.Labels.Precision
+	}
+	label := strconv.FormatFloat(v, 'f', precision, 64) + "%"
+	if v > 0 {
+		label = "+" + label
+	}
+	return label
+}
+
+// withPriceLabels returns the labels of a side along with the labels of its
+// price lines, for sizing its gutter.
+func withPriceLabels(labels []string, lines []priceLine, side axis.Side) []string {
+	if labels == nil {
+		return nil
+	}
+	all := append([]string(nil), labels...)
+	for _, l := range lines {
+		if l.side == side {
+			all = append(all, l.label)
+		}
+	}
+	return all
+}
+
+// priceLineRow returns the row of dots a price line at a position is drawn on.
+func priceLineRow(c *braille.Canvas, pos float64) int {
+	dotsH := c.Height() * 4
+	return (dotsH - 1) - int(math.Round(pos*float64(dotsH-1)))
+}
+
+// marker is a glyph and label drawn over the plot at a value.
+type marker struct {
+	x, y  int
+	text  string
+	style lipgloss.Style
+}
+
+// minMaxMarkers returns markers at the highest and lowest values of each
+// series, with the label to the right of the glyph, or to its left where it
+// doesn't fit. Values outside a fixed range aren't marked.
+func (m *Model) minMaxMarkers(values [][]float64, scales map[axis.Side]axis.Scale, c *braille.Canvas, points int) []marker {
+	var markers []marker
+	for i, s := range m.Series {
+		hi, lo := -1, -1
+		for j, v := range values[i] {
+			if !finite(v) {
+				continue
+			}
+			if hi < 0 || v > values[i][hi] {
+				hi = j
+			}
+			if lo < 0 || v < values[i][lo] {
+				lo = j
+			}
+		}
+		if hi < 0 {
+			continue
+		}
+		for _, mm := range []struct {
+			j     int
+			glyph string
+		}{{hi, "▲"}, {lo, "▼"}} {
+			if mm.glyph == "▼" && lo == hi {
+				break
+			}
+			v := values[i][mm.j]
+			pos := scales[s.Axis].Normalize(v)
+			if !(pos >= 0 && pos <= 1) {
+				continue
+			}
+			x := 0
+			if points > 1 {
+				x = int(math.Round(float64(mm.j)*float64(c.Width()*2-1)/float64(points-1))) / 2
+			}
+			label := m.valueLabel(v, s.Axis)
+			text := mm.glyph + " " + label
+			if x+ansi.StringWidth(text) > c.Width() {
+				text = label + " " + mm.glyph
+				x -= ansi.StringWidth(text) - 1
+			}
+			markers = append(markers, marker{x: x, y: priceLineRow(c, pos) / 4, text: text, style: s.Style})
+		}
+	}
+	return markers
+}
+
+// withMarkers draws markers over the rows of a plot of the given width.
+// Markers that would run off the plot or over an earlier marker are left out.
+func withMarkers(rows []string, markers []marker, width int) []string {
+	taken := make(map[int][][2]int)
+	for _, mk := range markers {
+		w := ansi.StringWidth(mk.text)
+		if mk.x < 0 || mk.x+w > width || mk.y < 0 || mk.y >= len(rows) {
+			continue
+		}
+		overlaps := false
+		for _, span := range taken[mk.y] {
+			if mk.x < span[1] && span[0] < mk.x+w {
+				overlaps = true
+			}
+		}
+		if overlaps {
+			continue
+		}
+		taken[mk.y] = append(taken[mk.y], [2]int{mk.x, mk.x + w})
+		row := rows[mk.y]
+		rows[mk.y] = ansi.Truncate(row, mk.x, "") + mk.style.Render(mk.text) + ansi.TruncateLeft(row, mk.x+w, "")
+	}
+	return rows
+}
+
+// plotSeries draws the values of a series onto the canvas as connected line segments.
+func (m *Model) plotSeries(c *braille.Canvas, data []float64, interpolation Interpolation, scale axis.Scale, points int) {
+	dotsW, dotsH := c.Width()*2, c.Height()*4
+	// A line through more points than there are columns of dots can't show
+	// them all, so only draw those that keep its shape.
+	indices := LTTB(data, dotsW)
+	if interpolation == Points {
+		indices = LTTB(data, 0)
+	}
+	prevX, prevY, havePrev := 0, 0, false
+	for _, i := range indices {
+		v := data[i]
+		pos := scale.Normalize(v)
+		if math.IsNaN(pos) || math.IsInf(pos, 0) {
+			havePrev = false
+			continue
+		}
+		// Values outside a fixed range are drawn off the canvas, but not so
+		// far that lines to them take long to draw.
+		pos = min(max(pos, -1), 2)
+		x := 0
+		if points > 1 {
+			x = int(math.Round(float64(i) * float64(dotsW-1) / float64(points-1)))
+		}
+		y := (dotsH - 1) - int(math.Round(pos*float64(dotsH-1)))
+		switch {
+		case interpolation == Points:
+			c.SetPixel(x, y)
+		case havePrev && interpolation == Step:
+			c.Line(prevX, prevY, x, prevY)
+			c.Line(x, prevY, x, y)
+		case havePrev:
+			c.Line(prevX, prevY, x, y)
+		default:
+			c.SetPixel(x, y)
+		}
+		prevX, prevY, havePrev = x, y, true
+	}
+}
+
+// indicatorSeries returns the index of the series an indicator is computed
+// from, or -1 if there's no such series.
+func (m *Model) indicatorSeries(ind indicator.Indicator) int {
+	if ind.Series == "" && len(m.Series) > 0 {
+		return 0
+	}
+	for i, s := range m.Series {
+		if s.Name == ind.Series {
+			return i
+		}
+	}
+	return -1
+}
+
+// renderLegend overlays the legend on the plot, with an entry per series and
+// enabled indicator showing its latest value.
+func (m *Model) renderLegend(values [][]float64, plot string) string {
+	l := m.Legend
+	l.Entries = make([]legend.Entry, 0, len(m.Series))
+	for i, s := range m.Series {
+		e := legend.Entry{Label: s.Name, Style: s.Style, HideValue: true}
+		for j := len(values[i]) - 1; j >= 0; j-- {
+			if v := values[i][j]; !math.IsNaN(v) && !math.IsInf(v, 0) {
+				e.Value, e.HideValue = v, false
+				break
+			}
+		}
+		l.Entries = append(l.Entries, e)
+	}
+	for _, ind := range m.Indicators {
+		i := m.indicatorSeries(ind)
+		if !ind.Enabled || ind.Compute == nil || i < 0 {
+			continue
+		}
+		e := legend.Entry{Label: ind.Name, Style: ind.Style, HideValue: true}
+		computed := ind.Compute(values[i])
+		for j := len(computed) - 1; j >= 0; j-- {
+			if v := computed[j]; !math.IsNaN(v) && !math.IsInf(v, 0) {
+				e.Value, e.HideValue = v, false
+				break
+			}
+		}
+		l.Entries = append(l.Entries, e)
+	}
+	return l.Overlay(plot)
+}
+
+// axisMode returns the mode configured for the given side.
+func (m *Model) axisMode(side axis.Side) axis.Mode {
+	if side == axis.Left {
+		return m.LeftMode
+	}
+	return m.RightMode
+}
+
+// axisLabels returns one label per row for the given side, empty where no label is drawn.
+// Returns nil if no series uses the axis.
+func (m *Model) axisLabels(scales map[axis.Side]axis.Scale, side axis.Side, rows int) []string {
+	scale, ok := scales[side]
+	if !ok {
+		return nil
+	}
+	return scale.RowLabels(rows, m.Labels)
+}
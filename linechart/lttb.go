@@ -0,0 +1,94 @@
+package linechart
+
+import "math"
+
+// LTTB picks about threshold of the values to draw with the
+// Largest-Triangle-Three-Buckets algorithm, returning their indices in
+// order. The values are split into buckets, and from each the value forming
+// the largest triangle with the values picked either side is kept, which
+// keeps the peaks and troughs that give a series its shape. Runs of values
+// between NaN or Inf are downsampled separately, sharing the threshold by
+// their length, and the first index of each gap is kept so the gap is drawn.
+// When there are no more values than the threshold, every index is returned.
+func LTTB(data []float64, threshold int) []int {
+	if threshold <= 0 || len(data) <= threshold {
+		all := make([]int, len(data))
+		for i := range all {
+			all[i] = i
+		}
+		return all
+	}
+
+	out := make([]int, 0, threshold+2)
+	for start := 0; start < len(data); {
+		if !finite(data[start]) {
+			out = append(out, start)
+			for start < len(data) && !finite(data[start]) {
+				start++
+			}
+			continue
+		}
+		end := start
+		for end < len(data) && finite(data[end]) {
+			end++
+		}
+		n := int(math.Round(float64(end-start) * float64(threshold) / float64(len(data))))
+		for _, i := range lttb(data[start:end], max(n, 2)) {
+			out = append(out, start+i)
+		}
+		start = end
+	}
+	return out
+}
+
+// lttb downsamples finite values to threshold points, always keeping the
+// first and last.
+func lttb(data []float64, threshold int) []int {
+	n := len(data)
+	if n <= threshold || n <= 2 {
+		all := make([]int, n)
+		for i := range all {
+			all[i] = i
+		}
+		return all
+	}
+	if threshold < 3 {
+		return []int{0, n - 1}
+	}
+
+	out := make([]int, 0, threshold)
+	out = append(out, 0)
+	// The first and last values have buckets of their own.
+	every := float64(n-2) / float64(threshold-2)
+	a := 0
+	for b := range threshold - 2 {
+		// Average the next bucket to stand in for the point after this one.
+		nextStart := int(float64(b+1)*every) + 1
+		nextEnd := min(int(float64(b+2)*every)+1, n)
+		avgX, avgY := 0.0, 0.0
+		for j := nextStart; j < nextEnd; j++ {
+			avgX += float64(j)
+			avgY += data[j]
+		}
+		count := float64(nextEnd - nextStart)
+		avgX, avgY = avgX/count, avgY/count
+
+		// Keep the point in this bucket making the largest triangle.
+		start, end := int(float64(b)*every)+1, int(float64(b+1)*every)+1
+		best, bestArea := start, -1.0
+		for j := start; j < end; j++ {
+			area := math.Abs((float64(a)-avgX)*(data[j]-data[a]) - (float64(a)-float64(j))*(avgY-data[a]))
+			if area > bestArea {
+				best, bestArea = j, area
+			}
+		}
+		out = append(out, best)
+		a = best
+	}
+	return append(out, n-1)
+}
+
+// finite reports whether v is a finite number.
+func finite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
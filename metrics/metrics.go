@@ -0,0 +1,112 @@
+// Package metrics exposes the health of feeds and the time spent rendering as
+// Prometheus metrics, for dashboards left running as monitors.
+package metrics
+
+import (
+	"time"
+
+	"github.com/allank/chartea/feed"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	_ feed.Metrics         = (*Collector)(nil)
+	_ prometheus.Collector = (*Collector)(nil)
+)
+
+// Collector records the messages, reconnects and checksum failures of feeds,
+// and how long components take to render. It implements feed.Metrics, to be
+// set on sources, and prometheus.Collector, to be registered with a registry
+// and served with promhttp, e.g.
+//
+//	collector := metrics.New()
+//	registry := prometheus.NewRegistry()
+//	registry.MustRegister(collector)
+//	http.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+//
+// The rate of messages per second is the rate of chartea_feed_messages_total.
+type Collector struct {
+	messages  *prometheus.CounterVec
+	reconnect *prometheus.CounterVec
+	checksum  *prometheus.CounterVec
+	render    *prometheus.HistogramVec
+}
+
+// New creates a collector with the metrics in the chartea namespace.
+func New() *Collector {
+	return &Collector{
+		messages: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "chartea",
+			Subsystem: "feed",
+			Name:      "messages_total",
+			Help:      "Messages received by each source.",
+		}, []string{"source"}),
+		reconnect: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "chartea",
+			Subsystem: "feed",
+			Name:      "reconnects_total",
+			Help:      "Reconnections of each source after its connection dropped.",
+		}, []string{"source"}),
+		checksum: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "chartea",
+			Subsystem: "feed",
+			Name:      "checksum_failures_total",
+			Help:      "Books of each source that failed the exchange's checksum.",
+		}, []string{"source"}),
+		render: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "chartea",
+			Subsystem: "render",
+			Name:      "duration_seconds",
+			Help:      "Time taken to render each component.",
+			// Renders take from tens of microseconds up to a frame or so.
+			Buckets: prometheus.ExponentialBuckets(25e-6, 2, 12),
+		}, []string{"component"}),
+	}
+}
+
+// Message records a message received by a source.
+func (c *Collector) Message(source string) {
+	c.messages.WithLabelValues(source).Inc()
+}
+
+// Reconnect records a source reconnecting after its connection dropped.
+func (c *Collector) Reconnect(source string) {
+	c.reconnect.WithLabelValues(source).Inc()
+}
+
+// ChecksumFailure records a book that failed its checksum.
+func (c *Collector) ChecksumFailure(source string) {
+	c.checksum.WithLabelValues(source).Inc()
+}
+
+// Render records the time a component took to render.
+func (c *Collector) Render(component string, d time.Duration) {
+	c.render.WithLabelValues(component).Observe(d.Seconds())
+}
+
+// Time renders a component with view, recording the time it took, e.g.
+//
+//	s := collector.Time("clob", func() string { return book.View() })
+func (c *Collector) Time(component string, view func() string) string {
+	start := time.Now()
+	s := view()
+	c.Render(component, time.Since(start))
+	return s
+}
+
+// Describe sends the descriptions of the metrics.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.messages.Describe(ch)
+	c.reconnect.Describe(ch)
+	c.checksum.Describe(ch)
+	c.render.Describe(ch)
+}
+
+// Collect sends the current values of the metrics.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.messages.Collect(ch)
+	c.reconnect.Collect(ch)
+	c.checksum.Collect(ch)
+	c.render.Collect(ch)
+}
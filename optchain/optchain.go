@@ -0,0 +1,225 @@
+package optchain
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ViewOptions allows you to specify the dimensions of the options chain view.
+type ViewOptions struct {
+	Width  int
+	Height int
+}
+
+// Quote is the market for a single option contract.
+type Quote struct {
+	Bid          float64
+	Ask          float64
+	OpenInterest float64
+}
+
+// Strike holds the call and put quotes for a single strike price.
+type Strike struct {
+	Strike float64
+	Call   Quote
+	Put    Quote
+}
+
+// Model represents the state of the options chain component.
+type Model struct {
+	width  int
+	height int
+
+	// Strikes are the rows of the chain. They do not need to be sorted.
+	Strikes []Strike
+
+	// Underlying is the price of the underlying, the closest strike is highlighted as at the money.
+	Underlying float64
+
+	// Spacing is the space between the strike column and the call and put columns.
+	Spacing int
+
+	// Precision for strikes and quotes.
+	StrikePrecision int
+	QuotePrecision  int
+
+	// Styles
+	StyleOffBar lipgloss.Style
+	StyleOnCall lipgloss.Style
+	StyleOnPut  lipgloss.Style
+	StyleStrike lipgloss.Style
+	StyleATM    lipgloss.Style
+	StyleHeader lipgloss.Style
+}
+
+// New creates a new options chain model with default styles.
+func New() Model {
+	return Model{
+		Spacing:         1,
+		StrikePrecision: 0,
+		QuotePrecision:  2,
+		StyleOffBar: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "232", Dark: "188"}),
+		StyleOnCall: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("188")).
+			Background(lipgloss.Color("34")),
+		StyleOnPut: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("188")).
+			Background(lipgloss.Color("124")),
+		StyleStrike: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "232", Dark: "188"}).
+			Bold(true),
+		StyleATM: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("232")).
+			Background(lipgloss.Color("214")).
+			Bold(true),
+		StyleHeader: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "240", Dark: "245"}),
+	}
+}
+
+// Init initializes the options chain model.
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages for the options chain model.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	}
+	return m, nil
+}
+
+// View renders the chain, taking up the full width and height of the model.
+func (m *Model) View() string {
+	if m.width <= 0 {
+		return "Initializing..."
+	}
+	return m.ViewWithOptions(ViewOptions{Width: m.width, Height: m.height})
+}
+
+// ViewWithOptions renders the chain with the given options. Strikes run down
+// the middle, with calls on the left and puts on the right. Open interest is
+// shown as a bar growing out from the strike column, as in the order book.
+func (m *Model) ViewWithOptions(opts ViewOptions) string {
+	if opts.Width <= 0 {
+		return "Initializing..."
+	}
+	if opts.Height <= 1 || len(m.Strikes) == 0 {
+		return ""
+	}
+
+	// Sort the strikes before rendering.
+	sort.Slice(m.Strikes, func(i, j int) bool {
+		return m.Strikes[i].Strike < m.Strikes[j].Strike
+	})
+
+	// Keep the rows that fit below the header, centred on the money.
+	atm := m.atmIndex()
+	strikes := m.Strikes
+	rows := opts.Height - 1
+	first := 0
+	if len(strikes) > rows {
+		first = max(0, min(atm-rows/2, len(strikes)-rows))
+		strikes = strikes[first : first+rows]
+	}
+
+	// Calculate the width of each column.
+	strikeFormat := fmt.Sprintf("%%.%df", m.StrikePrecision)
+	strikeWidth := len("Strike")
+	for _, s := range strikes {
+		strikeWidth = max(strikeWidth, len(fmt.Sprintf(strikeFormat, s.Strike)))
+	}
+	strikeWidth += 2
+	columnWidth := (opts.Width - strikeWidth - m.Spacing*2) / 2
+	if columnWidth <= 0 {
+		return ""
+	}
+
+	// Scale the bars on the largest open interest on either side, leaving out
+	// open interest that can't be drawn.
+	maxOI := 0.0
+	for _, s := range strikes {
+		for _, oi := range []float64{s.Call.OpenInterest, s.Put.OpenInterest} {
+			if drawable(oi) {
+				maxOI = max(maxOI, oi)
+			}
+		}
+	}
+
+	spacer := strings.Repeat(" ", m.Spacing)
+	lines := make([]string, 0, len(strikes)+1)
+	header := m.StyleHeader.Render(m.layout("Bid", "Ask", columnWidth)) + spacer +
+		m.StyleHeader.Width(strikeWidth).Align(lipgloss.Center).Render("Strike") + spacer +
+		m.StyleHeader.Render(m.layout("Bid", "Ask", columnWidth))
+	lines = append(lines, header)
+
+	for i, s := range strikes {
+		strikeStyle := m.StyleStrike
+		if first+i == atm && m.Underlying > 0 {
+			strikeStyle = m.StyleATM
+		}
+		strike := strikeStyle.Width(strikeWidth).Align(lipgloss.Center).Render(fmt.Sprintf(strikeFormat, s.Strike))
+		call := m.renderBar(s.Call, columnWidth, maxOI, m.StyleOnCall, true)
+		put := m.renderBar(s.Put, columnWidth, maxOI, m.StyleOnPut, false)
+		lines = append(lines, call+spacer+strike+spacer+put)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// renderBar renders one side of a strike, with the open interest bar anchored
+// to the strike column. Calls are anchored right, puts are anchored left.
+func (m *Model) renderBar(q Quote, width int, maxOI float64, on lipgloss.Style, anchorRight bool) string {
+	quoteFormat := fmt.Sprintf("%%.%df", m.QuotePrecision)
+	output := m.layout(fmt.Sprintf(quoteFormat, q.Bid), fmt.Sprintf(quoteFormat, q.Ask), width)
+
+	onLen := 0
+	if maxOI > 0 && drawable(q.OpenInterest) {
+		onLen = min(max(int(float64(width)*(q.OpenInterest/maxOI)), 0), width)
+	}
+	offLen := width - onLen
+
+	if anchorRight {
+		offStr := m.StyleOffBar.Width(offLen).Render(output[:offLen])
+		onStr := on.Width(onLen).Render(output[offLen:])
+		return lipgloss.JoinHorizontal(lipgloss.Right, offStr, onStr)
+	}
+	onStr := on.Width(onLen).Render(output[:onLen])
+	offStr := m.StyleOffBar.Width(offLen).Render(output[onLen:])
+	return lipgloss.JoinHorizontal(lipgloss.Left, onStr, offStr)
+}
+
+// drawable reports whether an open interest can be drawn as a bar: a finite
+// number that isn't negative.
+func drawable(oi float64) bool {
+	return oi >= 0 && !math.IsInf(oi, 1)
+}
+
+// layout places the bid on the left and the ask on the right of a column of the given width.
+func (m *Model) layout(bid, ask string, width int) string {
+	padding := width - len(bid) - len(ask)
+	if padding < 1 {
+		// Not enough room for both, show as much of the bid as fits.
+		return fmt.Sprintf("%-*s", width, bid)[:width]
+	}
+	return bid + strings.Repeat(" ", padding) + ask
+}
+
+// atmIndex returns the index of the strike closest to the underlying price.
+func (m *Model) atmIndex() int {
+	atm := 0
+	for i, s := range m.Strikes {
+		if math.Abs(s.Strike-m.Underlying) < math.Abs(m.Strikes[atm].Strike-m.Underlying) {
+			atm = i
+		}
+	}
+	return atm
+}
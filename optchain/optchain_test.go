@@ -0,0 +1,28 @@
+package optchain
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// TestViewOpenInterestNotDrawable checks that open interest that isn't a
+// finite number, or is negative, renders without a bar rather than panicking.
+func TestViewOpenInterestNotDrawable(t *testing.T) {
+	for _, oi := range []float64{math.Inf(1), math.Inf(-1), math.NaN(), -5} {
+		m := New()
+		m.Strikes = []Strike{
+			{Strike: 95, Call: Quote{Bid: 6, Ask: 6.5, OpenInterest: oi}, Put: Quote{Bid: 1, Ask: 1.2, OpenInterest: 10}},
+			{Strike: 100, Call: Quote{Bid: 3, Ask: 3.4, OpenInterest: 20}, Put: Quote{Bid: 3, Ask: 3.3, OpenInterest: oi}},
+		}
+		m.Underlying = 100
+		lines := strings.Split(m.ViewWithOptions(ViewOptions{Width: 40, Height: 10}), "\n")
+		for i, line := range lines {
+			if w := ansi.StringWidth(line); w != 40 {
+				t.Errorf("open interest %v: line %d is %d wide, want 40", oi, i, w)
+			}
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package panel
+
+import (
+	"github.com/allank/chartea/clob"
+	"github.com/allank/chartea/depth"
+	"github.com/allank/chartea/feed"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ViewOptions allows you to specify the dimensions of a panel.
+type ViewOptions struct {
+	Width  int
+	Height int
+}
+
+// BookDepth renders an order book ladder on one side and its cumulative depth
+// chart on the other, both from the same OrderBook and sized together.
+type BookDepth struct {
+	width  int
+	height int
+
+	// OrderBook is the data for both the ladder and the chart.
+	clob.OrderBook
+
+	// Book and Chart are the ladder and depth chart, for their own settings
+	// and styles. Their books are replaced with OrderBook when rendered.
+	Book  clob.Model
+	Chart depth.Model
+
+	// Ratio is the share of the width given to the ladder, between 0 and 1.
+	Ratio float64
+
+	// Spacing is the space between the ladder and the chart.
+	Spacing int
+
+	// ChartLeft puts the chart on the left of the ladder.
+	ChartLeft bool
+}
+
+// NewBookDepth creates a book and depth panel, with the ladder in the
+// Vertical orientation taking half of the width.
+func NewBookDepth() BookDepth {
+	book := clob.New()
+	book.Orientation = clob.Vertical
+	return BookDepth{
+		Book:    book,
+		Chart:   depth.New(),
+		Ratio:   0.5,
+		Spacing: 1,
+	}
+}
+
+// Init initializes the panel.
+func (m BookDepth) Init() tea.Cmd {
+	return m.Book.Init()
+}
+
+// Update handles messages for the panel. A feed.BookUpdateMsg replaces the
+// book, and other messages are passed on to the ladder, e.g. for its
+// spinner, refreshes and impact size.
+func (m BookDepth) Update(msg tea.Msg) (BookDepth, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+	case feed.BookUpdateMsg:
+		m.OrderBook = msg.Book
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.Book, cmd = m.Book.Update(msg)
+	return m, cmd
+}
+
+// View renders the panel, taking up the full width and height of the model.
+func (m *BookDepth) View() string {
+	if m.width <= 0 {
+		return "Initializing..."
+	}
+	return m.ViewWithOptions(ViewOptions{Width: m.width, Height: m.height})
+}
+
+// ViewWithOptions renders the panel with the given options. Unless the chart
+// has its own Levels, it covers the levels that fit in the ladder, so the two
+// show the same part of the book.
+func (m *BookDepth) ViewWithOptions(opts ViewOptions) string {
+	if opts.Width <= 0 {
+		return "Initializing..."
+	}
+	if opts.Height <= 0 {
+		return ""
+	}
+	m.Book.OrderBook = m.OrderBook
+	m.Chart.OrderBook = m.OrderBook
+
+	spacing := max(m.Spacing, 0)
+	bookWidth := int(float64(opts.Width-spacing) * min(max(m.Ratio, 0), 1))
+	chartWidth := opts.Width - spacing - bookWidth
+	if bookWidth <= 0 || chartWidth <= 0 {
+		// Too narrow for both, so only show the ladder.
+		return m.Book.ViewWithOptions(clob.ViewOptions{Width: opts.Width, Height: opts.Height})
+	}
+
+	if m.Chart.Levels == 0 {
+		m.Chart.Levels = m.ladderLevels(opts.Height)
+		defer func() { m.Chart.Levels = 0 }()
+	}
+
+	book := m.Book.ViewWithOptions(clob.ViewOptions{Width: bookWidth, Height: opts.Height})
+	chart := lipgloss.Place(chartWidth, opts.Height, lipgloss.Left, lipgloss.Bottom,
+		m.Chart.ViewWithOptions(depth.ViewOptions{Width: chartWidth, Height: opts.Height}))
+	spacer := lipgloss.NewStyle().Width(spacing).Render("")
+	if m.ChartLeft {
+		return lipgloss.JoinHorizontal(lipgloss.Top, chart, spacer, book)
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, book, spacer, chart)
+}
+
+// ladderLevels returns the number of levels on each side the ladder shows in
+// the given height.
+func (m *BookDepth) ladderLevels(height int) int {
+	rows := height
+	if m.Book.Orientation == clob.Vertical {
+		// Leave room for the spread.
+		rows = (height - 1) / 2
+	}
+	if m.Book.Depth > 0 {
+		rows = min(rows, m.Book.Depth)
+	}
+	return max(rows, 1)
+}
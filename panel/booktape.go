@@ -0,0 +1,125 @@
+package panel
+
+import (
+	"github.com/allank/chartea/clob"
+	"github.com/allank/chartea/feed"
+	"github.com/allank/chartea/tape"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// BookTape renders an order book ladder beside a tape of the market's trades,
+// the usual market depth panel, fed from one subscription and styled alike.
+type BookTape struct {
+	width  int
+	height int
+
+	// Market limits the panel to the feed messages for one market. When empty
+	// every BookUpdateMsg and TradeMsg is shown.
+	Market string
+
+	// Book and Tape are the ladder and the tape. When rendered, the tape
+	// takes the ladder's precision, and its colours from the ladder's bid
+	// and ask bars, so the two match.
+	Book clob.Model
+	Tape tape.Model
+
+	// Ratio is the share of the width given to the ladder, between 0 and 1.
+	Ratio float64
+
+	// Spacing is the space between the ladder and the tape.
+	Spacing int
+
+	// TapeLeft puts the tape on the left of the ladder.
+	TapeLeft bool
+}
+
+// NewBookTape creates a book and tape panel, with the ladder in the Vertical
+// orientation taking two thirds of the width.
+func NewBookTape() BookTape {
+	book := clob.New()
+	book.Orientation = clob.Vertical
+	return BookTape{
+		Book:    book,
+		Tape:    tape.New(),
+		Ratio:   2.0 / 3,
+		Spacing: 1,
+	}
+}
+
+// Init initializes the panel.
+func (m BookTape) Init() tea.Cmd {
+	return m.Book.Init()
+}
+
+// Update handles messages for the panel. A feed.BookUpdateMsg replaces the
+// book and a feed.TradeMsg adds its trades to the tape, and other messages
+// are passed on to the ladder, e.g. for its spinner, refreshes and impact
+// size.
+func (m BookTape) Update(msg tea.Msg) (BookTape, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+	case feed.BookUpdateMsg:
+		if m.Market == "" || msg.Market == m.Market {
+			m.Book.OrderBook = msg.Book
+		}
+		return m, nil
+	case feed.TradeMsg:
+		if m.Market == "" || msg.Market == m.Market {
+			m.Tape.Push(msg.Trades...)
+		}
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.Book, cmd = m.Book.Update(msg)
+	return m, cmd
+}
+
+// View renders the panel, taking up the full width and height of the model.
+func (m *BookTape) View() string {
+	if m.width <= 0 {
+		return "Initializing..."
+	}
+	return m.ViewWithOptions(ViewOptions{Width: m.width, Height: m.height})
+}
+
+// ViewWithOptions renders the panel with the given options.
+func (m *BookTape) ViewWithOptions(opts ViewOptions) string {
+	if opts.Width <= 0 {
+		return "Initializing..."
+	}
+	if opts.Height <= 0 {
+		return ""
+	}
+	m.syncStyles()
+
+	spacing := max(m.Spacing, 0)
+	bookWidth := int(float64(opts.Width-spacing) * min(max(m.Ratio, 0), 1))
+	tapeWidth := opts.Width - spacing - bookWidth
+	if bookWidth <= 0 || tapeWidth <= 0 {
+		// Too narrow for both, so only show the ladder.
+		return m.Book.ViewWithOptions(clob.ViewOptions{Width: opts.Width, Height: opts.Height})
+	}
+
+	book := m.Book.ViewWithOptions(clob.ViewOptions{Width: bookWidth, Height: opts.Height})
+	trades := m.Tape.ViewWithOptions(tape.ViewOptions{Width: tapeWidth, Height: opts.Height})
+	spacer := lipgloss.NewStyle().Width(spacing).Render("")
+	if m.TapeLeft {
+		return lipgloss.JoinHorizontal(lipgloss.Top, trades, spacer, book)
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, book, spacer, trades)
+}
+
+// syncStyles gives the tape the ladder's precision, and colours buys like the
+// bids and sells like the asks.
+func (m *BookTape) syncStyles() {
+	m.Tape.PricePrecision = m.Book.PricePrecision
+	m.Tape.VolumePrecision = m.Book.VolumePrecision
+	m.Tape.StyleBuy = m.Tape.StyleBuy.Foreground(m.Book.StyleOnBid.GetBackground())
+	m.Tape.StyleSell = m.Tape.StyleSell.Foreground(m.Book.StyleOnAsk.GetBackground())
+	m.Tape.StyleUnknown = m.Tape.StyleUnknown.Foreground(m.Book.StyleOffBar.GetForeground())
+}
@@ -0,0 +1,216 @@
+package pnf
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ViewOptions allows you to specify the dimensions of the P&F view.
+type ViewOptions struct {
+	Width  int
+	Height int
+}
+
+// Column is a single column of Xs (rising) or Os (falling).
+// Low and High are box numbers, the price of a box is its number times the box size.
+type Column struct {
+	Up   bool
+	Low  int
+	High int
+}
+
+// Model represents the state of the Point & Figure chart component.
+type Model struct {
+	width  int
+	height int
+
+	// Prices are the closing prices the chart is built from, oldest first.
+	Prices []float64
+
+	// BoxSize is the price range represented by each box. Nothing is drawn until it is set.
+	BoxSize float64
+
+	// Reversal is the number of boxes price must move against a column to start a new one.
+	Reversal int
+
+	// PricePrecision for the price labels.
+	PricePrecision int
+
+	// Styles
+	StyleX    lipgloss.Style
+	StyleO    lipgloss.Style
+	StyleAxis lipgloss.Style
+}
+
+// New creates a new P&F model with default styles and a 3 box reversal.
+func New() Model {
+	return Model{
+		Reversal:       3,
+		PricePrecision: 2,
+		StyleX: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("34")),
+		StyleO: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("124")),
+		StyleAxis: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "240", Dark: "245"}),
+	}
+}
+
+// Init initializes the P&F model.
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages for the P&F model.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	}
+	return m, nil
+}
+
+// View renders the chart, taking up the full width and height of the model.
+func (m *Model) View() string {
+	if m.width <= 0 {
+		return "Initializing..."
+	}
+	return m.ViewWithOptions(ViewOptions{Width: m.width, Height: m.height})
+}
+
+// ViewWithOptions renders the chart with the given options.
+func (m *Model) ViewWithOptions(opts ViewOptions) string {
+	if opts.Width <= 0 {
+		return "Initializing..."
+	}
+	columns := Columns(m.Prices, m.BoxSize, m.Reversal)
+	if len(columns) == 0 || opts.Height <= 0 {
+		return ""
+	}
+
+	// Size the price labels on the widest box in the chart.
+	low, high := columns[0].Low, columns[0].High
+	for _, c := range columns {
+		low = min(low, c.Low)
+		high = max(high, c.High)
+	}
+	priceFormat := fmt.Sprintf("%%.%df", m.PricePrecision)
+	labelWidth := max(
+		len(fmt.Sprintf(priceFormat, float64(low)*m.BoxSize)),
+		len(fmt.Sprintf(priceFormat, float64(high)*m.BoxSize)),
+	)
+
+	// Show the most recent columns that fit alongside the labels.
+	plotWidth := opts.Width - labelWidth - 1
+	if plotWidth <= 0 {
+		return ""
+	}
+	if len(columns) > plotWidth {
+		columns = columns[len(columns)-plotWidth:]
+	}
+
+	// Find the boxes covered by the visible columns, and if there are more than
+	// fit, keep the window centred on the latest box.
+	low, high = columns[0].Low, columns[0].High
+	for _, c := range columns {
+		low = min(low, c.Low)
+		high = max(high, c.High)
+	}
+	if high-low+1 > opts.Height {
+		last := columns[len(columns)-1]
+		current := last.Low
+		if last.Up {
+			current = last.High
+		}
+		top := min(high, current+opts.Height/2)
+		top = max(top, low+opts.Height-1)
+		high, low = top, top-opts.Height+1
+	}
+
+	rows := make([]string, 0, high-low+1)
+	for box := high; box >= low; box-- {
+		var sb strings.Builder
+		for _, c := range columns {
+			switch {
+			case box < c.Low || box > c.High:
+				sb.WriteString(" ")
+			case c.Up:
+				sb.WriteString(m.StyleX.Render("X"))
+			default:
+				sb.WriteString(m.StyleO.Render("O"))
+			}
+		}
+		sb.WriteString(strings.Repeat(" ", plotWidth-len(columns)))
+		label := fmt.Sprintf(" %*s", labelWidth, fmt.Sprintf(priceFormat, float64(box)*m.BoxSize))
+		sb.WriteString(m.StyleAxis.Render(label))
+		rows = append(rows, sb.String())
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+// maxBox is the largest box number kept, the largest integer a float64 holds
+// exactly. Prices further from zero in boxes are skipped, so the distance
+// between any two boxes fits in an int.
+const maxBox = 1 << 53
+
+// Columns builds the P&F columns for the given closing prices. A column is
+// extended while price keeps moving in its direction, and a new column is
+// started once price reverses by at least the reversal number of boxes.
+// Prices that aren't finite, or whose box number is beyond maxBox, e.g. from
+// a box size far too small for the price, are skipped.
+func Columns(prices []float64, boxSize float64, reversal int) []Column {
+	if boxSize <= 0 {
+		return nil
+	}
+	reversal = min(max(reversal, 1), maxBox)
+
+	var columns []Column
+	start := math.NaN()
+	for _, p := range prices {
+		if box := p / boxSize; !(box >= -maxBox && box <= maxBox) {
+			continue
+		}
+		// Rising prices fill a box once they reach its top, falling prices once they reach its bottom.
+		upBox := int(math.Floor(p / boxSize))
+		downBox := int(math.Ceil(p / boxSize))
+
+		if len(columns) == 0 {
+			// Wait for price to move a full box before deciding the first column's direction.
+			if math.IsNaN(start) {
+				start = p
+				continue
+			}
+			startBox := int(math.Floor(start / boxSize))
+			switch {
+			case upBox > startBox:
+				columns = append(columns, Column{Up: true, Low: startBox, High: upBox})
+			case downBox < int(math.Ceil(start/boxSize)):
+				columns = append(columns, Column{Up: false, Low: downBox, High: int(math.Ceil(start / boxSize))})
+			}
+			continue
+		}
+
+		c := &columns[len(columns)-1]
+		if c.Up {
+			switch {
+			case upBox > c.High:
+				c.High = upBox
+			case downBox <= c.High-reversal:
+				columns = append(columns, Column{Up: false, Low: downBox, High: c.High - 1})
+			}
+		} else {
+			switch {
+			case downBox < c.Low:
+				c.Low = downBox
+			case upBox >= c.Low+reversal:
+				columns = append(columns, Column{Up: true, Low: c.Low + 1, High: upBox})
+			}
+		}
+	}
+	return columns
+}
@@ -0,0 +1,34 @@
+package pnf
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// TestViewHugeBoxNumbers checks that prices whose box number doesn't fit in an
+// int are skipped rather than making the chart too large to render.
+func TestViewHugeBoxNumbers(t *testing.T) {
+	for _, c := range []struct {
+		prices  []float64
+		boxSize float64
+	}{
+		{[]float64{1, 1e30}, 1},
+		{[]float64{1e30, 1, 5}, 1},
+		{[]float64{100, 101, 99}, 1e-300},
+		{[]float64{-1e300, 1, 10}, 1},
+		{[]float64{math.NaN(), 1, math.Inf(1), 10}, 1},
+	} {
+		m := New()
+		m.Prices, m.BoxSize = c.prices, c.boxSize
+		out := m.ViewWithOptions(ViewOptions{Width: 40, Height: 10})
+		if lines := strings.Split(out, "\n"); len(lines) > 10 {
+			t.Errorf("%v at a box size of %v: got %d lines, want at most 10", c.prices, c.boxSize, len(lines))
+		}
+		for _, col := range Columns(c.prices, c.boxSize, 3) {
+			if col.Low < -maxBox || col.High > maxBox {
+				t.Errorf("%v at a box size of %v: column %+v is beyond maxBox", c.prices, c.boxSize, col)
+			}
+		}
+	}
+}
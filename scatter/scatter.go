@@ -0,0 +1,194 @@
+package scatter
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/allank/chartea/axis"
+	"github.com/allank/chartea/canvas/braille"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ViewOptions allows you to specify the dimensions of the scatter plot view.
+type ViewOptions struct {
+	Width  int
+	Height int
+}
+
+// Point is a single (x, y) observation.
+type Point struct {
+	X float64
+	Y float64
+}
+
+// Model represents the state of the scatter plot component.
+type Model struct {
+	width  int
+	height int
+
+	// Points are the observations plotted.
+	Points []Point
+
+	// ShowRegression draws the least squares regression line through the points.
+	ShowRegression bool
+
+	// Precision for the axis labels.
+	XPrecision int
+	YPrecision int
+
+	// Styles
+	StylePoint      lipgloss.Style
+	StyleRegression lipgloss.Style
+	StyleAxis       lipgloss.Style
+}
+
+// New creates a new scatter plot model with default styles.
+func New() Model {
+	return Model{
+		XPrecision: 2,
+		YPrecision: 2,
+		StylePoint: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "232", Dark: "188"}),
+		StyleRegression: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("214")),
+		StyleAxis: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "240", Dark: "245"}),
+	}
+}
+
+// Init initializes the scatter plot model.
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages for the scatter plot model.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	}
+	return m, nil
+}
+
+// View renders the plot, taking up the full width and height of the model.
+func (m *Model) View() string {
+	if m.width <= 0 {
+		return "Initializing..."
+	}
+	return m.ViewWithOptions(ViewOptions{Width: m.width, Height: m.height})
+}
+
+// ViewWithOptions renders the plot with the given options. The y-axis is
+// labelled on the left and the x-axis along the bottom.
+func (m *Model) ViewWithOptions(opts ViewOptions) string {
+	if opts.Width <= 0 {
+		return "Initializing..."
+	}
+	// Leave a row for the x-axis labels.
+	plotHeight := opts.Height - 1
+	if plotHeight <= 0 {
+		return ""
+	}
+
+	xs := make([]float64, len(m.Points))
+	ys := make([]float64, len(m.Points))
+	for i, p := range m.Points {
+		xs[i], ys[i] = p.X, p.Y
+	}
+	xScale := axis.Fit(axis.Linear, xs)
+	yScale := axis.Fit(axis.Linear, ys)
+
+	xFormat := fmt.Sprintf("%%.%df", m.XPrecision)
+	yFormat := fmt.Sprintf("%%.%df", m.YPrecision)
+	yTop, yBottom := fmt.Sprintf(yFormat, yScale.Max), fmt.Sprintf(yFormat, yScale.Min)
+	gutter := max(len(yTop), len(yBottom)) + 1
+	plotWidth := opts.Width - gutter
+	if plotWidth <= 0 {
+		return ""
+	}
+
+	c := braille.New(plotWidth, plotHeight)
+	dotsW, dotsH := plotWidth*2, plotHeight*4
+	toDot := func(x, y float64) (int, int) {
+		return int(math.Round(xScale.Normalize(x) * float64(dotsW-1))),
+			(dotsH - 1) - int(math.Round(yScale.Normalize(y)*float64(dotsH-1)))
+	}
+
+	// Draw the regression line first so the points are drawn over it.
+	if slope, intercept, ok := Regression(m.Points); m.ShowRegression && ok {
+		c.SetStyle(m.StyleRegression)
+		// Clip the line to the plot by stepping across it a dot at a time.
+		prevX, prevY, havePrev := 0, 0, false
+		for dx := 0; dx < dotsW; dx++ {
+			x := xScale.Min + (xScale.Max-xScale.Min)*float64(dx)/float64(max(dotsW-1, 1))
+			y := slope*x + intercept
+			_, dy := toDot(x, y)
+			if dy < 0 || dy >= dotsH {
+				havePrev = false
+				continue
+			}
+			if havePrev {
+				c.Line(prevX, prevY, dx, dy)
+			} else {
+				c.SetPixel(dx, dy)
+			}
+			prevX, prevY, havePrev = dx, dy, true
+		}
+	}
+	c.SetStyle(m.StylePoint)
+	for _, p := range m.Points {
+		if math.IsNaN(p.X) || math.IsNaN(p.Y) || math.IsInf(p.X, 0) || math.IsInf(p.Y, 0) {
+			continue
+		}
+		c.SetPixel(toDot(p.X, p.Y))
+	}
+
+	plotRows := c.Rows()
+	rows := make([]string, 0, opts.Height)
+	for y, row := range plotRows {
+		label := ""
+		switch y {
+		case 0:
+			label = yTop
+		case plotHeight - 1:
+			label = yBottom
+		}
+		rows = append(rows, m.StyleAxis.Render(fmt.Sprintf("%*s ", gutter-1, label))+row)
+	}
+
+	// Label the ends of the x-axis.
+	xLeft, xRight := fmt.Sprintf(xFormat, xScale.Min), fmt.Sprintf(xFormat, xScale.Max)
+	xLabels := strings.Repeat(" ", gutter) + xLeft
+	if padding := plotWidth - len(xLeft) - len(xRight); padding > 0 {
+		xLabels += strings.Repeat(" ", padding) + xRight
+	}
+	rows = append(rows, m.StyleAxis.Render(xLabels))
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+// Regression returns the slope and intercept of the least squares line through
+// the points. ok is false if there are too few points, or they all share an x value.
+func Regression(points []Point) (slope, intercept float64, ok bool) {
+	var n, sumX, sumY, sumXY, sumXX float64
+	for _, p := range points {
+		if math.IsNaN(p.X) || math.IsNaN(p.Y) || math.IsInf(p.X, 0) || math.IsInf(p.Y, 0) {
+			continue
+		}
+		n++
+		sumX += p.X
+		sumY += p.Y
+		sumXY += p.X * p.Y
+		sumXX += p.X * p.X
+	}
+	denominator := n*sumXX - sumX*sumX
+	if n < 2 || denominator == 0 {
+		return 0, 0, false
+	}
+	slope = (n*sumXY - sumX*sumY) / denominator
+	intercept = (sumY - slope*sumX) / n
+	return slope, intercept, true
+}
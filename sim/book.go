@@ -0,0 +1,252 @@
+// Package sim generates synthetic market data from a seed, so that tests,
+// golden files and demos see the same data on every run.
+package sim
+
+import (
+	"math/rand/v2"
+	"strconv"
+	"time"
+
+	"github.com/allank/chartea/clob"
+)
+
+// Epoch is the time of the first book generated, and each update is Step
+// after the one before.
+var (
+	Epoch = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	Step  = 100 * time.Millisecond
+)
+
+// Precision of the books generated. Prices start around 10000.00.
+const (
+	PriceDecimals  = 2
+	VolumeDecimals = 4
+
+	startMid = 1_000_000
+	// maxVolume is the largest volume of a level, in the smallest unit.
+	maxVolume = 50_000
+)
+
+// level is a price level, with the price in ticks and the volume in the
+// smallest unit of volume.
+type level struct {
+	price, volume int64
+	count         int
+	changed       time.Time
+}
+
+// Book is a reproducible order book, changing through a sequence of updates
+// that is the same for the same seed. The book always has the same number of
+// levels on each side and never crosses.
+type Book struct {
+	rng  *rand.Rand
+	time time.Time
+	// bids and asks are best first.
+	bids, asks []level
+}
+
+// DeterministicBook creates a book with levels on each side, generated from a
+// seed. Books with the same seed and levels, and the updates that follow, are
+// identical, e.g.
+//
+//	b := sim.DeterministicBook(1, 20)
+//	m.OrderBook = b.Snapshot()
+//	for _, u := range b.Updates(100) {
+//		...
+//	}
+func DeterministicBook(seed uint64, levels int) *Book {
+	b := &Book{
+		rng:  rand.New(rand.NewPCG(seed, seed)),
+		time: Epoch,
+	}
+	levels = max(levels, 1)
+	bid, ask := int64(startMid-1), int64(startMid+1)
+	for range levels {
+		b.bids = append(b.bids, b.newLevel(bid))
+		b.asks = append(b.asks, b.newLevel(ask))
+		bid -= 1 + b.rng.Int64N(3)
+		ask += 1 + b.rng.Int64N(3)
+	}
+	return b
+}
+
+// Time returns the time of the latest update, or Epoch before any.
+func (b *Book) Time() time.Time {
+	return b.time
+}
+
+// Snapshot returns the book as it stands, best levels first.
+func (b *Book) Snapshot() clob.OrderBook {
+	return clob.OrderBook{
+		Bids: b.orders(b.bids),
+		Asks: b.orders(b.asks),
+	}
+}
+
+// Update is a change to the book, like the deltas of a feed: each level that
+// changed is given with its new volume, and a level with no volume has been
+// removed.
+type Update struct {
+	Time time.Time
+	Bids []clob.Order
+	Asks []clob.Order
+}
+
+// Next changes the book and returns the change. Most updates change the
+// volume of a level, while others take out the best level, move a level or
+// improve on the best price.
+func (b *Book) Next() Update {
+	b.time = b.time.Add(Step)
+	u := Update{Time: b.time}
+
+	side, changes := &b.bids, &u.Bids
+	if b.rng.IntN(2) == 1 {
+		side, changes = &b.asks, &u.Asks
+	}
+	bids := side == &b.bids
+	levels := *side
+	spread := b.asks[0].price - b.bids[0].price
+
+	switch r := b.rng.IntN(20); {
+	case r < 12:
+		// Orders added to or cancelled from a level.
+		i := b.rng.IntN(len(levels))
+		levels[i].volume = 1 + b.rng.Int64N(maxVolume)
+		levels[i].count = 1 + b.rng.IntN(10)
+		levels[i].changed = b.time
+		*changes = append(*changes, b.order(levels[i]))
+	case r < 14:
+		// A level cancelled and another placed within the side.
+		i := b.rng.IntN(len(levels))
+		removed := levels[i]
+		levels = append(levels[:i:i], levels[i+1:]...)
+		l := b.newLevel(b.freePrice(levels, bids))
+		levels = insert(levels, l, bids)
+		removed.volume = 0
+		*changes = append(*changes, b.order(removed), b.order(l))
+	case r < 17 || spread <= 2:
+		// The best level taken, with a level placed beyond the worst to keep
+		// the depth.
+		removed := levels[0]
+		worst := levels[len(levels)-1].price
+		gap := 1 + b.rng.Int64N(3)
+		if bids {
+			gap = -gap
+		}
+		l := b.newLevel(worst + gap)
+		levels = append(levels[1:], l)
+		removed.volume = 0
+		*changes = append(*changes, b.order(removed), b.order(l))
+	default:
+		// A better price inside the spread, with the worst level cancelled.
+		removed := levels[len(levels)-1]
+		price := b.bids[0].price + 1 + b.rng.Int64N(spread-1)
+		l := b.newLevel(price)
+		levels = append([]level{l}, levels[:len(levels)-1]...)
+		removed.volume = 0
+		*changes = append(*changes, b.order(l), b.order(removed))
+	}
+	*side = levels
+	return u
+}
+
+// Updates returns the next n updates.
+func (b *Book) Updates(n int) []Update {
+	updates := make([]Update, n)
+	for i := range updates {
+		updates[i] = b.Next()
+	}
+	return updates
+}
+
+// newLevel creates a level at a price with a random volume and count.
+func (b *Book) newLevel(price int64) level {
+	return level{
+		price:   price,
+		volume:  1 + b.rng.Int64N(maxVolume),
+		count:   1 + b.rng.IntN(10),
+		changed: b.time,
+	}
+}
+
+// freePrice returns a random price between the best and worst levels of a
+// side, or beyond the worst when there is no gap between them.
+func (b *Book) freePrice(levels []level, bids bool) int64 {
+	if len(levels) == 0 {
+		if bids {
+			return b.asks[0].price - 1
+		}
+		return b.bids[0].price + 1
+	}
+	lo, hi := levels[0].price, levels[len(levels)-1].price
+	if bids {
+		lo, hi = hi, lo
+	}
+	taken := map[int64]bool{}
+	for _, l := range levels {
+		taken[l.price] = true
+	}
+	var free []int64
+	for p := lo + 1; p < hi; p++ {
+		if !taken[p] {
+			free = append(free, p)
+		}
+	}
+	if len(free) == 0 {
+		if bids {
+			return lo - 1
+		}
+		return hi + 1
+	}
+	return free[b.rng.IntN(len(free))]
+}
+
+// insert adds a level to a side, keeping it best first.
+func insert(levels []level, l level, bids bool) []level {
+	i := 0
+	for i < len(levels) && (bids && levels[i].price > l.price || !bids && levels[i].price < l.price) {
+		i++
+	}
+	levels = append(levels, level{})
+	copy(levels[i+1:], levels[i:])
+	levels[i] = l
+	return levels
+}
+
+// orders converts levels to orders.
+func (b *Book) orders(levels []level) []clob.Order {
+	orders := make([]clob.Order, len(levels))
+	for i, l := range levels {
+		orders[i] = b.order(l)
+	}
+	return orders
+}
+
+// order converts a level to an order, with the exact price and volume as
+// text. A removed level was changed by the latest update.
+func (b *Book) order(l level) clob.Order {
+	price, volume := fixed(l.price, PriceDecimals), fixed(l.volume, VolumeDecimals)
+	pf, _ := strconv.ParseFloat(price, 64)
+	vf, _ := strconv.ParseFloat(volume, 64)
+	count, changed := l.count, l.changed
+	if l.volume == 0 {
+		count, changed = 0, b.time
+	}
+	return clob.Order{
+		Price:      pf,
+		Volume:     vf,
+		PriceText:  price,
+		VolumeText: volume,
+		Count:      count,
+		Time:       changed,
+	}
+}
+
+// fixed formats an integer number of the smallest unit with decimals.
+func fixed(v int64, decimals int) string {
+	s := strconv.FormatInt(v, 10)
+	for len(s) <= decimals {
+		s = "0" + s
+	}
+	return s[:len(s)-decimals] + "." + s[len(s)-decimals:]
+}
@@ -0,0 +1,48 @@
+package sparkline
+
+import (
+	"math"
+	"strings"
+
+	"github.com/allank/chartea/axis"
+)
+
+// bars are the glyphs used for each eighth of a cell, lowest first.
+var bars = []rune("▁▂▃▄▅▆▇█")
+
+// Render returns a sparkline of the most recent values that fit in width cells,
+// scaled between the smallest and largest of them. NaN values are left blank,
+// and if there are fewer values than cells the sparkline is padded on the left.
+func Render(values []float64, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if len(values) > width {
+		values = values[len(values)-width:]
+	}
+
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		low = math.Min(low, v)
+		high = math.Max(high, v)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(strings.Repeat(" ", width-len(values)))
+	for _, v := range values {
+		switch {
+		case math.IsNaN(v) || math.IsInf(v, 0):
+			sb.WriteByte(' ')
+		case high == low:
+			// A flat line sits in the middle.
+			sb.WriteRune(bars[len(bars)/2-1])
+		default:
+			i := int(math.Round(axis.Scale{Min: low, Max: high}.Normalize(v) * float64(len(bars)-1)))
+			sb.WriteRune(bars[min(max(i, 0), len(bars)-1)])
+		}
+	}
+	return sb.String()
+}
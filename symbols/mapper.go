@@ -0,0 +1,82 @@
+package symbols
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Mapper translates between an exchange's names for markets and canonical
+// symbols.
+type Mapper interface {
+	// Name returns the exchange's name for a market.
+	Name(s Symbol) string
+	// Parse returns the market an exchange's name refers to.
+	Parse(name string) (Symbol, error)
+}
+
+// Mappers for the supported exchanges.
+var (
+	// Kraken names markets like XBT/USD, as in the wsname of its asset pairs.
+	// It also parses the REST names of pairs, e.g. XXBTZUSD or SOLUSD.
+	Kraken Mapper = kraken{}
+	// Binance names markets like BTCUSDT.
+	Binance Mapper = binance{}
+	// Coinbase names markets like BTC-USD.
+	Coinbase Mapper = coinbase{}
+)
+
+// krakenNames maps the common names of assets to the names Kraken uses.
+var krakenNames = map[string]string{
+	"BTC":  "XBT",
+	"DOGE": "XDG",
+}
+
+type kraken struct{}
+
+func (kraken) Name(s Symbol) string {
+	return krakenAsset(s.Base) + "/" + krakenAsset(s.Quote)
+}
+
+func (kraken) Parse(name string) (Symbol, error) {
+	// Legacy REST names prefix each asset with X for crypto or Z for fiat.
+	upper := strings.ToUpper(name)
+	if len(upper) == 8 && strings.ContainsRune("XZ", rune(upper[0])) && strings.ContainsRune("XZ", rune(upper[4])) {
+		return New(upper[1:4], upper[5:]), nil
+	}
+	return Parse(name)
+}
+
+// krakenAsset returns Kraken's name for an asset.
+func krakenAsset(asset string) string {
+	if name, ok := krakenNames[asset]; ok {
+		return name
+	}
+	return asset
+}
+
+type binance struct{}
+
+func (binance) Name(s Symbol) string {
+	return s.Base + s.Quote
+}
+
+func (binance) Parse(name string) (Symbol, error) {
+	if base, quote, ok := splitQuote(name); ok {
+		return New(base, quote), nil
+	}
+	return Symbol{}, fmt.Errorf("%w: %q", ErrInvalid, name)
+}
+
+type coinbase struct{}
+
+func (coinbase) Name(s Symbol) string {
+	return s.Base + "-" + s.Quote
+}
+
+func (coinbase) Parse(name string) (Symbol, error) {
+	base, quote, ok := strings.Cut(name, "-")
+	if !ok || base == "" || quote == "" {
+		return Symbol{}, fmt.Errorf("%w: %q", ErrInvalid, name)
+	}
+	return New(base, quote), nil
+}
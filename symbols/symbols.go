@@ -0,0 +1,90 @@
+package symbols
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrInvalid is returned when a market's name can't be parsed.
+var ErrInvalid = errors.New("invalid symbol")
+
+// aliases maps the names some exchanges give assets to their common names.
+var aliases = map[string]string{
+	"XBT": "BTC",
+	"XDG": "DOGE",
+}
+
+// quotes are the assets markets are commonly quoted in, longest first, to
+// split names that run the base and quote together, e.g. BTCUSDT.
+var quotes = sortedByLength([]string{
+	"USDT", "USDC", "FDUSD", "TUSD", "BUSD", "DAI", "USD", "EUR", "GBP", "JPY",
+	"CAD", "AUD", "CHF", "TRY", "BRL", "BTC", "XBT", "ETH", "BNB",
+})
+
+// Symbol is a market in canonical form, named by the common names of its base
+// and quote assets, e.g. BTC/USD.
+type Symbol struct {
+	Base  string
+	Quote string
+}
+
+// New creates a symbol from the names of its assets.
+func New(base, quote string) Symbol {
+	return Symbol{Base: Asset(base), Quote: Asset(quote)}
+}
+
+// String returns the canonical name of the market, e.g. BTC/USD.
+func (s Symbol) String() string {
+	return s.Base + "/" + s.Quote
+}
+
+// Asset returns the common name of an asset, e.g. BTC for XBT.
+func Asset(name string) string {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if common, ok := aliases[name]; ok {
+		return common
+	}
+	return name
+}
+
+// Parse parses the name of a market with its assets separated by a slash,
+// dash, underscore or colon, e.g. BTC/USD, XBT/USD or btc-usd, or run
+// together with a common quote asset, e.g. BTCUSDT.
+func Parse(name string) (Symbol, error) {
+	if base, quote, ok := cut(name); ok {
+		return New(base, quote), nil
+	}
+	if base, quote, ok := splitQuote(name); ok {
+		return New(base, quote), nil
+	}
+	return Symbol{}, fmt.Errorf("%w: %q", ErrInvalid, name)
+}
+
+// cut splits a name at its separator.
+func cut(name string) (base, quote string, ok bool) {
+	i := strings.IndexAny(name, "/-_:")
+	if i <= 0 || i == len(name)-1 {
+		return "", "", false
+	}
+	return name[:i], name[i+1:], true
+}
+
+// splitQuote splits a name with the base and quote run together, by the
+// longest common quote asset it ends with.
+func splitQuote(name string) (base, quote string, ok bool) {
+	upper := strings.ToUpper(name)
+	for _, q := range quotes {
+		if len(upper) > len(q) && strings.HasSuffix(upper, q) {
+			return upper[:len(upper)-len(q)], q, true
+		}
+	}
+	return "", "", false
+}
+
+// sortedByLength sorts names from the longest.
+func sortedByLength(names []string) []string {
+	sort.SliceStable(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+	return names
+}
@@ -0,0 +1,155 @@
+package tape
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/allank/chartea/trades"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// ViewOptions allows you to specify the dimensions of the tape view.
+type ViewOptions struct {
+	Width  int
+	Height int
+}
+
+// Model represents the state of the trade tape, or time and sales, component.
+type Model struct {
+	width  int
+	height int
+
+	// Trades are the most recent trades, oldest first.
+	Trades []trades.Trade
+
+	// Capacity is the number of trades kept, older trades are dropped.
+	Capacity int
+
+	// Precision for price and volume.
+	PricePrecision  int
+	VolumePrecision int
+
+	// TimeFormat is the layout the time of each trade is shown with.
+	TimeFormat string
+
+	// Styles
+	StyleBuy     lipgloss.Style
+	StyleSell    lipgloss.Style
+	StyleUnknown lipgloss.Style
+	StyleTime    lipgloss.Style
+}
+
+// New creates a new tape model with default styles.
+func New() Model {
+	return Model{
+		Capacity:        500,
+		PricePrecision:  2,
+		VolumePrecision: 2,
+		TimeFormat:      "15:04:05",
+		StyleBuy: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("34")),
+		StyleSell: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("124")),
+		StyleUnknown: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "232", Dark: "188"}),
+		StyleTime: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "240", Dark: "245"}),
+	}
+}
+
+// Push adds trades to the tape, oldest first. Trades whose price or volume
+// isn't a finite number can't be shown, and are dropped.
+func (m *Model) Push(ts ...trades.Trade) {
+	for _, t := range ts {
+		if !math.IsNaN(t.Price) && !math.IsInf(t.Price, 0) && !math.IsNaN(t.Volume) && !math.IsInf(t.Volume, 0) {
+			m.Trades = append(m.Trades, t)
+		}
+	}
+	if m.Capacity > 0 && len(m.Trades) > m.Capacity {
+		m.Trades = m.Trades[len(m.Trades)-m.Capacity:]
+	}
+}
+
+// Init initializes the tape model.
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages for the tape model.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	}
+	return m, nil
+}
+
+// View renders the tape, taking up the full width and height of the model.
+func (m *Model) View() string {
+	if m.width <= 0 {
+		return "Initializing..."
+	}
+	return m.ViewWithOptions(ViewOptions{Width: m.width, Height: m.height})
+}
+
+// ViewWithOptions renders the tape with the given options. The newest trade
+// is at the top, and each row shows the time, price and volume of a trade
+// coloured by the side of the aggressor. The time is dropped first when the
+// pane is too narrow.
+func (m *Model) ViewWithOptions(opts ViewOptions) string {
+	if opts.Width <= 0 {
+		return "Initializing..."
+	}
+	if opts.Height <= 0 {
+		return ""
+	}
+
+	shown := m.Trades[max(len(m.Trades)-opts.Height, 0):]
+	priceWidth, volumeWidth := 0, 0
+	for _, t := range shown {
+		priceWidth = max(priceWidth, len(m.price(t)))
+		volumeWidth = max(volumeWidth, len(m.volume(t)))
+	}
+	timeWidth := len(m.TimeFormat)
+	showTime := timeWidth > 0 && timeWidth+1+priceWidth+1+volumeWidth <= opts.Width
+
+	rows := make([]string, 0, opts.Height)
+	for i := len(shown) - 1; i >= 0; i-- {
+		t := shown[i]
+		text := fmt.Sprintf("%*s %*s", priceWidth, m.price(t), volumeWidth, m.volume(t))
+		row := m.style(t.Side).Render(ansi.Truncate(text, opts.Width, "…"))
+		if showTime {
+			at := t.Time.Format(m.TimeFormat)
+			pad := max(opts.Width-ansi.StringWidth(at)-len(text), 1)
+			row = m.StyleTime.Render(at) + fmt.Sprintf("%*s", pad, "") + row
+		}
+		rows = append(rows, row)
+	}
+	return lipgloss.NewStyle().Width(opts.Width).Height(opts.Height).Align(lipgloss.Right).Render(lipgloss.JoinVertical(lipgloss.Right, rows...))
+}
+
+// style returns the style for trades with an aggressor on a side.
+func (m *Model) style(side trades.Side) lipgloss.Style {
+	switch side {
+	case trades.Buy:
+		return m.StyleBuy
+	case trades.Sell:
+		return m.StyleSell
+	}
+	return m.StyleUnknown
+}
+
+// price formats the price of a trade.
+func (m *Model) price(t trades.Trade) string {
+	return strconv.FormatFloat(t.Price, 'f', m.PricePrecision, 64)
+}
+
+// volume formats the volume of a trade.
+func (m *Model) volume(t trades.Trade) string {
+	return strconv.FormatFloat(t.Volume, 'f', m.VolumePrecision, 64)
+}
@@ -0,0 +1,169 @@
+package theme
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme holds the styles of each component, by the name of the component's
+// package, then by the name of the style without its Style prefix, e.g.
+//
+//	{
+//	  "clob": {
+//	    "OnBid": {"foreground": "188", "background": "28"},
+//	    "OnAsk": {"foreground": "188", "background": "197"},
+//	    "OffBar": {"foreground": {"light": "232", "dark": "188"}}
+//	  },
+//	  "tape": {
+//	    "Buy": {"foreground": "28", "bold": true}
+//	  }
+//	}
+type Theme map[string]map[string]Style
+
+// Style is the attributes of a style. Attributes that aren't set keep the
+// value of the style they are applied to.
+type Style struct {
+	Foreground Color `json:"foreground,omitzero"`
+	Background Color `json:"background,omitzero"`
+	Bold       *bool `json:"bold,omitempty"`
+	Faint      *bool `json:"faint,omitempty"`
+	Italic     *bool `json:"italic,omitempty"`
+	Underline  *bool `json:"underline,omitempty"`
+	Reverse    *bool `json:"reverse,omitempty"`
+}
+
+// Color is a color given either as a single value, an ANSI color number or a
+// hex color, or as separate values for light and dark backgrounds.
+type Color struct {
+	Light string
+	Dark  string
+}
+
+// UnmarshalJSON decodes a color from a string or a {"light", "dark"} object.
+func (c *Color) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*c = Color{Light: s, Dark: s}
+		return nil
+	}
+	var adaptive struct {
+		Light string `json:"light"`
+		Dark  string `json:"dark"`
+	}
+	if err := json.Unmarshal(data, &adaptive); err != nil {
+		return fmt.Errorf("color must be a string or an object with light and dark colors: %w", err)
+	}
+	*c = Color(adaptive)
+	return nil
+}
+
+// MarshalJSON encodes a color as a string when it is the same on light and
+// dark backgrounds.
+func (c Color) MarshalJSON() ([]byte, error) {
+	if c.Light == c.Dark {
+		return json.Marshal(c.Light)
+	}
+	return json.Marshal(map[string]string{"light": c.Light, "dark": c.Dark})
+}
+
+// IsZero reports whether the color is unset.
+func (c Color) IsZero() bool {
+	return c.Light == "" && c.Dark == ""
+}
+
+// terminalColor returns the color for lipgloss.
+func (c Color) terminalColor() lipgloss.TerminalColor {
+	if c.Light == c.Dark {
+		return lipgloss.Color(c.Light)
+	}
+	return lipgloss.AdaptiveColor{Light: c.Light, Dark: c.Dark}
+}
+
+// Load reads a theme from a JSON file.
+func Load(path string) (Theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read theme: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse decodes a theme from JSON.
+func Parse(data []byte) (Theme, error) {
+	var t Theme
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("failed to decode theme: %w", err)
+	}
+	return t, nil
+}
+
+// Apply overlays the theme's styles for a component on a model, given as a
+// pointer, e.g.
+//
+//	err := t.Apply("clob", &book)
+//
+// Each style in the theme is applied to the model's lipgloss.Style field of
+// the same name with a Style prefix. A style the model doesn't have returns
+// an error naming it, after the rest are applied. A component the theme
+// doesn't mention leaves the model as it is.
+func (t Theme) Apply(component string, model any) error {
+	v := reflect.ValueOf(model)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("theme: %T is not a pointer to a model", model)
+	}
+	v = v.Elem()
+
+	styles := t[component]
+	names := make([]string, 0, len(styles))
+	for name := range styles {
+		names = append(names, name)
+	}
+	// Apply in a stable order so errors are reported consistently.
+	sort.Strings(names)
+
+	var unknown []string
+	for _, name := range names {
+		f := v.FieldByName("Style" + name)
+		if !f.IsValid() || !f.CanSet() || f.Type() != reflect.TypeOf(lipgloss.Style{}) {
+			unknown = append(unknown, name)
+			continue
+		}
+		f.Set(reflect.ValueOf(styles[name].Apply(f.Interface().(lipgloss.Style))))
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("theme: %s has no styles %s", component, strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// Apply returns a copy of a lipgloss style with the attributes that are set.
+func (s Style) Apply(style lipgloss.Style) lipgloss.Style {
+	if !s.Foreground.IsZero() {
+		style = style.Foreground(s.Foreground.terminalColor())
+	}
+	if !s.Background.IsZero() {
+		style = style.Background(s.Background.terminalColor())
+	}
+	if s.Bold != nil {
+		style = style.Bold(*s.Bold)
+	}
+	if s.Faint != nil {
+		style = style.Faint(*s.Faint)
+	}
+	if s.Italic != nil {
+		style = style.Italic(*s.Italic)
+	}
+	if s.Underline != nil {
+		style = style.Underline(*s.Underline)
+	}
+	if s.Reverse != nil {
+		style = style.Reverse(*s.Reverse)
+	}
+	return style
+}
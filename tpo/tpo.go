@@ -0,0 +1,250 @@
+package tpo
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// letters are assigned to periods in order, wrapping once they run out.
+const letters = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// ViewOptions allows you to specify the dimensions of the profile view.
+type ViewOptions struct {
+	Width  int
+	Height int
+}
+
+// Period is the range traded in a single time period, e.g. a 30 minute bar.
+type Period struct {
+	Low  float64
+	High float64
+}
+
+// Level is a single price level of the profile, with the letter of every
+// period that traded at that price.
+type Level struct {
+	Price   float64
+	Letters string
+}
+
+// Profile is a market profile built from a set of periods.
+// Levels are sorted by descending price, POC, ValueAreaHigh and ValueAreaLow
+// are indexes into Levels.
+type Profile struct {
+	Levels        []Level
+	POC           int
+	ValueAreaHigh int
+	ValueAreaLow  int
+}
+
+// Model represents the state of the market profile component.
+type Model struct {
+	width  int
+	height int
+
+	// Periods are the intraday periods the profile is built from, oldest first.
+	Periods []Period
+
+	// TickSize is the price range of each row. Nothing is drawn until it is set.
+	TickSize float64
+
+	// ValueArea is the percentage of TPOs around the POC included in the value area.
+	ValueArea float64
+
+	// PricePrecision for the price labels.
+	PricePrecision int
+
+	// Styles
+	StyleLetter    lipgloss.Style
+	StyleValueArea lipgloss.Style
+	StylePOC       lipgloss.Style
+	StyleAxis      lipgloss.Style
+}
+
+// New creates a new market profile model with default styles and a 70% value area.
+func New() Model {
+	return Model{
+		ValueArea:      70,
+		PricePrecision: 2,
+		StyleLetter: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "245", Dark: "240"}),
+		StyleValueArea: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "232", Dark: "188"}),
+		StylePOC: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("228")).
+			Background(lipgloss.Color("130")),
+		StyleAxis: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "240", Dark: "245"}),
+	}
+}
+
+// Init initializes the market profile model.
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages for the market profile model.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	}
+	return m, nil
+}
+
+// View renders the profile, taking up the full width and height of the model.
+func (m *Model) View() string {
+	if m.width <= 0 {
+		return "Initializing..."
+	}
+	return m.ViewWithOptions(ViewOptions{Width: m.width, Height: m.height})
+}
+
+// ViewWithOptions renders the profile with the given options.
+func (m *Model) ViewWithOptions(opts ViewOptions) string {
+	if opts.Width <= 0 {
+		return "Initializing..."
+	}
+	p := Build(m.Periods, m.TickSize, m.ValueArea)
+	if len(p.Levels) == 0 || opts.Height <= 0 {
+		return ""
+	}
+
+	// If there are more levels than rows, keep the window centred on the POC.
+	first, last := 0, len(p.Levels)-1
+	if len(p.Levels) > opts.Height {
+		first = max(0, min(p.POC-opts.Height/2, len(p.Levels)-opts.Height))
+		last = first + opts.Height - 1
+	}
+
+	priceFormat := fmt.Sprintf("%%.%df", m.PricePrecision)
+	labelWidth := 0
+	for _, l := range p.Levels[first : last+1] {
+		labelWidth = max(labelWidth, len(fmt.Sprintf(priceFormat, l.Price)))
+	}
+	letterWidth := opts.Width - labelWidth - 1
+	if letterWidth <= 0 {
+		return ""
+	}
+
+	rows := make([]string, 0, last-first+1)
+	for i := first; i <= last; i++ {
+		l := p.Levels[i]
+		label := m.StyleAxis.Render(fmt.Sprintf("%*s ", labelWidth, fmt.Sprintf(priceFormat, l.Price)))
+		tpos := l.Letters
+		if len(tpos) > letterWidth {
+			tpos = tpos[:letterWidth]
+		}
+
+		style := m.StyleLetter
+		switch {
+		case i == p.POC:
+			style = m.StylePOC
+		case i >= p.ValueAreaHigh && i <= p.ValueAreaLow:
+			style = m.StyleValueArea
+		}
+		rows = append(rows, label+style.Render(tpos)+strings.Repeat(" ", letterWidth-len(tpos)))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+// maxLevels is the most levels a profile has. When the periods span more
+// ticks, e.g. with a bad print far from the rest, the profile is cut to the
+// maxLevels ticks around the median period.
+const maxLevels = 10_000
+
+// maxTick is the largest tick index kept, the largest integer a float64 holds
+// exactly. Periods further from zero in ticks are dropped.
+const maxTick = 1 << 53
+
+// Build creates the market profile for the given periods. Each period marks
+// every tick it traded through with its letter. The POC is the level with the
+// most TPOs, and the value area grows out from the POC, taking the busier
+// neighbouring level each time, until it holds valueArea percent of all TPOs.
+// The profile holds at most maxLevels levels, around the median period.
+func Build(periods []Period, tickSize float64, valueArea float64) Profile {
+	if tickSize <= 0 || math.IsInf(tickSize, 0) {
+		return Profile{}
+	}
+
+	// Find the ticks traded in each period, leaving out those that aren't
+	// finite or whose ticks don't fit in an int.
+	type ticks struct {
+		low, high int
+		letter    byte
+	}
+	traded := make([]ticks, 0, len(periods))
+	for i, pd := range periods {
+		low := math.Floor(math.Min(pd.Low, pd.High) / tickSize)
+		high := math.Floor(math.Max(pd.Low, pd.High) / tickSize)
+		if !(low >= -maxTick && high <= maxTick) {
+			continue
+		}
+		traded = append(traded, ticks{int(low), int(high), letters[i%len(letters)]})
+	}
+	if len(traded) == 0 {
+		return Profile{}
+	}
+
+	// Find the range of ticks traded over all of the periods, cut to the
+	// maxLevels ticks around the median period.
+	lowTick, highTick := math.MaxInt, math.MinInt
+	mids := make([]int, len(traded))
+	for i, t := range traded {
+		lowTick, highTick = min(lowTick, t.low), max(highTick, t.high)
+		mids[i] = t.low + (t.high-t.low)/2
+	}
+	if highTick-lowTick >= maxLevels {
+		slices.Sort(mids)
+		median := mids[len(mids)/2]
+		lowTick = max(lowTick, median-maxLevels/2)
+		highTick = min(highTick, lowTick+maxLevels-1)
+		lowTick = highTick - maxLevels + 1
+	}
+
+	// Mark each tick with the letters of the periods that traded through it.
+	tpos := make([]strings.Builder, highTick-lowTick+1)
+	for _, pd := range traded {
+		for t := max(pd.low, lowTick); t <= min(pd.high, highTick); t++ {
+			tpos[highTick-t].WriteByte(pd.letter)
+		}
+	}
+
+	p := Profile{Levels: make([]Level, len(tpos))}
+	total := 0
+	for i := range tpos {
+		p.Levels[i] = Level{Price: float64(highTick-i) * tickSize, Letters: tpos[i].String()}
+		total += len(p.Levels[i].Letters)
+		if len(p.Levels[i].Letters) > len(p.Levels[p.POC].Letters) {
+			p.POC = i
+		}
+	}
+
+	// Grow the value area out from the POC.
+	p.ValueAreaHigh, p.ValueAreaLow = p.POC, p.POC
+	count := len(p.Levels[p.POC].Letters)
+	target := int(math.Ceil(float64(total) * valueArea / 100))
+	for count < target && (p.ValueAreaHigh > 0 || p.ValueAreaLow < len(p.Levels)-1) {
+		above, below := -1, -1
+		if p.ValueAreaHigh > 0 {
+			above = len(p.Levels[p.ValueAreaHigh-1].Letters)
+		}
+		if p.ValueAreaLow < len(p.Levels)-1 {
+			below = len(p.Levels[p.ValueAreaLow+1].Letters)
+		}
+		if above >= below {
+			p.ValueAreaHigh--
+			count += above
+		} else {
+			p.ValueAreaLow++
+			count += below
+		}
+	}
+	return p
+}
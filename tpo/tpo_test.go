@@ -0,0 +1,35 @@
+package tpo
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// TestBuildOutlier checks that a period far from the rest, or spanning an
+// absurd number of ticks, can't make the profile too large to build.
+func TestBuildOutlier(t *testing.T) {
+	normal := Period{Low: 100, High: 101}
+	for _, outlier := range []Period{
+		{Low: 1e9, High: 1e9 + 1},
+		{Low: 0, High: 1e9},
+		{Low: 1, High: 1e300},
+		{Low: math.Inf(-1), High: 100},
+		{Low: math.NaN(), High: 100},
+	} {
+		periods := []Period{normal, normal, outlier}
+		p := Build(periods, 0.01, 70)
+		if len(p.Levels) == 0 || len(p.Levels) > maxLevels {
+			t.Fatalf("%+v: got %d levels, want 1 to %d", outlier, len(p.Levels), maxLevels)
+		}
+		if poc := p.Levels[p.POC]; poc.Price < normal.Low || poc.Price > normal.High || !strings.Contains(poc.Letters, "AB") {
+			t.Errorf("%+v: POC at %v with %q, want the normal periods", outlier, poc.Price, poc.Letters)
+		}
+
+		m := New()
+		m.Periods, m.TickSize = periods, 0.01
+		if lines := strings.Split(m.ViewWithOptions(ViewOptions{Width: 40, Height: 10}), "\n"); len(lines) != 10 {
+			t.Errorf("%+v: got %d lines, want 10", outlier, len(lines))
+		}
+	}
+}
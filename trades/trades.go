@@ -0,0 +1,27 @@
+package trades
+
+import "time"
+
+// Side is the side of the aggressor in a trade.
+type Side int
+
+const (
+	// Unknown is used when the feed doesn't report the aggressor.
+	Unknown Side = iota
+	// Buy means the buyer took liquidity, lifting an ask.
+	Buy
+	// Sell means the seller took liquidity, hitting a bid.
+	Sell
+)
+
+// Trade represents a single executed trade.
+type Trade struct {
+	Time   time.Time
+	Price  float64
+	Volume float64
+	Side   Side
+	// ID is the exchange's number for the trade, or zero if it doesn't number
+	// them. Exchanges number trades in order, so a trade fetched twice, e.g.
+	// over REST and then over a websocket, can be told apart from a new one.
+	ID int64
+}
@@ -0,0 +1,260 @@
+package treemap
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/allank/chartea/gradient"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ViewOptions allows you to specify the dimensions of the treemap view.
+type ViewOptions struct {
+	Width  int
+	Height int
+}
+
+// Item is a single position in the portfolio.
+type Item struct {
+	// Label names the position.
+	Label string
+	// Value sizes the rectangle. Items with no value are not shown.
+	Value float64
+	// Change is the daily change as a fraction, e.g. 0.032 is +3.2%, and colors the rectangle.
+	Change float64
+}
+
+// Rect is a rectangle of terminal cells.
+type Rect struct {
+	X      int
+	Y      int
+	Width  int
+	Height int
+}
+
+// Model represents the state of the treemap component.
+type Model struct {
+	width  int
+	height int
+
+	// Items are the positions shown. They do not need to be sorted.
+	Items []Item
+
+	// MaxChange is the change at which the color scale is saturated.
+	MaxChange float64
+
+	// Precision for the change percentages.
+	Precision int
+
+	// Colors for falling, flat and rising positions, as hex colors.
+	ColorLow  lipgloss.Color
+	ColorMid  lipgloss.Color
+	ColorHigh lipgloss.Color
+}
+
+// New creates a new treemap model with default colors, saturating at a 5% move.
+func New() Model {
+	return Model{
+		MaxChange: 0.05,
+		Precision: 2,
+		ColorLow:  lipgloss.Color("#af0000"),
+		ColorMid:  lipgloss.Color("#303030"),
+		ColorHigh: lipgloss.Color("#00af00"),
+	}
+}
+
+// Init initializes the treemap model.
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages for the treemap model.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	}
+	return m, nil
+}
+
+// View renders the treemap, taking up the full width and height of the model.
+func (m *Model) View() string {
+	if m.width <= 0 {
+		return "Initializing..."
+	}
+	return m.ViewWithOptions(ViewOptions{Width: m.width, Height: m.height})
+}
+
+// ViewWithOptions renders the treemap with the given options. Each position is
+// a rectangle sized by its value and colored by its change, showing its label
+// and change when there is room.
+func (m *Model) ViewWithOptions(opts ViewOptions) string {
+	if opts.Width <= 0 {
+		return "Initializing..."
+	}
+	if opts.Height <= 0 {
+		return ""
+	}
+
+	// Lay out the largest positions first, which keeps the rectangles squarer.
+	items := make([]Item, 0, len(m.Items))
+	for _, it := range m.Items {
+		if it.Value > 0 && !math.IsInf(it.Value, 0) {
+			items = append(items, it)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Value > items[j].Value
+	})
+	values := make([]float64, len(items))
+	for i, it := range items {
+		values[i] = it.Value
+	}
+	rects := Layout(values, opts.Width, opts.Height)
+
+	grid := make([][]string, opts.Height)
+	for y := range grid {
+		grid[y] = make([]string, opts.Width)
+		for x := range grid[y] {
+			grid[y][x] = " "
+		}
+	}
+
+	changeFormat := fmt.Sprintf("%%+.%df%%%%", m.Precision)
+	for i, r := range rects {
+		if r.Width <= 0 || r.Height <= 0 {
+			continue
+		}
+		bg := m.color(items[i].Change)
+		style := lipgloss.NewStyle().Background(bg).Foreground(gradient.Contrast(bg))
+
+		// Place the label and change in the top left, leaving a gap to the next rectangle.
+		text := []string{items[i].Label, fmt.Sprintf(changeFormat, items[i].Change*100)}
+		for y := 0; y < r.Height; y++ {
+			line := ""
+			if y < len(text) && r.Width > 1 {
+				line = truncate(text[y], r.Width-1)
+			}
+			line += strings.Repeat(" ", r.Width-lipgloss.Width(line))
+			grid[r.Y+y][r.X] = style.Render(line)
+			for x := 1; x < r.Width; x++ {
+				grid[r.Y+y][r.X+x] = ""
+			}
+		}
+	}
+
+	rows := make([]string, 0, opts.Height)
+	for _, cells := range grid {
+		rows = append(rows, strings.Join(cells, ""))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+// color maps a change onto the color scale, through the middle color.
+func (m *Model) color(change float64) lipgloss.Color {
+	return gradient.Gradient{m.ColorLow, m.ColorMid, m.ColorHigh}.Map(change, -m.MaxChange, m.MaxChange)
+}
+
+// Layout positions a rectangle for each value within the given area using the
+// squarified treemap algorithm. Values should be sorted largest first. As
+// terminal cells are about twice as tall as they are wide, the layout is worked
+// out on square units so the rectangles look square on screen.
+func Layout(values []float64, width, height int) []Rect {
+	rects := make([]Rect, len(values))
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	if total <= 0 || width <= 0 || height <= 0 {
+		return rects
+	}
+
+	// Work in units where a cell is 1 wide and 2 high.
+	x, y, w, h := 0.0, 0.0, float64(width), float64(height)*2
+	scale := w * h / total
+
+	type area struct {
+		index int
+		size  float64
+	}
+	var row []area
+	// place lays out the current row along the shorter side and shrinks the remaining space.
+	place := func() {
+		sum := 0.0
+		for _, a := range row {
+			sum += a.size
+		}
+		if w >= h {
+			// A column on the left.
+			colW := sum / h
+			top := y
+			for _, a := range row {
+				cellH := a.size / colW
+				rects[a.index] = toCells(x, top, colW, cellH)
+				top += cellH
+			}
+			x += colW
+			w -= colW
+		} else {
+			// A row along the top.
+			rowH := sum / w
+			left := x
+			for _, a := range row {
+				cellW := a.size / rowH
+				rects[a.index] = toCells(left, y, cellW, rowH)
+				left += cellW
+			}
+			y += rowH
+			h -= rowH
+		}
+		row = row[:0]
+	}
+	// worst returns the largest aspect ratio in the row if laid along the given side.
+	worst := func(r []area, side float64) float64 {
+		sum, lo, hi := 0.0, math.Inf(1), 0.0
+		for _, a := range r {
+			sum += a.size
+			lo = math.Min(lo, a.size)
+			hi = math.Max(hi, a.size)
+		}
+		return math.Max(side*side*hi/(sum*sum), sum*sum/(side*side*lo))
+	}
+
+	for i, v := range values {
+		a := area{index: i, size: math.Max(v, 0) * scale}
+		if a.size == 0 {
+			continue
+		}
+		side := math.Min(w, h)
+		if len(row) > 0 && worst(append(row[:len(row):len(row)], a), side) > worst(row, side) {
+			place()
+		}
+		row = append(row, a)
+	}
+	if len(row) > 0 {
+		place()
+	}
+	return rects
+}
+
+// toCells converts a rectangle in square units to terminal cells, rounding the
+// edges so neighbouring rectangles meet without gaps or overlaps.
+func toCells(x, y, w, h float64) Rect {
+	x0, x1 := int(math.Round(x)), int(math.Round(x+w))
+	y0, y1 := int(math.Round(y/2)), int(math.Round((y+h)/2))
+	return Rect{X: x0, Y: y0, Width: x1 - x0, Height: y1 - y0}
+}
+
+// truncate shortens text to fit the given width.
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) > width {
+		return string(runes[:width])
+	}
+	return s
+}